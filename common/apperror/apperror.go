@@ -0,0 +1,118 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindBadRequest
+	KindTooManyRequests
+	KindTimeout
+)
+
+// error ของ domain ที่ส่งให้ client ได้ (Err เก็บสาเหตุภายใน ใช้ log เท่านั้น)
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTP status ตามชนิดของ error
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindBadRequest:
+		return http.StatusBadRequest
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case KindTimeout:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Validation(code string, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func Unauthorized(code string, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code string, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// request อ่านไม่ได้ เช่น JSON ผิดรูปแบบ
+func BadRequest(code string, message string) *Error {
+	return &Error{Kind: KindBadRequest, Code: code, Message: message}
+}
+
+// เรียกบ่อยเกินไป ต้องรอก่อนลองใหม่
+func TooManyRequests(code string, message string) *Error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message}
+}
+
+// งานไม่เสร็จทันเวลาหรือ request ถูกยกเลิก (เช่น server กำลังปิด) ลองใหม่ได้
+func Timeout(err error) *Error {
+	return &Error{Kind: KindTimeout, Code: "TIMEOUT", Message: "request timed out", Err: err}
+}
+
+// ห่อ error จาก driver / ระบบภายนอก ข้อความที่ client เห็นจะไม่มีรายละเอียดภายใน
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "INTERNAL_ERROR", Message: "internal server error", Err: err}
+}
+
+// แปลง error ใดๆ เป็น *Error (error ที่ไม่รู้จักถือเป็น internal)
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// เช็คว่า err เป็น domain error ชนิด kind หรือไม่
+func Is(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
@@ -0,0 +1,45 @@
+package apperror_test
+
+import (
+	"7solutions/backend/common/apperror"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Status(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  error
+		Output int
+	}{
+		{Name: "not found", Input: apperror.NotFound("USER_NOT_FOUND", "user not found"), Output: 404},
+		{Name: "conflict", Input: apperror.Conflict("EMAIL_TAKEN", "email already exists"), Output: 409},
+		{Name: "validation", Input: apperror.Validation("EMAIL_INVALID", "email invalid"), Output: 422},
+		{Name: "unauthorized", Input: apperror.Unauthorized("UNAUTHORIZED", "unauthorized"), Output: 401},
+		{Name: "forbidden", Input: apperror.Forbidden("FORBIDDEN", "forbidden"), Output: 403},
+		{Name: "bad request", Input: apperror.BadRequest("INVALID_BODY", "invalid request body"), Output: 400},
+		{Name: "too many requests", Input: apperror.TooManyRequests("TOO_MANY_ATTEMPTS", "too many attempts"), Output: 429},
+		{Name: "timeout", Input: apperror.Timeout(context.DeadlineExceeded), Output: 503},
+		{Name: "wrapped", Input: fmt.Errorf("get user: %w", apperror.NotFound("USER_NOT_FOUND", "user not found")), Output: 404},
+		{Name: "unknown error", Input: errors.New("connection refused"), Output: 500},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Output, apperror.From(c.Input).Status())
+		})
+	}
+}
+
+func Test_InternalHidesCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := apperror.From(cause)
+
+	assert.Equal(t, "INTERNAL_ERROR", err.Code)
+	assert.Equal(t, "internal server error", err.Message)
+	assert.ErrorIs(t, err, cause)
+	assert.True(t, apperror.Is(err, apperror.KindInternal))
+}
@@ -1,9 +1,59 @@
 package authorization
 
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
 type AppAuthorization interface {
 	// สำหรับ Cenerate JWT Tokan
-	GenerateToken(payload AppAuthorizationClaim) (token string, err error)
+	GenerateToken(ctx context.Context, payload AppAuthorizationClaim) (token string, err error)
 
 	// สำหรับ Validate JWT Tokan
-	ValidateToken(tokenString string, paserTo interface{}) (err error)
+	ValidateToken(ctx context.Context, tokenString string, paserTo interface{}) (err error)
+}
+
+type AppAuthorizationClaim struct {
+	UserId   string `json:"sub,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Audience string `json:"aud,omitempty"`
+	Issuer   string `json:"iss,omitempty"`
+	Channel  string `json:"channel,omitempty"`
+	Role     string `json:"role,omitempty"`
+
+	// NOTE token ที่ออกให้ OAuth client ใช้ได้เฉพาะ scope (คั่นด้วยช่องว่าง) ที่ user อนุญาต
+	Scope    string `json:"scope,omitempty"`
+	ClientId string `json:"client_id,omitempty"`
+
+	// NOTE ค่าด้านล่าง GenerateToken จะสร้างให้เอง ใช้อ่านตอน ValidateToken
+	TokenId   string  `json:"jti,omitempty"`
+	IssuedAt  float64 `json:"iat,omitempty"`
+	ExpiresAt int64   `json:"exp,omitempty"`
+}
+
+// เวลาที่ออก token จาก claim iat
+func (c AppAuthorizationClaim) IssuedTime() time.Time {
+	return time.UnixMilli(int64(math.Round(c.IssuedAt * 1000)))
+}
+
+// เวลาหมดอายุจาก claim exp
+func (c AppAuthorizationClaim) ExpiresTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// เลือก adapter ตามอัลกอริทึมของ key ring
+func NewAppAuthorization(keys *KeyRing) AppAuthorization {
+	switch keys.Method() {
+	case jwt.SigningMethodRS256:
+		return NewJWT_RS256(keys)
+	case jwt.SigningMethodES256:
+		return NewJWT_ES256(keys)
+	case SigningMethodEdDSA:
+		return NewJWT_EdDSA(keys)
+	default:
+		return NewJWT_HS256(keys)
+	}
 }
@@ -0,0 +1,12 @@
+package authorization
+
+import (
+	"7solutions/backend/config"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWT แบบ ES256 (ECDSA P-256)
+func NewJWT_ES256(keys *KeyRing) AppAuthorization {
+	return jwtAdapter{Method: jwt.SigningMethodES256, Keys: keys, Duration: config.Env.SignatureExp}
+}
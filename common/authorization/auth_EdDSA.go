@@ -0,0 +1,53 @@
+package authorization
+
+import (
+	"7solutions/backend/config"
+	"crypto/ed25519"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// jwt-go v3 ไม่มี EdDSA มาให้ จึงลงทะเบียน signing method เอง (Ed25519)
+type signingMethodEdDSA struct{}
+
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+// JWT แบบ EdDSA (Ed25519)
+func NewJWT_EdDSA(keys *KeyRing) AppAuthorization {
+	return jwtAdapter{Method: SigningMethodEdDSA, Keys: keys, Duration: config.Env.SignatureExp}
+}
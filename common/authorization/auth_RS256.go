@@ -0,0 +1,12 @@
+package authorization
+
+import (
+	"7solutions/backend/config"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWT แบบ RS256
+func NewJWT_RS256(keys *KeyRing) AppAuthorization {
+	return jwtAdapter{Method: jwt.SigningMethodRS256, Keys: keys, Duration: config.Env.SignatureExp}
+}
@@ -0,0 +1,145 @@
+package authorization
+
+import (
+	"7solutions/backend/common/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+type authCustomClaims struct {
+	Name     string `json:"name,omitempty"`
+	Channel  string `json:"channel,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	ClientId string `json:"client_id,omitempty"`
+	// iat แบบมีทศนิยม (ms) เพื่อเทียบกับเวลาที่ user ถูกเพิกถอน token ได้แม่นยำ
+	IssuedAt float64 `json:"iat,omitempty"`
+	jwt.StandardClaims
+}
+
+func newAuthCustomClaims(payload AppAuthorizationClaim, duration time.Duration) *authCustomClaims {
+	now := time.Now()
+	// FIX EDIT PAYLOAD HERE ----------------------------
+	return &authCustomClaims{
+		payload.Name,
+		payload.Channel,
+		payload.Role,
+		payload.Scope,
+		payload.ClientId,
+		float64(now.UnixMilli()) / 1000, // iat isused at (seconds since Unix epoch)
+		jwt.StandardClaims{
+			Audience:  payload.Audience,         // aud Audience (who or what the token intended for)
+			ExpiresAt: now.Add(duration).Unix(), // exp Expiration time (seconds since Unix epoch)
+			Id:        uuid.New().String(),      // jti JWT ID (unique identifier for this token)
+			Issuer:    payload.Issuer,           // iss issuer (who created and signed this token)
+			NotBefore: 0,                        // nbf No valid before (seconds since Unix epoch)
+			Subject:   payload.UserId,           // sub Subject (whom the token reference to)
+		},
+	}
+	// FIX EDIT PAYLOAD HERE ----------------------------
+}
+
+// NOTE Adapter -----------------------------
+// ใช้ร่วมกันทุกอัลกอริทึม เซ็นด้วยกุญแจ active และ verify ตาม kid ใน header
+type jwtAdapter struct {
+	Method   jwt.SigningMethod
+	Keys     *KeyRing
+	Duration time.Duration
+}
+
+func (c jwtAdapter) GenerateToken(ctx context.Context, payload AppAuthorizationClaim) (tokenString string, err error) {
+	_, span := tracing.Start(ctx, "jwt.Sign")
+	defer func() { tracing.End(span, err) }()
+
+	key := c.Keys.Active()
+	if key.PrivateKey == nil {
+		return "", errors.New("private key is not configured")
+	}
+
+	claim := newAuthCustomClaims(payload, c.Duration)
+
+	// NOTE Create a new JWT token & Set the claims for the token
+	token := jwt.NewWithClaims(c.Method, claim)
+	token.Header["kid"] = key.Kid
+
+	// NOTE Sign the token with the key
+	tokenString, err = token.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return tokenString, nil
+}
+
+func (c jwtAdapter) ValidateToken(ctx context.Context, tokenString string, data interface{}) (err error) {
+	_, span := tracing.Start(ctx, "jwt.Verify")
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE Parse the token string
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// NOTE Check the signing method of the token
+		if token.Method.Alg() != c.Method.Alg() {
+			return nil, errors.New("invalid signing method")
+		}
+
+		// NOTE Return the key for verifying the signature
+		kid, _ := token.Header["kid"].(string)
+		key, ok := c.Keys.Lookup(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return key.PublicKey, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return parseAuthClaims(token, data)
+}
+
+// ตรวจ claims ที่ทุก adapter ใช้ร่วมกัน แล้วแปลงลง data
+func parseAuthClaims(token *jwt.Token, data interface{}) (err error) {
+	// NOTE Check if the token is valid
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+
+	// NOTE Get the claims from the token
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("invalid claims")
+	}
+
+	// NOTE Check expired from the token
+	expirationTime, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("invalid expiration time")
+	}
+
+	if time.Now().Unix() > int64(expirationTime) {
+		return errors.New("token has expired")
+	}
+
+	// NOTE Check issuer from the token
+	_, ok = claims["iss"].(string)
+	if !ok {
+		return errors.New("invalid issuer in token")
+	}
+
+	// NOTE Convert the data to the specified type
+	dataBytes, err := json.Marshal(claims)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(dataBytes, &data)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package authorization
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type MockAuthorization struct {
 	mock.Mock
@@ -10,12 +14,12 @@ func NewAuthorizationMock() *MockAuthorization {
 	return &MockAuthorization{}
 }
 
-func (m *MockAuthorization) GenerateToken(payload AppAuthorizationClaim) (token string, err error) {
-	args := m.Called(payload)
+func (m *MockAuthorization) GenerateToken(ctx context.Context, payload AppAuthorizationClaim) (token string, err error) {
+	args := m.Called(ctx, payload)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAuthorization) ValidateToken(tokenString string, paserTo interface{}) (err error) {
-	args := m.Called(tokenString, paserTo)
+func (m *MockAuthorization) ValidateToken(ctx context.Context, tokenString string, paserTo interface{}) (err error) {
+	args := m.Called(ctx, tokenString, paserTo)
 	return args.Error(0)
 }
@@ -0,0 +1,197 @@
+package authorization_test
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/config"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyPair(t *testing.T, privateKey crypto.Signer) (privatePath string, publicPath string) {
+	dir := t.TempDir()
+
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	publicBytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	assert.NoError(t, err)
+
+	privatePath = filepath.Join(dir, "private.pem")
+	publicPath = filepath.Join(dir, "public.pem")
+	assert.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), 0600))
+	assert.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), 0600))
+	return privatePath, publicPath
+}
+
+func Test_AsymmetricToken(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	cases := []struct {
+		Name string
+		Alg  string
+		Key  crypto.Signer
+	}{
+		{Name: "RS256", Alg: "RS256", Key: rsaKey},
+		{Name: "ES256", Alg: "ES256", Key: ecKey},
+		{Name: "EdDSA", Alg: "EdDSA", Key: edKey},
+	}
+
+	payload := authorization.AppAuthorizationClaim{
+		UserId:   "227b6ede-95f1-4a01-96df-5c4ffb61063c",
+		Audience: "7solutions",
+		Issuer:   "7solutions",
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			privatePath, publicPath := writeKeyPair(t, c.Key)
+			config.Env.SignatureAlg = c.Alg
+			config.Env.SignatureExp = time.Hour
+
+			config.Env.SignaturePrivateKey = privatePath
+			config.Env.SignaturePublicKey = ""
+			signer := authorization.NewAppAuthorization(authorization.NewAppKeyRing())
+			token, err := signer.GenerateToken(context.Background(), payload)
+			assert.NoError(t, err)
+
+			// NOTE service ปลายทางมีแค่ public key
+			config.Env.SignaturePrivateKey = ""
+			config.Env.SignaturePublicKey = publicPath
+			verifier := authorization.NewAppAuthorization(authorization.NewAppKeyRing())
+
+			sub := authorization.AppAuthorizationClaim{}
+			assert.NoError(t, verifier.ValidateToken(context.Background(), token, &sub))
+			assert.Equal(t, payload.UserId, sub.UserId)
+
+			_, err = verifier.GenerateToken(context.Background(), payload)
+			assert.EqualError(t, err, "private key is not configured")
+		})
+	}
+}
+
+func Test_ValidateTokenRejectsOtherAlgorithm(t *testing.T) {
+	config.Env.SignatureExp = time.Hour
+	hmacRing, _ := authorization.NewKeyRing(jwt.SigningMethodHS256, authorization.StaticKeyLoader("k1",
+		authorization.SigningKey{Kid: "k1", PrivateKey: []byte("secret"), PublicKey: []byte("secret")},
+	))
+	token, err := authorization.NewJWT_HS256(hmacRing).GenerateToken(context.Background(), authorization.AppAuthorizationClaim{Issuer: "7solutions"})
+	assert.NoError(t, err)
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edRing, _ := authorization.NewKeyRing(authorization.SigningMethodEdDSA, authorization.StaticKeyLoader("k1",
+		authorization.SigningKey{Kid: "k1", PublicKey: edKey.Public()},
+	))
+
+	sub := authorization.AppAuthorizationClaim{}
+	err = authorization.NewJWT_EdDSA(edRing).ValidateToken(context.Background(), token, &sub)
+	assert.Error(t, err)
+}
+
+func Test_KeyRingRotation(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(kid string) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		privatePath, _ := writeKeyPair(t, key)
+		raw, _ := os.ReadFile(privatePath)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), raw, 0600))
+	}
+	kidOf := func(token string) string {
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+		assert.NoError(t, err)
+		return parsed.Header["kid"].(string)
+	}
+
+	config.Env.SignatureAlg = "ES256"
+	config.Env.SignatureExp = time.Hour
+	config.Env.SignatureKeyDir = dir
+	config.Env.SignatureActiveKid = ""
+	defer func() { config.Env.SignatureKeyDir = "" }()
+
+	writeKey("2025-01")
+	keyRing := authorization.NewAppKeyRing()
+	auth := authorization.NewAppAuthorization(keyRing)
+	payload := authorization.AppAuthorizationClaim{UserId: "user", Issuer: "7solutions"}
+
+	oldToken, err := auth.GenerateToken(context.Background(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-01", kidOf(oldToken))
+
+	// NOTE เพิ่มกุญแจใหม่ -> เซ็นด้วยกุญแจใหม่ แต่ token เก่ายังใช้ได้
+	writeKey("2025-02")
+	assert.NoError(t, keyRing.Reload())
+
+	newToken, err := auth.GenerateToken(context.Background(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-02", kidOf(newToken))
+	assert.NoError(t, auth.ValidateToken(context.Background(), oldToken, &authorization.AppAuthorizationClaim{}))
+	assert.NoError(t, auth.ValidateToken(context.Background(), newToken, &authorization.AppAuthorizationClaim{}))
+
+	jwks := keyRing.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2025-02", jwks.Keys[0].Kid)
+	assert.Equal(t, "EC", jwks.Keys[0].Kty)
+
+	// NOTE ลบกุญแจเก่าออก -> token เก่าใช้ไม่ได้อีก
+	assert.NoError(t, os.Remove(filepath.Join(dir, "2025-01.pem")))
+	assert.NoError(t, keyRing.Reload())
+
+	assert.EqualError(t, auth.ValidateToken(context.Background(), oldToken, &authorization.AppAuthorizationClaim{}), "unknown signing key")
+	assert.NoError(t, auth.ValidateToken(context.Background(), newToken, &authorization.AppAuthorizationClaim{}))
+	assert.Len(t, keyRing.JWKS().Keys, 1)
+}
+
+func Test_JSONWebKeyPublicKey(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name string
+		alg  string
+		key  crypto.Signer
+	}{
+		{name: "RSA", alg: "RS256", key: rsaKey},
+		{name: "EC", alg: "ES256", key: ecKey},
+		{name: "OKP", alg: "EdDSA", key: edKey},
+	}
+	defer func() { config.Env.SignatureKeyDir = "" }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			privatePath, _ := writeKeyPair(t, tt.key)
+			raw, _ := os.ReadFile(privatePath)
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "k1.pem"), raw, 0600))
+
+			config.Env.SignatureAlg = tt.alg
+			config.Env.SignatureKeyDir = dir
+			config.Env.SignatureActiveKid = ""
+			jwks := authorization.NewAppKeyRing().JWKS()
+			assert.Len(t, jwks.Keys, 1)
+
+			publicKey, err := jwks.Keys[0].PublicKey()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.key.Public(), publicKey)
+		})
+	}
+
+	_, err := authorization.JSONWebKey{Kty: "EC", Crv: "P-256", X: "AQ", Y: "AQ"}.PublicKey()
+	assert.EqualError(t, err, "jwk: invalid ec key")
+	_, err = authorization.JSONWebKey{Kty: "oct"}.PublicKey()
+	assert.EqualError(t, err, `jwk: unsupported key type "oct"`)
+}
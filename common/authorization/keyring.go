@@ -0,0 +1,155 @@
+package authorization
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/config"
+	"crypto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// กุญแจหนึ่งดอกใน key ring
+// HS256 ใช้ secret เดียวกันทั้ง PrivateKey และ PublicKey
+// ถ้า PrivateKey ว่าง กุญแจดอกนั้นใช้ verify ได้อย่างเดียว
+type SigningKey struct {
+	Kid        string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// โหลดกุญแจทั้งหมด พร้อมบอกว่า kid ไหนเป็นตัวเซ็น
+type KeyLoader func() (keys []SigningKey, activeKid string, err error)
+
+// กุญแจที่ใช้เซ็น (active) + กุญแจเก่าที่ยัง verify ได้ (retired)
+type KeyRing struct {
+	mu     sync.RWMutex
+	method jwt.SigningMethod
+	loader KeyLoader
+	active string
+	keys   map[string]SigningKey
+	order  []string
+}
+
+func NewKeyRing(method jwt.SigningMethod, loader KeyLoader) (*KeyRing, error) {
+	ring := &KeyRing{method: method, loader: loader}
+	if err := ring.Reload(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// key ring ตาม SIGNATURE_ALG / SIGNATURE_KEY_DIR
+func NewAppKeyRing() *KeyRing {
+	method, err := signingMethod(config.Env.SignatureAlg)
+	if err != nil {
+		logger.Fatal(slog.Default(), "invalid SIGNATURE_ALG", "error", err)
+	}
+
+	loader := envKeyLoader(method)
+	if config.Env.SignatureKeyDir != "" {
+		loader = dirKeyLoader(method, config.Env.SignatureKeyDir, config.Env.SignatureActiveKid)
+	}
+
+	ring, err := NewKeyRing(method, loader)
+	if err != nil {
+		logger.Fatal(slog.Default(), "unable to load signing keys", "alg", method.Alg(), "error", err)
+	}
+	return ring
+}
+
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch strings.ToUpper(alg) {
+	case "", "HS256":
+		return jwt.SigningMethodHS256, nil
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "ES256":
+		return jwt.SigningMethodES256, nil
+	case "EDDSA":
+		return SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported signature algorithm: %s", alg)
+}
+
+func (k *KeyRing) Method() jwt.SigningMethod {
+	return k.method
+}
+
+// โหลดกุญแจใหม่โดยไม่ต้อง restart ถ้าโหลดไม่ผ่านจะใช้ชุดเดิมต่อ
+func (k *KeyRing) Reload() error {
+	keys, activeKid, err := k.loader()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.New("no signing keys found")
+	}
+
+	byKid := make(map[string]SigningKey, len(keys))
+	order := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Kid == "" {
+			return errors.New("signing key without kid")
+		}
+		if _, ok := byKid[key.Kid]; ok {
+			return fmt.Errorf("duplicate kid %s", key.Kid)
+		}
+		if err := checkKeyType(k.method, key.PublicKey); err != nil {
+			return fmt.Errorf("kid %s: %w", key.Kid, err)
+		}
+		byKid[key.Kid] = key
+		order = append(order, key.Kid)
+	}
+	if _, ok := byKid[activeKid]; !ok {
+		return fmt.Errorf("active kid %s not found", activeKid)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.active = activeKid
+	k.keys = byKid
+	k.order = order
+	return nil
+}
+
+func (k *KeyRing) Active() SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[k.active]
+}
+
+// token รุ่นเก่าที่ไม่มี kid จะถูก verify ด้วยกุญแจ active
+func (k *KeyRing) Lookup(kid string) (key SigningKey, ok bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if kid == "" {
+		kid = k.active
+	}
+	key, ok = k.keys[kid]
+	return key, ok
+}
+
+// public key ทุกดอก (active ก่อน) สำหรับ /.well-known/jwks.json
+// HS256 เป็น secret จึงไม่ถูก publish
+func (k *KeyRing) JWKS() JSONWebKeySet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	result := JSONWebKeySet{Keys: []JSONWebKey{}}
+	kids := append([]string{k.active}, k.order...)
+	for i, kid := range kids {
+		if i > 0 && kid == k.active {
+			continue
+		}
+		jwk, ok := newJSONWebKey(k.method, k.keys[kid])
+		if ok {
+			result.Keys = append(result.Keys, jwk)
+		}
+	}
+	return result
+}
@@ -0,0 +1,216 @@
+package authorization
+
+import (
+	"7solutions/backend/config"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// NOTE Loader -----------------------------
+
+// กุญแจดอกเดียวจาก SIGNATURE_KEY หรือ SIGNATURE_PRIVATE_KEY / SIGNATURE_PUBLIC_KEY
+// kid คำนวณจากตัวกุญแจ ทุก instance จึงได้ค่าเดียวกัน
+func envKeyLoader(method jwt.SigningMethod) KeyLoader {
+	return func() (keys []SigningKey, activeKid string, err error) {
+		var key SigningKey
+		if method == jwt.SigningMethodHS256 {
+			key = hmacKey(config.Env.SignatureKey)
+		} else {
+			key, err = loadKeyPair(config.Env.SignaturePrivateKey, config.Env.SignaturePublicKey)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		key.Kid, err = keyThumbprint(method, key)
+		if err != nil {
+			return nil, "", err
+		}
+		return []SigningKey{key}, key.Kid, nil
+	}
+}
+
+// ทุกไฟล์ใน dir คือกุญแจหนึ่งดอก ชื่อไฟล์ (ไม่รวมนามสกุล) คือ kid
+// HS256 ใช้ไฟล์ .key ที่เก็บ secret ส่วนแบบอื่นใช้ .pem (private หรือ public)
+// ตัวเซ็นคือ activeKid หรือถ้าไม่ระบุจะใช้ kid ที่เรียงแล้วอยู่ท้ายสุด เช่น 2025-01.pem -> 2025-02.pem
+func dirKeyLoader(method jwt.SigningMethod, dir string, activeKid string) KeyLoader {
+	return func() (keys []SigningKey, active string, err error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, "", err
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".pem" && ext != ".key") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+
+			var key SigningKey
+			if method == jwt.SigningMethodHS256 {
+				raw, err := os.ReadFile(path)
+				if err != nil {
+					return nil, "", err
+				}
+				key = hmacKey(strings.TrimSpace(string(raw)))
+			} else {
+				key, err = loadKeyFile(path)
+				if err != nil {
+					return nil, "", err
+				}
+			}
+			key.Kid = strings.TrimSuffix(entry.Name(), ext)
+			keys = append(keys, key)
+			active = key.Kid
+		}
+
+		if activeKid != "" {
+			active = activeKid
+		}
+		return keys, active, nil
+	}
+}
+
+// กุญแจที่ให้มาตรงๆ เช่นใน test
+func StaticKeyLoader(activeKid string, keys ...SigningKey) KeyLoader {
+	return func() ([]SigningKey, string, error) {
+		return keys, activeKid, nil
+	}
+}
+
+func hmacKey(secret string) SigningKey {
+	return SigningKey{PrivateKey: []byte(secret), PublicKey: []byte(secret)}
+}
+
+func loadKeyPair(privateKeyPath string, publicKeyPath string) (key SigningKey, err error) {
+	if privateKeyPath != "" {
+		key.PrivateKey, err = loadPrivateKey(privateKeyPath)
+		if err != nil {
+			return key, err
+		}
+		key.PublicKey = key.PrivateKey.(crypto.Signer).Public()
+	}
+	if publicKeyPath != "" {
+		key.PublicKey, err = loadPublicKey(publicKeyPath)
+		if err != nil {
+			return key, err
+		}
+	}
+	if key.PublicKey == nil {
+		return key, errors.New("private or public key is required")
+	}
+	return key, nil
+}
+
+// ไฟล์เดียวที่อาจเป็น private หรือ public key ก็ได้
+func loadKeyFile(path string) (key SigningKey, err error) {
+	if privateKey, err := loadPrivateKey(path); err == nil {
+		return SigningKey{PrivateKey: privateKey, PublicKey: privateKey.(crypto.Signer).Public()}, nil
+	}
+	publicKey, err := loadPublicKey(path)
+	if err != nil {
+		return key, err
+	}
+	return SigningKey{PublicKey: publicKey}, nil
+}
+
+// NOTE PEM -----------------------------
+
+func readPEM(path string) (block *pem.Block, err error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ = pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+// รองรับ PKCS#8, PKCS#1 (RSA) และ SEC 1 (EC)
+func loadPrivateKey(path string) (key crypto.PrivateKey, err error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported private key", path)
+}
+
+// รองรับ PKIX, PKCS#1 (RSA) และ certificate
+func loadPublicKey(path string) (key crypto.PublicKey, err error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported public key", path)
+}
+
+func checkKeyType(method jwt.SigningMethod, key crypto.PublicKey) error {
+	switch method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if k, ok := key.([]byte); ok && len(k) > 0 {
+			return nil
+		}
+	case jwt.SigningMethodRS256.Alg():
+		if _, ok := key.(*rsa.PublicKey); ok {
+			return nil
+		}
+	case jwt.SigningMethodES256.Alg():
+		if k, ok := key.(*ecdsa.PublicKey); ok && k.Curve.Params().Name == "P-256" {
+			return nil
+		}
+	case SigningMethodEdDSA.Alg():
+		if _, ok := key.(ed25519.PublicKey); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("key type does not match %s", method.Alg())
+}
+
+// kid จาก RFC 7638 thumbprint ส่วน HS256 ใช้ hash ของ secret
+func keyThumbprint(method jwt.SigningMethod, key SigningKey) (string, error) {
+	if err := checkKeyType(method, key.PublicKey); err != nil {
+		return "", err
+	}
+	if secret, ok := key.PublicKey.([]byte); ok {
+		sum := sha256.Sum256(secret)
+		return hex.EncodeToString(sum[:8]), nil
+	}
+	jwk, _ := newJSONWebKey(method, key)
+	return jwk.thumbprint(), nil
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// environment ที่ log เป็น text อ่านง่าย นอกนั้นเป็น JSON ให้ระบบเก็บ log อ่าน
+const EnvDevelopment = "development"
+
+// สร้าง logger ตาม environment ทุก handler ปิดบังค่าที่เป็นความลับ (ดู Redact)
+func New(w io.Writer, env string, level slog.Level) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	}
+	if env == EnvDevelopment {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// logger ที่ทิ้งทุกข้อความ ใช้ใน test
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// แปลงค่า LOG_LEVEL (debug, info, warn, error) ค่าที่ไม่รู้จักใช้ info
+func ParseLevel(value string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+type contextKey struct{}
+
+// เก็บ logger ของ request (มี request_id) ไว้ใน context
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// logger ของ request ถ้าไม่มีใช้ slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// log ข้อผิดพลาดที่ทำให้ start ต่อไม่ได้แล้วปิดโปรแกรม (แทน log.Fatal)
+func Fatal(logger *slog.Logger, msg string, args ...interface{}) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
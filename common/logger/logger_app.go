@@ -0,0 +1,14 @@
+package logger
+
+import (
+	"7solutions/backend/config"
+	"log/slog"
+	"os"
+)
+
+// logger ของแอปตาม ENV และ LOG_LEVEL ตั้งเป็น slog.Default ด้วย (log.Printf เดิมจะออกผ่าน logger นี้)
+func NewAppLogger() *slog.Logger {
+	logger := New(os.Stdout, config.Env.Env, ParseLevel(config.Env.LogLevel))
+	slog.SetDefault(logger)
+	return logger
+}
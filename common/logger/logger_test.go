@@ -0,0 +1,119 @@
+package logger_test
+
+import (
+	"7solutions/backend/common/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger.New(buf, "production", slog.LevelInfo).Info("hello", "user_id", "u1")
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "u1", entry["user_id"])
+
+	buf.Reset()
+	logger.New(buf, logger.EnvDevelopment, slog.LevelInfo).Info("hello", "user_id", "u1")
+	assert.Contains(t, buf.String(), "msg=hello user_id=u1")
+
+	buf.Reset()
+	logger.New(buf, "production", logger.ParseLevel("warn")).Info("hidden")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, slog.LevelInfo, logger.ParseLevel("verbose"))
+}
+
+func Test_Redact(t *testing.T) {
+	type credentials struct {
+		Email       string `json:"email"`
+		Password    string `json:"password"`
+		MFA         string `json:"code"`
+		TokenID     string `json:"token_id"`
+		APIKeyID    string `json:"api_key_id"`
+		ErrorCode   string `json:"errorCode"`
+		Nested      map[string]string
+		AccessToken string `json:"accessToken"`
+	}
+	cases := []struct {
+		Name  string
+		Attrs []interface{}
+		Check func(t *testing.T, entry map[string]interface{})
+	}{
+		{
+			Name:  "sensitive keys",
+			Attrs: []interface{}{"password", "p@ss", "refresh_token", "r1", "Authorization", "Bearer x", "client_secret", "s1", "code_verifier", "v1", "apikey", "sk_1"},
+			Check: func(t *testing.T, entry map[string]interface{}) {
+				for _, key := range []string{"password", "refresh_token", "Authorization", "client_secret", "code_verifier", "apikey"} {
+					assert.Equal(t, "[REDACTED]", entry[key], key)
+				}
+			},
+		},
+		{
+			Name:  "ids are not secrets",
+			Attrs: []interface{}{"token_id", "jti-1", "api_key_id", "k1", "errorCode", "FORBIDDEN"},
+			Check: func(t *testing.T, entry map[string]interface{}) {
+				assert.Equal(t, "jti-1", entry["token_id"])
+				assert.Equal(t, "k1", entry["api_key_id"])
+				assert.Equal(t, "FORBIDDEN", entry["errorCode"])
+			},
+		},
+		{
+			Name: "struct fields",
+			Attrs: []interface{}{"payload", &credentials{
+				Email: "a@test.com", Password: "p@ss", MFA: "123456", TokenID: "jti-1", APIKeyID: "k1", ErrorCode: "X",
+				Nested: map[string]string{"newPassword": "n", "name": "bank"}, AccessToken: "eyJ",
+			}},
+			Check: func(t *testing.T, entry map[string]interface{}) {
+				payload := entry["payload"].(map[string]interface{})
+				assert.Equal(t, "a@test.com", payload["email"])
+				assert.Equal(t, "[REDACTED]", payload["password"])
+				assert.Equal(t, "[REDACTED]", payload["code"])
+				assert.Equal(t, "[REDACTED]", payload["accessToken"])
+				assert.Equal(t, "jti-1", payload["token_id"])
+				assert.Equal(t, "k1", payload["api_key_id"])
+				assert.Equal(t, map[string]interface{}{"newPassword": "[REDACTED]", "name": "bank"}, payload["Nested"])
+			},
+		},
+		{
+			Name:  "group",
+			Attrs: []interface{}{slog.Group("request", "token", "t1", "path", "/api/signin")},
+			Check: func(t *testing.T, entry map[string]interface{}) {
+				assert.Equal(t, map[string]interface{}{"token": "[REDACTED]", "path": "/api/signin"}, entry["request"])
+			},
+		},
+		{
+			Name:  "errors keep message",
+			Attrs: []interface{}{"error", errors.New("connection refused")},
+			Check: func(t *testing.T, entry map[string]interface{}) {
+				assert.Equal(t, "connection refused", entry["error"])
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger.New(buf, "production", slog.LevelInfo).Info("test", c.Attrs...)
+			entry := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+			c.Check(t, entry)
+		})
+	}
+}
+
+func Test_FromContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), logger.FromContext(context.Background()))
+
+	buf := &bytes.Buffer{}
+	log := logger.New(buf, "production", slog.LevelInfo).With("request_id", "r1")
+	logger.FromContext(logger.WithContext(context.Background(), log)).Info("hello")
+	assert.Contains(t, buf.String(), `"request_id":"r1"`)
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// NOTE เทียบชื่อ key แบบไม่สนตัวพิมพ์ _ และ - (เช่น access_token = accessToken)
+// ชื่อตรงตัว เช่น key ของ API key หรือ code ของ MFA / authorization code
+var sensitiveNames = []string{"key", "code", "apikey", "authorization", "cookie", "setcookie", "recoverycodes"}
+
+// ชื่อที่ลงท้าย เช่น password, newPassword, refreshToken, client_secret, code_verifier
+var sensitiveSuffixes = []string{"password", "token", "secret", "verifier"}
+
+func sensitive(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	if slices.Contains(sensitiveNames, key) {
+		return true
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if sensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		attr.Value = Redact(attr.Value.Any())
+	}
+	return attr
+}
+
+// ปิดบัง field ที่เป็นความลับใน struct, map หรือ slice (ตามชื่อ field ใน JSON) ค่าอื่นคืนตามเดิม
+func Redact(value interface{}) slog.Value {
+	if _, ok := value.(error); ok || value == nil {
+		return slog.AnyValue(value)
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return slog.AnyValue(value)
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return slog.AnyValue(value)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return slog.AnyValue(value)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return slog.AnyValue(value)
+	}
+	return slog.AnyValue(redactJSON(decoded))
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if sensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
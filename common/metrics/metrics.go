@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ผลของการ sign in (label result ของ signin_total)
+const (
+	SignInSuccess     = "success"
+	SignInFailure     = "failure"
+	SignInMFARequired = "mfa_required"
+)
+
+// metrics ของแอปในรูปแบบ Prometheus
+// NOTE ใช้ registry ของตัวเอง (ไม่ใช้ global) เพื่อให้ test สร้างใหม่ได้ทุกครั้งโดยไม่ชนกัน
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	mongoDuration   *prometheus.HistogramVec
+	mongoErrors     *prometheus.CounterVec
+	signIns         *prometheus.CounterVec
+	users           prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mongo_operation_duration_seconds",
+			Help:    "Mongo command duration by repository method.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"repository", "method"}),
+		mongoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongo_operation_errors_total",
+			Help: "Failed Mongo commands by repository method.",
+		}, []string{"repository", "method"}),
+		signIns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "signin_total",
+			Help: "Sign-in attempts by method (password, mfa, oidc), result (success, failure, mfa_required) and error code.",
+		}, []string{"method", "result", "reason"}),
+		users: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "users_total",
+			Help: "Number of users, refreshed by the background count job.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.mongoDuration,
+		m.mongoErrors,
+		m.signIns,
+		m.users,
+	)
+	return m
+}
+
+// handler สำหรับ GET /metrics (Prometheus text format)
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// NOTE route คือ path ที่ลงทะเบียนไว้ (เช่น /api/user/:id) ไม่ใช่ path จริง เพื่อไม่ให้ label บานตาม id
+func (m *Metrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveMongo(repository string, method string, duration time.Duration, failed bool) {
+	m.mongoDuration.WithLabelValues(repository, method).Observe(duration.Seconds())
+	if failed {
+		m.mongoErrors.WithLabelValues(repository, method).Inc()
+	}
+}
+
+// NOTE reason คือ errorCode ของ response (ว่างเมื่อไม่ใช่ failure)
+func (m *Metrics) SignIn(method string, result string, reason string) {
+	m.signIns.WithLabelValues(method, result, reason).Inc()
+}
+
+func (m *Metrics) SetUsers(count int64) {
+	m.users.Set(float64(count))
+}
@@ -0,0 +1,96 @@
+package metrics_test
+
+import (
+	"7solutions/backend/common/metrics"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ผลของ handler ในรูปแบบ Prometheus text
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, 200, rec.Code)
+
+	raw, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(raw)
+}
+
+func Test_Metrics(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Record   func(m *metrics.Metrics)
+		Expected []string
+	}{
+		{
+			Name: "http request",
+			Record: func(m *metrics.Metrics) {
+				m.ObserveRequest("GET", "/api/user/:id", 200, 30*time.Millisecond)
+				m.ObserveRequest("GET", "/api/user/:id", 404, 2*time.Second)
+			},
+			Expected: []string{
+				`http_requests_total{method="GET",route="/api/user/:id",status="200"} 1`,
+				`http_requests_total{method="GET",route="/api/user/:id",status="404"} 1`,
+				`http_request_duration_seconds_bucket{method="GET",route="/api/user/:id",le="0.05"} 1`,
+				`http_request_duration_seconds_count{method="GET",route="/api/user/:id"} 2`,
+			},
+		},
+		{
+			Name: "mongo operation",
+			Record: func(m *metrics.Metrics) {
+				m.ObserveMongo("user", "GetUserByID", 3*time.Millisecond, false)
+				m.ObserveMongo("user", "GetUserByID", 10*time.Second, true)
+			},
+			Expected: []string{
+				`mongo_operation_duration_seconds_count{method="GetUserByID",repository="user"} 2`,
+				`mongo_operation_errors_total{method="GetUserByID",repository="user"} 1`,
+			},
+		},
+		{
+			Name: "sign in",
+			Record: func(m *metrics.Metrics) {
+				m.SignIn("password", metrics.SignInSuccess, "")
+				m.SignIn("password", metrics.SignInFailure, "INVALID_CREDENTIALS")
+				m.SignIn("password", metrics.SignInFailure, "INVALID_CREDENTIALS")
+				m.SignIn("oidc", metrics.SignInMFARequired, "")
+			},
+			Expected: []string{
+				`signin_total{method="password",reason="",result="success"} 1`,
+				`signin_total{method="password",reason="INVALID_CREDENTIALS",result="failure"} 2`,
+				`signin_total{method="oidc",reason="",result="mfa_required"} 1`,
+			},
+		},
+		{
+			Name: "users gauge",
+			Record: func(m *metrics.Metrics) {
+				m.SetUsers(10)
+				m.SetUsers(7)
+			},
+			Expected: []string{"users_total 7"},
+		},
+		{
+			Name:     "runtime collectors",
+			Record:   func(m *metrics.Metrics) {},
+			Expected: []string{"go_goroutines", "process_start_time_seconds"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			// NOTE registry แยกกันทุกครั้ง สร้างซ้ำได้ไม่ชนกัน
+			m := metrics.New()
+			c.Record(m)
+
+			body := scrape(t, m)
+			for _, line := range c.Expected {
+				assert.Contains(t, body, line)
+			}
+		})
+	}
+}
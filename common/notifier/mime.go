@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// สร้างข้อความ MIME แบบ multipart/alternative (text + html) สำหรับ SMTP และไฟล์ .eml
+func buildMIME(from string, email Email) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain; charset=UTF-8", content: email.Text},
+		{contentType: "text/html; charset=UTF-8", content: email.HTML},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var result bytes.Buffer
+	// NOTE หัวเรื่องภาษาไทยต้อง encode ตาม RFC 2047
+	headers := [][2]string{
+		{"From", from},
+		{"To", email.To},
+		{"Subject", mime.QEncoding.Encode("UTF-8", email.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + messageID() + "@7solutions>"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + writer.Boundary()},
+	}
+	for _, header := range headers {
+		result.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	result.WriteString("\r\n")
+	result.Write(body.Bytes())
+	return result.Bytes(), nil
+}
+
+func messageID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
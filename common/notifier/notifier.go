@@ -0,0 +1,41 @@
+package notifier
+
+import "errors"
+
+// ชื่อ template ของข้อความที่ส่งถึง user (ไฟล์ templates/<lang>/<template>.txt และ .html)
+const (
+	TemplateWelcome           = "welcome"
+	TemplatePasswordReset     = "password-reset"
+	TemplateEmailVerification = "email-verification"
+)
+
+// ภาษาของ template ที่มี
+const (
+	LangEnglish = "en"
+	LangThai    = "th"
+)
+
+var (
+	ErrQueueFull = errors.New("notifier queue is full")
+	ErrClosed    = errors.New("notifier is closed")
+)
+
+type Message struct {
+	To       string
+	Template string
+	Lang     string // ว่าง = ภาษาเริ่มต้นของ driver
+	Data     map[string]string
+}
+
+// ช่องทางส่งข้อความถึง user (เปลี่ยน driver ได้โดยไม่ต้องแก้ service)
+type Notifier interface {
+	Notify(message Message) error
+}
+
+// email ที่ render จาก template แล้ว
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
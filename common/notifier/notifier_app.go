@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/config"
+	"log/slog"
+)
+
+// driver ของ MAIL_DRIVER
+const (
+	DriverConsole = "console"
+	DriverFile    = "file"
+	DriverSMTP    = "smtp"
+)
+
+// notifier ตาม MAIL_DRIVER ส่งแบบ async พร้อม retry ทุก driver
+func NewAppNotifier(log *slog.Logger) *AsyncNotifier {
+	var next Notifier
+	switch config.Env.MailDriver {
+	case "", DriverConsole:
+		next = NewFileNotifier("", config.Env.MailFrom, config.Env.MailLang)
+	case DriverFile:
+		next = NewFileNotifier(config.Env.MailDir, config.Env.MailFrom, config.Env.MailLang)
+	case DriverSMTP:
+		next = NewSMTPNotifier(SMTPConfig{
+			Host:     config.Env.SMTPHost,
+			Port:     config.Env.SMTPPort,
+			Username: config.Env.SMTPUsername,
+			Password: config.Env.SMTPPassword,
+			From:     config.Env.MailFrom,
+			Lang:     config.Env.MailLang,
+		})
+	default:
+		logger.Fatal(log, "unsupported MAIL_DRIVER", "driver", config.Env.MailDriver)
+	}
+	return NewAsyncNotifier(next, config.Env.MailQueueSize, config.Env.MailRetries, config.Env.MailRetryBackoff, log)
+}
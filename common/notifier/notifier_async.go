@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ส่งข้อความใน background พร้อม retry ผู้เรียก Notify ไม่ต้องรอ mail server
+type AsyncNotifier struct {
+	next    Notifier
+	queue   chan Message
+	retries int
+	backoff time.Duration
+	logger  *slog.Logger
+	wg      sync.WaitGroup
+
+	// NOTE ป้องกัน Notify ส่งเข้าคิวที่ปิดแล้ว (request ที่ยังค้างตอนปิด server)
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+}
+
+// retries คือจำนวนครั้งที่ลองใหม่หลังครั้งแรกล้มเหลว รอ backoff, 2*backoff, 4*backoff, ...
+func NewAsyncNotifier(next Notifier, size int, retries int, backoff time.Duration, logger *slog.Logger) *AsyncNotifier {
+	n := &AsyncNotifier{
+		next:    next,
+		queue:   make(chan Message, size),
+		retries: retries,
+		backoff: backoff,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+	n.wg.Add(1)
+	go n.run()
+	return n
+}
+
+// NOTE ไม่ block ถ้าคิวเต็มคืน ErrQueueFull ถ้าปิดไปแล้วคืน ErrClosed
+func (n *AsyncNotifier) Notify(message Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return ErrClosed
+	}
+	select {
+	case n.queue <- message:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// หยุดรับข้อความใหม่และรอส่งข้อความที่ค้างให้เสร็จ (ที่รอ retry จะลองใหม่ทันทีไม่รอ backoff)
+func (n *AsyncNotifier) Close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.done)
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	n.wg.Wait()
+}
+
+func (n *AsyncNotifier) run() {
+	defer n.wg.Done()
+	for message := range n.queue {
+		n.send(message, 0, n.backoff)
+	}
+}
+
+func (n *AsyncNotifier) send(message Message, attempt int, wait time.Duration) {
+	for ; ; attempt++ {
+		err := n.next.Notify(message)
+		if err == nil {
+			return
+		}
+		if attempt >= n.retries {
+			n.logger.Error("notify failed", "template", message.Template, "to", message.To, "attempts", attempt+1, "error", err)
+			return
+		}
+		select {
+		case <-n.done:
+			continue
+		default:
+		}
+		// NOTE รอ backoff นอก worker ข้อความอื่นในคิวจะได้ไม่ต้องรอผู้รับที่ส่งไม่ผ่าน
+		n.wg.Add(1)
+		go n.retry(message, attempt+1, wait)
+		return
+	}
+}
+
+func (n *AsyncNotifier) retry(message Message, attempt int, wait time.Duration) {
+	defer n.wg.Done()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-n.done:
+	}
+	n.send(message, attempt, wait*2)
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type fileNotifier struct {
+	mu   sync.Mutex
+	dir  string
+	out  io.Writer
+	from string
+	lang string
+}
+
+// ใช้ตอน development: เขียนเป็นไฟล์ .eml ลง dir (เปิดด้วยโปรแกรมอ่านเมลได้)
+// ถ้า dir ว่างจะพิมพ์ส่วนที่เป็นข้อความลง stdout แทน (console)
+func NewFileNotifier(dir string, from string, lang string) Notifier {
+	return &fileNotifier{
+		dir:  dir,
+		out:  os.Stdout,
+		from: from,
+		lang: lang,
+	}
+}
+
+func (n *fileNotifier) Notify(message Message) error {
+	email, err := Render(message, n.lang)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.dir == "" {
+		_, err := fmt.Fprintf(n.out, "----- mail to %s: %s -----\n%s", email.To, email.Subject, email.Text)
+		return err
+	}
+
+	raw, err := buildMIME(n.from, email)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(n.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s-%s.eml", time.Now().Format("20060102T150405.000000000"), message.Template, messageID()[:8])
+	return os.WriteFile(filepath.Join(n.dir, name), raw, 0o644)
+}
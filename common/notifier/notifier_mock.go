@@ -0,0 +1,16 @@
+package notifier
+
+import "github.com/stretchr/testify/mock"
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func NewNotifierMock() *MockNotifier {
+	return &MockNotifier{}
+}
+
+func (m *MockNotifier) Notify(message Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
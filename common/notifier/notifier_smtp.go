@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // ว่าง = ไม่ต้อง login
+	Password string
+	From     string
+	Lang     string
+	Timeout  time.Duration
+}
+
+type smtpNotifier struct {
+	config SMTPConfig
+}
+
+// ส่งผ่าน SMTP ใช้ STARTTLS เมื่อ server รองรับ
+// NOTE ส่งแบบ synchronous ห่อด้วย NewAsyncNotifier เพื่อไม่ให้ service ต้องรอ
+func NewSMTPNotifier(config SMTPConfig) Notifier {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &smtpNotifier{config: config}
+}
+
+func (n *smtpNotifier) Notify(message Message) error {
+	email, err := Render(message, n.config.Lang)
+	if err != nil {
+		return err
+	}
+	raw, err := buildMIME(n.config.From, email)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(n.config.Host, n.config.Port), n.config.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(n.config.Timeout))
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
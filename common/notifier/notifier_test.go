@@ -0,0 +1,282 @@
+package notifier_test
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/notifier/smtptest"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var data = map[string]string{
+	"name":      "bank",
+	"token":     "reset-token",
+	"url":       "http://localhost:3000/api/verify-email?token=a&b",
+	"expiresIn": "30m0s",
+}
+
+func Test_Render(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Template string
+		Lang     string
+		Subject  string
+		Contains []string
+	}{
+		{Name: "welcome en", Template: notifier.TemplateWelcome, Lang: notifier.LangEnglish, Subject: "Welcome", Contains: []string{"bank"}},
+		{Name: "welcome th", Template: notifier.TemplateWelcome, Lang: notifier.LangThai, Subject: "ยินดีต้อนรับ", Contains: []string{"bank"}},
+		{Name: "verification en", Template: notifier.TemplateEmailVerification, Lang: notifier.LangEnglish, Subject: "Verify", Contains: []string{"bank", data["url"], "24h0m0s"}},
+		{Name: "verification th", Template: notifier.TemplateEmailVerification, Lang: notifier.LangThai, Subject: "ยืนยัน", Contains: []string{"bank", data["url"]}},
+		{Name: "password reset en", Template: notifier.TemplatePasswordReset, Lang: notifier.LangEnglish, Subject: "password", Contains: []string{"reset-token", "30m0s"}},
+		{Name: "password reset th", Template: notifier.TemplatePasswordReset, Lang: notifier.LangThai, Subject: "ตั้งรหัสผ่านใหม่", Contains: []string{"reset-token", "30m0s"}},
+		{Name: "unknown lang falls back to en", Template: notifier.TemplateWelcome, Lang: "jp", Subject: "Welcome", Contains: []string{"bank"}},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			messageData := map[string]string{}
+			for k, v := range data {
+				messageData[k] = v
+			}
+			if c.Template == notifier.TemplateEmailVerification {
+				messageData["expiresIn"] = "24h0m0s"
+			}
+
+			email, err := notifier.Render(notifier.Message{To: "bank@test.com", Template: c.Template, Lang: c.Lang, Data: messageData}, notifier.LangThai)
+			require.NoError(t, err)
+			assert.Equal(t, "bank@test.com", email.To)
+			assert.Contains(t, email.Subject, c.Subject)
+			assert.NotContains(t, email.Text, "{{")
+			for _, s := range c.Contains {
+				assert.Contains(t, email.Text, s)
+			}
+			assert.Contains(t, email.HTML, "bank")
+		})
+	}
+}
+
+func Test_RenderEscapesHTML(t *testing.T) {
+	email, err := notifier.Render(notifier.Message{Template: notifier.TemplateWelcome, Data: map[string]string{"name": "<script>"}}, notifier.LangEnglish)
+	require.NoError(t, err)
+	assert.Contains(t, email.Text, "<script>")
+	assert.NotContains(t, email.HTML, "<script>")
+}
+
+func Test_RenderUnknownTemplate(t *testing.T) {
+	_, err := notifier.Render(notifier.Message{Template: "unknown"}, notifier.LangEnglish)
+	assert.Error(t, err)
+}
+
+func Test_FileNotifier(t *testing.T) {
+	dir := t.TempDir()
+	notify := notifier.NewFileNotifier(dir, "no-reply@test.com", notifier.LangThai)
+
+	err := notify.Notify(notifier.Message{To: "bank@test.com", Template: notifier.TemplatePasswordReset, Data: data})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	subject, text := parseEmail(t, string(raw))
+	assert.Equal(t, "ตั้งรหัสผ่านใหม่", subject)
+	assert.Contains(t, text, "reset-token")
+}
+
+func Test_SMTPNotifier(t *testing.T) {
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	notify := notifier.NewSMTPNotifier(notifier.SMTPConfig{
+		Host:     server.Host(),
+		Port:     server.Port(),
+		Username: "user",
+		Password: "secret",
+		From:     "no-reply@test.com",
+		Lang:     notifier.LangEnglish,
+	})
+	err := notify.Notify(notifier.Message{To: "bank@test.com", Template: notifier.TemplateEmailVerification, Lang: notifier.LangThai, Data: data})
+	require.NoError(t, err)
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "no-reply@test.com", messages[0].From)
+	assert.Equal(t, []string{"bank@test.com"}, messages[0].To)
+
+	subject, text := parseEmail(t, messages[0].Data)
+	assert.Contains(t, subject, "ยืนยัน")
+	assert.Contains(t, text, data["url"])
+}
+
+func Test_SMTPNotifierRejected(t *testing.T) {
+	server := smtptest.NewServer()
+	defer server.Close()
+	server.FailNext(1)
+
+	notify := notifier.NewSMTPNotifier(notifier.SMTPConfig{Host: server.Host(), Port: server.Port(), From: "no-reply@test.com"})
+	err := notify.Notify(notifier.Message{To: "bank@test.com", Template: notifier.TemplateWelcome, Data: data})
+	assert.Error(t, err)
+	assert.Empty(t, server.Messages())
+}
+
+func Test_AsyncNotifierRetry(t *testing.T) {
+	server := smtptest.NewServer()
+	defer server.Close()
+	server.FailNext(2)
+
+	smtp := notifier.NewSMTPNotifier(notifier.SMTPConfig{Host: server.Host(), Port: server.Port(), From: "no-reply@test.com"})
+	notify := notifier.NewAsyncNotifier(smtp, 10, 3, time.Millisecond, logger.Discard())
+
+	require.NoError(t, notify.Notify(notifier.Message{To: "bank@test.com", Template: notifier.TemplateWelcome, Data: data}))
+	notify.Close()
+
+	assert.Len(t, server.Messages(), 1)
+}
+
+func Test_AsyncNotifierGiveUp(t *testing.T) {
+	next := &countNotifier{err: errors.New("connection refused")}
+	buf := &bytes.Buffer{}
+	notify := notifier.NewAsyncNotifier(next, 10, 2, time.Millisecond, logger.New(buf, "production", slog.LevelInfo))
+
+	require.NoError(t, notify.Notify(notifier.Message{Template: notifier.TemplateWelcome}))
+	notify.Close()
+
+	// NOTE ครั้งแรก + retry 2 ครั้ง
+	assert.Equal(t, 3, next.count())
+	assert.Contains(t, buf.String(), `"msg":"notify failed"`)
+	assert.Contains(t, buf.String(), `"attempts":3`)
+}
+
+func Test_AsyncNotifierDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	next := &countNotifier{wait: release}
+	notify := notifier.NewAsyncNotifier(next, 1, 0, 0, logger.Discard())
+
+	// NOTE ข้อความแรกค้างอยู่ที่ worker ข้อความที่สองรอในคิว ข้อความที่สามคิวเต็ม
+	require.NoError(t, notify.Notify(notifier.Message{}))
+	require.Eventually(t, func() bool { return next.started() }, time.Second, time.Millisecond)
+	require.NoError(t, notify.Notify(notifier.Message{}))
+	assert.ErrorIs(t, notify.Notify(notifier.Message{}), notifier.ErrQueueFull)
+
+	close(release)
+	notify.Close()
+	assert.Equal(t, 2, next.count())
+}
+
+func Test_AsyncNotifierRetryDoesNotBlockQueue(t *testing.T) {
+	next := &countNotifier{err: errors.New("mailbox unavailable"), failTo: "bad@test.com"}
+	notify := notifier.NewAsyncNotifier(next, 10, 2, time.Hour, logger.Discard())
+
+	// NOTE ผู้รับแรกส่งไม่ผ่านและรอ retry อีกนาน ข้อความถัดไปต้องส่งได้เลย
+	require.NoError(t, notify.Notify(notifier.Message{To: "bad@test.com"}))
+	require.NoError(t, notify.Notify(notifier.Message{To: "good@test.com"}))
+	require.Eventually(t, func() bool { return len(next.delivered()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"good@test.com"}, next.delivered())
+
+	// NOTE ปิดแล้ว retry ที่ค้างลองทันทีไม่รอ backoff
+	closed := make(chan struct{})
+	go func() {
+		notify.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close waited for the retry backoff")
+	}
+	// NOTE bad: ครั้งแรก + retry 2 ครั้ง, good: 1 ครั้ง
+	assert.Equal(t, 4, next.count())
+}
+
+func Test_AsyncNotifierClosed(t *testing.T) {
+	notify := notifier.NewAsyncNotifier(&countNotifier{}, 10, 0, 0, logger.Discard())
+	notify.Close()
+	notify.Close()
+
+	assert.ErrorIs(t, notify.Notify(notifier.Message{}), notifier.ErrClosed)
+}
+
+type countNotifier struct {
+	mu     sync.Mutex
+	err    error
+	failTo string // ถ้าระบุ err ใช้กับผู้รับนี้เท่านั้น
+	wait   chan struct{}
+	calls  int
+	sent   []string
+}
+
+func (n *countNotifier) Notify(message notifier.Message) error {
+	n.mu.Lock()
+	n.calls++
+	failed := n.err != nil && (n.failTo == "" || message.To == n.failTo)
+	if !failed {
+		n.sent = append(n.sent, message.To)
+	}
+	n.mu.Unlock()
+	if n.wait != nil {
+		<-n.wait
+	}
+	if failed {
+		return n.err
+	}
+	return nil
+}
+
+func (n *countNotifier) delivered() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.sent...)
+}
+
+func (n *countNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls
+}
+
+func (n *countNotifier) started() bool {
+	return n.count() > 0
+}
+
+// คืนหัวเรื่อง (decode แล้ว) และส่วน text/plain ของข้อความ MIME
+func parseEmail(t *testing.T, raw string) (subject string, text string) {
+	message, err := mail.ReadMessage(strings.NewReader(raw))
+	require.NoError(t, err)
+
+	subject, err = new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain") {
+			text = string(body)
+		}
+	}
+	return subject, text
+}
@@ -0,0 +1,151 @@
+// Package smtptest มี SMTP server ใน process สำหรับ test (แบบเดียวกับ net/http/httptest)
+package smtptest
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// ข้อความที่ server ได้รับ (Data คือข้อความ MIME ทั้งก้อน)
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+type Server struct {
+	Addr string // host:port
+
+	listener net.Listener
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	messages []Message
+	failNext int
+}
+
+// เริ่ม server ที่ 127.0.0.1 port สุ่ม ต้องเรียก Close เมื่อใช้เสร็จ
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("smtptest: failed to listen: " + err.Error())
+	}
+	s := &Server{Addr: listener.Addr().String(), listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Addr)
+	return host
+}
+
+func (s *Server) Port() string {
+	_, port, _ := net.SplitHostPort(s.Addr)
+	return port
+}
+
+// ข้อความที่ได้รับทั้งหมดตามลำดับ
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// ปฏิเสธ n ข้อความถัดไปด้วย 451 (ใช้ทดสอบการ retry)
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *Server) handle(conn *textproto.Conn) {
+	conn.PrintfLine("220 smtptest ready")
+
+	var message Message
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			conn.PrintfLine("250-smtptest")
+			conn.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			conn.PrintfLine("235 authenticated")
+		case "MAIL":
+			if s.shouldFail() {
+				conn.PrintfLine("451 temporary failure")
+				continue
+			}
+			message = Message{From: address(arg)}
+			conn.PrintfLine("250 ok")
+		case "RCPT":
+			message.To = append(message.To, address(arg))
+			conn.PrintfLine("250 ok")
+		case "DATA":
+			conn.PrintfLine("354 end with <CRLF>.<CRLF>")
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			conn.PrintfLine("250 queued")
+		case "RSET", "NOOP":
+			conn.PrintfLine("250 ok")
+		case "QUIT":
+			conn.PrintfLine("221 bye")
+			return
+		default:
+			conn.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func (s *Server) shouldFail() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failNext > 0 {
+		s.failNext--
+		return true
+	}
+	return false
+}
+
+// "FROM:<a@b.com>" -> "a@b.com"
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, ' '); i >= 0 {
+		value = value[:i]
+	}
+	return strings.Trim(value, "<>")
+}
@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+type emailTemplate struct {
+	text *texttemplate.Template // มี block "subject" สำหรับหัวเรื่อง
+	html *htmltemplate.Template
+}
+
+// NOTE key คือ "<lang>/<template>" parse ครั้งเดียวตอนเริ่ม
+var templates = loadTemplates()
+
+func loadTemplates() map[string]emailTemplate {
+	result := map[string]emailTemplate{}
+	for _, lang := range []string{LangEnglish, LangThai} {
+		for _, name := range []string{TemplateWelcome, TemplatePasswordReset, TemplateEmailVerification} {
+			path := "templates/" + lang + "/" + name
+			result[lang+"/"+name] = emailTemplate{
+				text: texttemplate.Must(texttemplate.ParseFS(templateFS, path+".txt")),
+				html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, path+".html")),
+			}
+		}
+	}
+	return result
+}
+
+// render ข้อความเป็น email ตามภาษาของ message (ถ้าไม่ระบุใช้ lang, ไม่มีภาษานั้นใช้ภาษาอังกฤษ)
+func Render(message Message, lang string) (result Email, err error) {
+	if message.Lang != "" {
+		lang = message.Lang
+	}
+	tmpl, ok := templates[lang+"/"+message.Template]
+	if !ok {
+		tmpl, ok = templates[LangEnglish+"/"+message.Template]
+	}
+	if !ok {
+		return result, fmt.Errorf("unknown template: %s", message.Template)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", message.Data); err != nil {
+		return result, err
+	}
+	if err := tmpl.text.Execute(&text, message.Data); err != nil {
+		return result, err
+	}
+	if err := tmpl.html.Execute(&html, message.Data); err != nil {
+		return result, err
+	}
+
+	result = Email{
+		To:      message.To,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}
+	return result, nil
+}
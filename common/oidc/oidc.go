@@ -0,0 +1,338 @@
+// Package oidc เป็น client ของ OpenID Connect provider แบบ authorization code + PKCE
+package oidc
+
+import (
+	"7solutions/backend/common/authorization"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	maxResponseSize = 1 << 20
+
+	// NOTE ยอมให้นาฬิกาของเรากับ provider คลาดกันได้
+	clockSkew = time.Minute
+
+	// NOTE ดึง JWKS ใหม่เมื่อเจอ kid ที่ไม่รู้จักได้ไม่เกินนาทีละครั้ง
+	jwksRefreshInterval = time.Minute
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc: discovery failed")
+	ErrTokenExchange  = errors.New("oidc: token exchange failed")
+	ErrInvalidIDToken = errors.New("oidc: invalid id token")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // ว่าง = public client
+	RedirectURL  string
+	Scopes       []string
+}
+
+// ข้อมูลผู้ใช้จาก ID token ที่ตรวจแล้ว
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider interface {
+	// URL หน้า login ของ provider
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	// แลก authorization code เป็น ID token
+	Exchange(ctx context.Context, code string, codeVerifier string) (idToken string, err error)
+	// ตรวจลายเซ็น issuer audience อายุ และ nonce ของ ID token
+	VerifyIDToken(ctx context.Context, idToken string, nonce string) (Claims, error)
+}
+
+// provider ที่เปิดใช้ key คือชื่อใน URL /api/oidc/:provider
+type Providers map[string]Provider
+
+type provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]crypto.PublicKey
+	keysAt    time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// endpoint และกุญแจอ่านจาก discovery document ของ issuer เมื่อใช้ครั้งแรก
+func NewProvider(config Config) Provider {
+	return &provider{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	doc, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (p *provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	doc, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		if body.Error != "" {
+			return "", fmt.Errorf("%w: %s %s", ErrTokenExchange, body.Error, body.ErrorDescription)
+		}
+		return "", fmt.Errorf("%w: status %d", ErrTokenExchange, resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("%w: %v", ErrTokenExchange, decodeErr)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: response has no id_token", ErrTokenExchange)
+	}
+	return body.IDToken, nil
+}
+
+func (p *provider) VerifyIDToken(ctx context.Context, idToken string, nonce string) (result Claims, err error) {
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(idToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		// NOTE รับเฉพาะลายเซ็นแบบ asymmetric ที่ตรวจด้วยกุญแจจาก JWKS ได้ (RSA, EC และ OKP)
+		// EdDSA ลงทะเบียนไว้ใน authorization เพราะ jwt-go v3 ไม่มีให้
+		switch method := token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			if method != authorization.SigningMethodEdDSA {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	switch {
+	case claims.Issuer != p.config.Issuer:
+		return result, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case !claims.Audience.contains(p.config.ClientID):
+		return result, fmt.Errorf("%w: audience does not contain client id", ErrInvalidIDToken)
+	case claims.AuthorizedParty != "" && claims.AuthorizedParty != p.config.ClientID:
+		return result, fmt.Errorf("%w: unexpected authorized party %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	case claims.Nonce == "" || claims.Nonce != nonce:
+		return result, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return result, fmt.Errorf("%w: subject is required", ErrInvalidIDToken)
+	}
+
+	result = Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}
+	return result, nil
+}
+
+func (p *provider) endpoints(ctx context.Context) (discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return *p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return doc, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	// NOTE issuer ใน document ต้องตรงกับที่ตั้งค่า (OpenID Connect Discovery 4.3)
+	if doc.Issuer != p.config.Issuer {
+		return doc, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, doc.Issuer, p.config.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return doc, fmt.Errorf("%w: missing endpoint", ErrDiscovery)
+	}
+	p.discovery = &doc
+	return doc, nil
+}
+
+// kid ที่ไม่รู้จักอาจเป็นกุญแจที่ provider เพิ่ง rotate จึงดึง JWKS ใหม่
+func (p *provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	doc, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if p.keys == nil || time.Since(p.keysAt) >= jwksRefreshInterval {
+		var set authorization.JSONWebKeySet
+		if err := p.getJSON(ctx, doc.JWKSURI, &set); err != nil {
+			return nil, fmt.Errorf("fetch jwks: %w", err)
+		}
+		keys := map[string]crypto.PublicKey{}
+		for _, jwk := range set.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+			if key, err := jwk.PublicKey(); err == nil {
+				keys[jwk.Kid] = key
+			}
+		}
+		p.keys, p.keysAt = keys, time.Now()
+	}
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(dst)
+}
+
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   flexBool `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+func (c *idTokenClaims) Valid() error {
+	now := time.Now()
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return errors.New("token is expired")
+	}
+	if now.Add(clockSkew).Before(time.Unix(c.IssuedAt, 0)) {
+		return errors.New("token used before issued")
+	}
+	return nil
+}
+
+// aud เป็นได้ทั้ง string และ array
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NOTE บาง provider ส่ง email_verified เป็น string "true"
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = flexBool(v)
+	case string:
+		*b = flexBool(strings.EqualFold(v, "true"))
+	default:
+		*b = false
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package oidc
+
+import "7solutions/backend/config"
+
+// provider ตาม OIDC_PROVIDERS (ไม่ได้ตั้งค่า = ไม่มี)
+func NewAppProviders() Providers {
+	providers := Providers{}
+	for _, c := range config.OIDCConfig() {
+		providers[c.Name] = NewProvider(Config{
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  c.RedirectURL,
+			Scopes:       c.Scopes,
+		})
+	}
+	return providers
+}
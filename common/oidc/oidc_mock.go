@@ -0,0 +1,30 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProvider struct {
+	mock.Mock
+}
+
+func NewProviderMock() *MockProvider {
+	return &MockProvider{}
+}
+
+func (m *MockProvider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	args := m.Called(ctx, state, nonce, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockProvider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	args := m.Called(ctx, code, codeVerifier)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockProvider) VerifyIDToken(ctx context.Context, idToken string, nonce string) (Claims, error) {
+	args := m.Called(ctx, idToken, nonce)
+	return args.Get(0).(Claims), args.Error(1)
+}
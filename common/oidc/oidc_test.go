@@ -0,0 +1,197 @@
+package oidc_test
+
+import (
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/common/oidc/oidctest"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURL = "http://localhost:3000/api/oidc/stub/callback"
+
+func newProvider(idp *oidctest.Server) oidc.Provider {
+	return oidc.NewProvider(oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email"},
+	})
+}
+
+// เปิดหน้า login ของ provider แล้วคืน query ที่ provider redirect กลับมา
+func authorize(t *testing.T, loginURL string) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(loginURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, redirectURL, location.Scheme+"://"+location.Host+location.Path)
+	return location.Query()
+}
+
+func Test_ProviderAuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "sub-1", Email: "a@example.com", EmailVerified: true, Name: "A"})
+	provider := newProvider(idp)
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	loginURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	query := authorize(t, loginURL)
+	assert.Equal(t, "state-1", query.Get("state"))
+
+	// NOTE verifier ไม่ตรงกับ challenge -> provider ปฏิเสธ และ code ใช้ซ้ำไม่ได้
+	_, err = provider.Exchange(context.Background(), query.Get("code"), "wrong-verifier")
+	assert.ErrorIs(t, err, oidc.ErrTokenExchange)
+	assert.ErrorContains(t, err, "invalid_grant")
+
+	query = authorize(t, loginURL)
+	idToken, err := provider.Exchange(context.Background(), query.Get("code"), verifier)
+	require.NoError(t, err)
+	_, err = provider.Exchange(context.Background(), query.Get("code"), verifier)
+	assert.ErrorIs(t, err, oidc.ErrTokenExchange)
+
+	claims, err := provider.VerifyIDToken(context.Background(), idToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, oidc.Claims{Subject: "sub-1", Email: "a@example.com", EmailVerified: true, Name: "A"}, claims)
+
+	_, err = provider.VerifyIDToken(context.Background(), idToken, "nonce-2")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func Test_ProviderVerifyIDToken(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+	user := oidctest.User{Subject: "sub-1", Email: "a@example.com", EmailVerified: true}
+
+	tests := []struct {
+		name     string
+		modify   func(claims jwt.MapClaims)
+		token    func() string
+		verified bool
+		err      string
+	}{
+		{name: "valid", verified: true},
+		{name: "audience array", modify: func(c jwt.MapClaims) { c["aud"] = []string{"other", "client"}; c["azp"] = "client" }, verified: true},
+		{name: "email verified as string", modify: func(c jwt.MapClaims) { c["email_verified"] = "true" }, verified: true},
+		{name: "email not verified", modify: func(c jwt.MapClaims) { c["email_verified"] = false }},
+		{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, err: "unexpected issuer"},
+		{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "other" }, err: "audience does not contain client id"},
+		{name: "wrong authorized party", modify: func(c jwt.MapClaims) { c["azp"] = "other" }, err: "unexpected authorized party"},
+		{name: "wrong nonce", modify: func(c jwt.MapClaims) { c["nonce"] = "other" }, err: "nonce mismatch"},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, err: "token is expired"},
+		{name: "issued in the future", modify: func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }, err: "token used before issued"},
+		{name: "no subject", modify: func(c jwt.MapClaims) { c["sub"] = "" }, err: "subject is required"},
+		{name: "symmetric signature", token: func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": idp.URL, "aud": "client", "sub": "sub-1", "nonce": "nonce", "exp": time.Now().Add(time.Hour).Unix()})
+			signed, _ := token.SignedString([]byte("secret"))
+			return signed
+		}, err: "unexpected signing method HS256"},
+		{name: "signed by another key", token: func() string {
+			other := oidctest.NewServer("client", "secret")
+			defer other.Close()
+			return other.IDToken(user, "nonce", func(c jwt.MapClaims) { c["iss"] = idp.URL })
+		}, err: "verification error"},
+		// NOTE เพิ่งดึง JWKS ไป kid ใหม่จึงยังไม่รู้จัก (ดึงใหม่ได้นาทีละครั้ง)
+		{name: "unknown key", token: func() string {
+			idp.RotateKey()
+			return idp.IDToken(user, "nonce", nil)
+		}, err: "unknown signing key"},
+	}
+
+	provider := newProvider(idp)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := idp.IDToken(user, "nonce", tt.modify)
+			if tt.token != nil {
+				token = tt.token()
+			}
+
+			claims, err := provider.VerifyIDToken(context.Background(), token, "nonce")
+			if tt.err != "" {
+				assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "sub-1", claims.Subject)
+			assert.Equal(t, tt.verified, claims.EmailVerified)
+		})
+	}
+}
+
+func Test_ProviderVerifyIDTokenEdDSA(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+	idp.RotateEd25519Key()
+	user := oidctest.User{Subject: "sub-1", Email: "a@example.com", EmailVerified: true}
+	provider := newProvider(idp)
+
+	claims, err := provider.VerifyIDToken(context.Background(), idp.IDToken(user, "nonce", nil), "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", claims.Subject)
+
+	// NOTE alg ต้องตรงกับชนิดกุญแจ token RS256 ที่อ้าง kid ของกุญแจ OKP ไม่ผ่าน
+	other := oidctest.NewServer("client", "secret")
+	defer other.Close()
+	token := other.IDToken(user, "nonce", func(c jwt.MapClaims) { c["iss"] = idp.URL })
+	parts := strings.Split(token, ".")
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "stub-2"})
+	parts[0] = base64.RawURLEncoding.EncodeToString(header)
+	_, err = provider.VerifyIDToken(context.Background(), strings.Join(parts, "."), "nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	assert.ErrorContains(t, err, "key is of invalid type")
+}
+
+func Test_ProviderDiscoveryIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+
+	provider := oidc.NewProvider(oidc.Config{Issuer: idp.URL + "/", ClientID: "client", RedirectURL: redirectURL})
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+	assert.ErrorContains(t, err, "does not match")
+}
+
+func Test_ProviderCanceledContext(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "sub-1", Email: "a@example.com", EmailVerified: true})
+	provider := newProvider(idp)
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	loginURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	query := authorize(t, loginURL)
+
+	// NOTE request ต้นทางถูกยกเลิก -> ไม่ยิงไปหา provider ต่อ
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = provider.Exchange(ctx, query.Get("code"), verifier)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// NOTE provider ตัวใหม่ยังไม่มี JWKS ใน cache ต้องโหลดด้วย ctx ที่ถูกยกเลิก
+	idToken, err := provider.Exchange(context.Background(), query.Get("code"), verifier)
+	require.NoError(t, err)
+	_, err = newProvider(idp).VerifyIDToken(ctx, idToken, "nonce-1")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	assert.ErrorContains(t, err, context.Canceled.Error())
+}
@@ -0,0 +1,249 @@
+// Package oidctest มี OpenID Connect provider ใน process สำหรับ test (แบบเดียวกับ net/http/httptest)
+// หน้า /authorize อนุมัติทันทีด้วย user ที่ตั้งไว้ใน SetUser
+package oidctest
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/oidc"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// ผู้ใช้ที่ login อยู่ที่ provider
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Server struct {
+	URL          string // issuer
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+	mu     sync.Mutex
+	method jwt.SigningMethod
+	key    crypto.PrivateKey
+	kid    string
+	keys   int
+	user   User
+	codes  map[string]grant
+}
+
+type grant struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	user          User
+}
+
+// เริ่ม provider ที่ 127.0.0.1 port สุ่ม ต้องเรียก Close เมื่อใช้เสร็จ
+func NewServer(clientID string, clientSecret string) *Server {
+	s := &Server{ClientID: clientID, ClientSecret: clientSecret, codes: map[string]grant{}}
+	s.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// user ที่ /authorize จะอนุมัติให้
+func (s *Server) SetUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// สร้างกุญแจเซ็นใหม่ (kid ใหม่) แทนกุญแจเดิม
+func (s *Server) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("oidctest: failed to generate key: " + err.Error())
+	}
+	s.setKey(jwt.SigningMethodRS256, key)
+}
+
+// เหมือน RotateKey แต่เซ็นด้วย Ed25519 (alg EdDSA, kty OKP ใน JWKS)
+func (s *Server) RotateEd25519Key() {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic("oidctest: failed to generate key: " + err.Error())
+	}
+	s.setKey(authorization.SigningMethodEdDSA, key)
+}
+
+func (s *Server) setKey(method jwt.SigningMethod, key crypto.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys++
+	s.method, s.key, s.kid = method, key, "stub-"+strconv.Itoa(s.keys)
+}
+
+// ID token ของ user ที่ใช้ nonce นี้ แก้ claim ได้ผ่าน modify (ใช้ทดสอบ token ที่ไม่ถูกต้อง)
+func (s *Server) IDToken(user User, nonce string, modify func(claims jwt.MapClaims)) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            s.ClientID,
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic("oidctest: failed to sign token: " + err.Error())
+	}
+	return signed
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	switch {
+	case query.Get("client_id") != s.ClientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case err != nil || !redirectURI.IsAbs():
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	case query.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256":
+		http.Error(w, "pkce required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = grant{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		user:          s.user,
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	// NOTE code ใช้ได้ครั้งเดียว
+	s.mu.Lock()
+	g, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") || oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     s.IDToken(g.user, g.nonce, nil),
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	key, kid := s.key, s.kid
+	s.mu.Unlock()
+
+	encode := base64.RawURLEncoding.EncodeToString
+	jwk := map[string]string{"kid": kid, "use": "sig"}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		jwk["kty"], jwk["alg"] = "RSA", "RS256"
+		jwk["n"], jwk["e"] = encode(key.N.Bytes()), encode(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PrivateKey:
+		jwk["kty"], jwk["alg"], jwk["crv"] = "OKP", "EdDSA", "Ed25519"
+		jwk["x"] = encode(key.Public().(ed25519.PublicKey))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{jwk},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
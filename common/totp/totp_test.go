@@ -0,0 +1,83 @@
+package totp_test
+
+import (
+	"7solutions/backend/common/totp"
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NOTE test vector SHA1 จาก RFC 6238 Appendix B (รหัส 8 หลัก ใช้ 6 หลักท้าย)
+var secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func Test_Code(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Time   int64
+		Output string
+	}{
+		{Name: "59", Time: 59, Output: "287082"},
+		{Name: "1111111109", Time: 1111111109, Output: "081804"},
+		{Name: "1111111111", Time: 1111111111, Output: "050471"},
+		{Name: "1234567890", Time: 1234567890, Output: "005924"},
+		{Name: "2000000000", Time: 2000000000, Output: "279037"},
+		{Name: "20000000000", Time: 20000000000, Output: "353130"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			code, err := totp.Code(secret, totp.Step(time.Unix(c.Time, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, c.Output, code)
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := totp.Code(secret, totp.Step(now))
+	require.NoError(t, err)
+
+	step, ok := totp.Validate(secret, code, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	// NOTE นาฬิกาคลาดไม่เกิน 1 ช่วง
+	step, ok = totp.Validate(secret, code, now.Add(totp.Period), 1)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+	_, ok = totp.Validate(secret, code, now.Add(2*totp.Period), 1)
+	assert.False(t, ok)
+
+	_, ok = totp.Validate(secret, "000000", now, 1)
+	assert.False(t, ok)
+	_, ok = totp.Validate(secret, "12345", now, 1)
+	assert.False(t, ok)
+	_, ok = totp.Validate("not base32!", code, now, 1)
+	assert.False(t, ok)
+}
+
+func Test_NewSecret(t *testing.T) {
+	a, err := totp.NewSecret()
+	require.NoError(t, err)
+	b, err := totp.NewSecret()
+	require.NoError(t, err)
+	assert.Len(t, a, 32)
+	assert.NotEqual(t, a, b)
+
+	_, err = totp.Code(a, 1)
+	assert.NoError(t, err)
+}
+
+func Test_URI(t *testing.T) {
+	uri, err := url.Parse(totp.URI("7solutions", "bank@test.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/7solutions:bank@test.com", uri.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	assert.Equal(t, "7solutions", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ปลายทางของ span (TRACE_EXPORTER)
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ชื่อ service เริ่มต้น (เปลี่ยนได้ด้วย OTEL_SERVICE_NAME)
+const ServiceName = "7solutions-backend"
+
+const instrumentationName = "7solutions/backend"
+
+// สร้าง tracer provider ตาม exporter
+// NOTE otlp ส่งแบบ HTTP ตั้งปลายทางด้วย OTEL_EXPORTER_OTLP_ENDPOINT ส่วน sampler ใช้ OTEL_TRACES_SAMPLER ตามมาตรฐาน
+func New(ctx context.Context, exporter string, w io.Writer) (*sdktrace.TracerProvider, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	switch exporter {
+	case ExporterNone, "":
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		// NOTE stdout ใช้ตอนพัฒนา ส่งทันทีไม่ต้องรอ batch
+		opts = append(opts, sdktrace.WithSyncer(exp))
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s", exporter)
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+// เริ่ม span ลูกของ span ใน ctx (ใช้ tracer provider กลางของ otel)
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// ปิด span พร้อมบันทึก error (ถ้ามี)
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// attribute ของ span ที่ใช้ร่วมกันหลาย package
+var (
+	ErrorCodeKey = attribute.Key("app.error_code")
+)
@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/config"
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ตั้ง tracer provider กลางตาม TRACE_EXPORTER และรับ/ส่งต่อ trace ผ่าน header traceparent (W3C)
+// NOTE เรียก shutdown ก่อนปิดแอปเพื่อส่ง span ที่ค้างอยู่
+func NewAppTracer() (shutdown func(context.Context) error) {
+	provider, err := New(context.Background(), config.Env.TraceExporter, os.Stdout)
+	if err != nil {
+		logger.Fatal(slog.Default(), "unable to start tracer", "error", err)
+	}
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown
+}
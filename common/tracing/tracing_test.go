@@ -0,0 +1,72 @@
+package tracing_test
+
+import (
+	"7solutions/backend/common/tracing"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_New(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Exporter string
+		Error    bool
+		Output   bool
+	}{
+		{Name: "none", Exporter: tracing.ExporterNone},
+		{Name: "empty", Exporter: ""},
+		{Name: "stdout", Exporter: tracing.ExporterStdout, Output: true},
+		{Name: "unsupported", Exporter: "zipkin", Error: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			provider, err := tracing.New(context.Background(), c.Exporter, buf)
+			if c.Error {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, span := provider.Tracer("test").Start(context.Background(), "work")
+			span.End()
+			require.NoError(t, provider.Shutdown(context.Background()))
+
+			if c.Output {
+				assert.Contains(t, buf.String(), `"Name":"work"`)
+				assert.Contains(t, buf.String(), tracing.ServiceName)
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func Test_StartEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	_, child := tracing.Start(ctx, "child")
+	tracing.End(child, errors.New("boom"))
+	tracing.End(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, "Error", spans[0].Status().Code.String())
+	assert.Equal(t, "boom", spans[0].Status().Description)
+	assert.Equal(t, "Unset", spans[1].Status().Code.String())
+}
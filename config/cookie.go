@@ -0,0 +1,39 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	AccessTokenCookie  = "Accesstoken"
+	RefreshTokenCookie = "Refreshtoken"
+
+	// NOTE double-submit: JavaScript อ่าน cookie นี้แล้วส่งกลับใน header
+	CSRFCookie = "Csrftoken"
+	CSRFHeader = "X-CSRF-Token"
+
+	// NOTE ผูก state ของ OIDC login กับ browser ที่เริ่ม login (อยู่แค่ระหว่างไป login ที่ provider)
+	OIDCStateCookie   = "Oidcstate"
+	OIDCSessionCookie = "Oidcsession"
+)
+
+// cookie ของ session ตาม COOKIE_DOMAIN, COOKIE_SECURE และ COOKIE_SAME_SITE
+// expires เป็นค่าศูนย์ = ลบ cookie
+func Cookie(name string, value string, expires time.Time, httpOnly bool) *fiber.Cookie {
+	cookie := &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   Env.CookieDomain,
+		Expires:  expires,
+		Secure:   Env.CookieSecure,
+		HTTPOnly: httpOnly,
+		SameSite: Env.CookieSameSite,
+	}
+	if expires.IsZero() {
+		cookie.Expires = time.Unix(0, 0)
+	}
+	return cookie
+}
@@ -4,9 +4,16 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
+// header สำหรับผูก log ของ request (รับจาก proxy/client หรือสร้างใหม่ แล้วตอบกลับใน header เดียวกัน)
+const RequestIDHeader = "X-Request-ID"
+
 func CorsConfig() cors.Config {
 	return cors.Config{
 		AllowOrigins: Env.Cors,
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization, apikey",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, apikey, " + CSRFHeader + ", " + RequestIDHeader,
+		// NOTE ให้ frontend อ่าน request id ไปแนบตอนแจ้งปัญหาได้
+		ExposeHeaders: RequestIDHeader,
+		// NOTE ส่ง cookie ข้าม origin ได้เฉพาะเมื่อระบุ origin (ใช้กับ * ไม่ได้)
+		AllowCredentials: Env.Cors != "*",
 	}
 }
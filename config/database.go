@@ -2,25 +2,82 @@ package config
 
 import (
 	"context"
-	"log"
+	"database/sql"
+	"log/slog"
+	"os"
 	"time"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	_ "modernc.org/sqlite"
 )
 
-func NewAppDatabase() *mongo.Database {
+// NOTE monitor ใช้เก็บ metrics ของแต่ละคำสั่ง (nil ได้)
+func NewAppDatabase(monitor *event.CommandMonitor) *mongo.Database {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(Env.DBURI))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(Env.DBURI).SetMonitor(monitor))
 	if err != nil {
-		log.Fatal(err)
+		fatal("unable to connect to database", "error", err)
 	}
 	// # Check the connection
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
+		fatal("unable to connect to database", "error", err)
 	}
 	return client.Database(Env.DBName)
 }
+
+// สร้าง index ของทุก collection ตอน start (key คือชื่อ collection)
+// NOTE ถ้ามีข้อมูลซ้ำอยู่แล้ว unique index จะสร้างไม่ได้ ต้องแก้ข้อมูลก่อน
+func NewAppIndexes(db *mongo.Database, indexes map[string][]mongo.IndexModel, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for collection, collectionIndexes := range indexes {
+		if len(collectionIndexes) == 0 {
+			continue
+		}
+		names, err := db.Collection(collection).Indexes().CreateMany(ctx, collectionIndexes)
+		if err != nil {
+			logger.Error("unable to create indexes", "collection", collection, "error", err)
+			os.Exit(1)
+		}
+		logger.Debug("ensured indexes", "collection", collection, "indexes", names)
+	}
+}
+
+// ชื่อ driver ของ database/sql ตาม DB_DRIVER
+var sqlDrivers = map[string]string{
+	"postgres": "pgx",
+	"sqlite":   "sqlite",
+}
+
+// เปิดฐานข้อมูล SQL ตาม DB_DRIVER โดยใช้ DB_URI เป็น DSN
+func NewAppSQLDatabase() *sql.DB {
+	driver, ok := sqlDrivers[Env.DBDriver]
+	if !ok {
+		fatal("unsupported DB_DRIVER", "driver", Env.DBDriver)
+	}
+
+	db, err := sql.Open(driver, Env.DBURI)
+	if err != nil {
+		fatal("unable to open database", "error", err)
+	}
+	// NOTE sqlite เขียนได้ทีละ connection (และ :memory: แยกฐานข้อมูลต่อ connection)
+	if Env.DBDriver == "sqlite" {
+		db.SetMaxOpenConns(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// # Check the connection
+	if err := db.PingContext(ctx); err != nil {
+		fatal("unable to connect to database", "error", err)
+	}
+	return db
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// operation ที่ไม่ได้ระบุใน DB_TIMEOUTS ใช้ค่านี้ (ถ้ามี)
+const DBTimeoutDefault = "*"
+
+// เวลาสูงสุดของคำสั่งฐานข้อมูลจาก DB_TIMEOUTS key คือ "<repository>.<Method>", "<repository>" หรือ "*"
+func DBTimeoutConfig() map[string]time.Duration {
+	timeouts, err := ParseDBTimeouts(Env.DBTimeouts)
+	if err != nil {
+		fatal("invalid DB_TIMEOUTS", "error", err)
+	}
+	return timeouts
+}
+
+// รูปแบบ "rate_limit=2s, user.CountUser=5s, *=10s" (0 = ไม่จำกัด แต่ยังถูกยกเลิกตาม request)
+func ParseDBTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, timeout, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: missing =", entry)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%q: key must be \"<repository>.<Method>\", \"<repository>\" or %q", entry, DBTimeoutDefault)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(timeout))
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("%q: invalid timeout %q", entry, timeout)
+		}
+		timeouts[key] = duration
+	}
+	return timeouts, nil
+}
@@ -0,0 +1,51 @@
+package config_test
+
+import (
+	"7solutions/backend/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseDBTimeouts(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  string
+		Output map[string]time.Duration
+		Error  bool
+	}{
+		{
+			Name:  "methods, repositories and default",
+			Input: "user.CountUser=5s, rate_limit = 2s,*=10s, mfa=0,",
+			Output: map[string]time.Duration{
+				"user.CountUser": 5 * time.Second,
+				"rate_limit":     2 * time.Second,
+				"*":              10 * time.Second,
+				"mfa":            0,
+			},
+		},
+		{Name: "empty", Input: "", Output: map[string]time.Duration{}},
+		{Name: "missing timeout", Input: "user", Error: true},
+		{Name: "missing key", Input: "=5s", Error: true},
+		{Name: "invalid timeout", Input: "user=five", Error: true},
+		{Name: "negative timeout", Input: "user=-1s", Error: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			timeouts, err := config.ParseDBTimeouts(c.Input)
+			if c.Error {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, timeouts)
+		})
+	}
+}
+
+func Test_DefaultDBTimeouts(t *testing.T) {
+	timeouts, err := config.ParseDBTimeouts(config.Env.DBTimeouts)
+	assert.NoError(t, err)
+	assert.Contains(t, timeouts, config.DBTimeoutDefault)
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/spf13/viper"
@@ -10,19 +13,136 @@ import (
 // Set default environments
 var Env = struct {
 	// Environment settings
-	Env          string        `mapstructure:"ENV"`                              // ระบุ environment ที่ใช้งาน เช่น development, staging, production
+	Env          string        `mapstructure:"ENV"`                              // ระบุ environment ที่ใช้งาน เช่น development, staging, production (development = log แบบ text นอกนั้น JSON)
+	LogLevel     string        `mapstructure:"LOG_LEVEL"`                        // ระดับ log ขั้นต่ำ: debug, info, warn, error
 	Port         string        `mapstructure:"PORT"`                             // พอร์ตที่แอปจะรันอยู่
 	Cors         string        `mapstructure:"CORS"`                             // รายการ origin ที่อนุญาต (CORS)
 	AppHost      string        `mapstructure:"APP_HOST" validate:"required,uri"` // Host ของแอปพลิเคชัน
-	DBURI        string        `mapstructure:"DB_URI" validate:"required"`
+	DBDriver     string        `mapstructure:"DB_DRIVER"`                        // ฐานข้อมูลที่ใช้: mongo, postgres, sqlite
+	DBURI        string        `mapstructure:"DB_URI" validate:"required"`       // mongo URI หรือ DSN ของ postgres/sqlite
 	DBName       string        `mapstructure:"DB_NAME" validate:"required"`
 	SignatureKey string        `mapstructure:"SIGNATURE_KEY"`
 	SignatureExp time.Duration `mapstructure:"SIGNATURE_EXP"`
+
+	// JWT signing settings
+	SignatureAlg        string        `mapstructure:"SIGNATURE_ALG"`         // อัลกอริทึมที่ใช้เซ็น token: HS256, RS256, ES256, EdDSA
+	SignaturePrivateKey string        `mapstructure:"SIGNATURE_PRIVATE_KEY"` // path ไฟล์ PEM private key (ไม่ต้องใส่ถ้า validate อย่างเดียว)
+	SignaturePublicKey  string        `mapstructure:"SIGNATURE_PUBLIC_KEY"`  // path ไฟล์ PEM public key
+	SignatureKeyDir     string        `mapstructure:"SIGNATURE_KEY_DIR"`     // โฟลเดอร์ key ring ชื่อไฟล์คือ kid (ถ้าตั้งค่าจะใช้แทน SIGNATURE_KEY และไฟล์ PEM ด้านบน)
+	SignatureActiveKid  string        `mapstructure:"SIGNATURE_ACTIVE_KID"`  // kid ที่ใช้เซ็น ถ้าว่างใช้ไฟล์ที่ชื่อเรียงท้ายสุด
+	RefreshTokenExp     time.Duration `mapstructure:"REFRESH_TOKEN_EXP"`     // อายุของ refresh token
+	PasswordResetExp    time.Duration `mapstructure:"PASSWORD_RESET_EXP"`    // อายุของ token สำหรับตั้งรหัสผ่านใหม่
+
+	// Email verification settings
+	EmailVerificationExp time.Duration `mapstructure:"EMAIL_VERIFICATION_EXP"` // อายุของ token ยืนยัน email
+	RequireEmailVerified bool          `mapstructure:"REQUIRE_EMAIL_VERIFIED"` // true = sign in ไม่ได้จนกว่าจะยืนยัน email
+
+	// Sign in protection settings
+	LoginMaxAttempts   int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`    // sign in ผิดกี่ครั้งต่อ email จึงล็อก
+	LoginIPMaxAttempts int           `mapstructure:"LOGIN_IP_MAX_ATTEMPTS"` // sign in ผิดกี่ครั้งต่อ IP จึงล็อก
+	LoginAttemptWindow time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`  // ช่วงเวลาที่นับครั้งที่ผิด
+	LoginLockout       time.Duration `mapstructure:"LOGIN_LOCKOUT"`         // ระยะเวลาที่ล็อก
+	LoginDelay         time.Duration `mapstructure:"LOGIN_DELAY"`           // เวลารอหลังผิดครั้งแรก (เพิ่มเท่าตัวทุกครั้งที่ผิด)
+
+	// Browser session (cookie) settings
+	CookieDomain   string `mapstructure:"COOKIE_DOMAIN"`    // ว่าง = เฉพาะ host ที่ตอบ
+	CookieSecure   bool   `mapstructure:"COOKIE_SECURE"`    // ส่ง cookie ผ่าน https เท่านั้น (ปิดได้ตอนพัฒนาบน http://localhost)
+	CookieSameSite string `mapstructure:"COOKIE_SAME_SITE"` // Strict, Lax หรือ None (None ต้องเปิด COOKIE_SECURE)
+
+	// Two-factor authentication settings
+	MFAEncryptionKey string        `mapstructure:"MFA_ENCRYPTION_KEY"` // key เข้ารหัส TOTP secret (base64 ของ 32 bytes) ต้องตั้งค่าถึงจะเปิด MFA ได้
+	MFAIssuer        string        `mapstructure:"MFA_ISSUER"`         // ชื่อที่แสดงในแอป authenticator
+	MFAChallengeExp  time.Duration `mapstructure:"MFA_CHALLENGE_EXP"`  // อายุของ mfa token ระหว่าง sign in
+
+	// OpenID Connect settings
+	OIDCProviders string        `mapstructure:"OIDC_PROVIDERS"` // ชื่อ provider คั่นด้วย , เช่น "google,keycloak" (ดู OIDCConfig)
+	OIDCStateExp  time.Duration `mapstructure:"OIDC_STATE_EXP"` // เวลาที่ให้ login ที่ provider ให้เสร็จ
+
+	// OAuth2 authorization server settings
+	OAuthCodeExp time.Duration `mapstructure:"OAUTH_CODE_EXP"` // อายุของ authorization code ที่ออกให้ client
+
+	// Metrics settings
+	MetricsToken string `mapstructure:"METRICS_TOKEN"` // Bearer token ที่ต้องส่งมาเมื่อเรียก /metrics (ว่าง = ไม่ต้องส่ง)
+
+	// Tracing settings
+	TraceExporter string `mapstructure:"TRACE_EXPORTER"` // ปลายทางของ span: none, stdout, otlp (ปลายทาง otlp ตั้งด้วย OTEL_EXPORTER_OTLP_ENDPOINT)
+
+	// Timeout settings
+	RequestTimeout  time.Duration `mapstructure:"REQUEST_TIMEOUT"`  // เวลาสูงสุดของหนึ่ง request (0 = ไม่จำกัด) เกินแล้วงานที่ค้างถูกยกเลิกและตอบ 503
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"` // เวลารอ request ที่ค้างอยู่ตอนปิด server ก่อนยกเลิก
+	DBTimeouts      string        `mapstructure:"DB_TIMEOUTS"`      // เวลาสูงสุดของคำสั่งฐานข้อมูล เช่น "rate_limit=2s, *=10s" (ดู DBTimeoutConfig)
+
+	// Rate limit settings
+	RateLimits  string `mapstructure:"RATE_LIMITS"`   // limit ต่อ route เช่น "POST /api/signin=10/1m, *=300/1m" (ดู RateLimitConfig)
+	RateLimitIP string `mapstructure:"RATE_LIMIT_IP"` // limit ต่อ IP ก่อนยืนยันตัวตน รวมทุก route ที่ต้อง login เช่น "600/1m" (0/1m = ไม่จำกัด)
+
+	// Proxy settings
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"` // IP / CIDR ของ reverse proxy เช่น "10.0.0.1, 10.1.0.0/16" (ว่าง = ใช้ IP ของ connection เสมอ)
+	ProxyHeader    string `mapstructure:"PROXY_HEADER"`    // header ที่ proxy ใส่ IP ของ client (อ่านเฉพาะ request ที่มาจาก TRUSTED_PROXIES)
+
+	// Mail settings
+	MailDriver       string        `mapstructure:"MAIL_DRIVER"`        // ช่องทางส่ง email: console, file, smtp
+	MailDir          string        `mapstructure:"MAIL_DIR"`           // โฟลเดอร์เก็บไฟล์ .eml (MAIL_DRIVER=file)
+	MailFrom         string        `mapstructure:"MAIL_FROM"`          // ผู้ส่ง
+	MailLang         string        `mapstructure:"MAIL_LANG"`          // ภาษาของ template: en, th
+	MailQueueSize    int           `mapstructure:"MAIL_QUEUE_SIZE"`    // จำนวนข้อความที่รอส่งได้
+	MailRetries      int           `mapstructure:"MAIL_RETRIES"`       // จำนวนครั้งที่ลองส่งใหม่เมื่อล้มเหลว
+	MailRetryBackoff time.Duration `mapstructure:"MAIL_RETRY_BACKOFF"` // เวลารอก่อนลองใหม่ครั้งแรก (เพิ่มเท่าตัวทุกครั้ง)
+	SMTPHost         string        `mapstructure:"SMTP_HOST"`
+	SMTPPort         string        `mapstructure:"SMTP_PORT"`
+	SMTPUsername     string        `mapstructure:"SMTP_USERNAME"` // ว่าง = ไม่ต้อง login
+	SMTPPassword     string        `mapstructure:"SMTP_PASSWORD"`
 }{
-	Env:     "production",
-	Port:    "3000",
-	Cors:    "*",
-	AppHost: "http://localhost:3000",
+	Env:          "production",
+	LogLevel:     "info",
+	Port:         "3000",
+	Cors:         "*",
+	AppHost:      "http://localhost:3000",
+	DBDriver:     "mongo",
+	SignatureAlg: "HS256",
+
+	RefreshTokenExp:  7 * 24 * time.Hour,
+	PasswordResetExp: 30 * time.Minute,
+
+	EmailVerificationExp: 24 * time.Hour,
+
+	LoginMaxAttempts:   5,
+	LoginIPMaxAttempts: 20,
+	LoginAttemptWindow: 15 * time.Minute,
+	LoginLockout:       15 * time.Minute,
+	LoginDelay:         time.Second,
+
+	CookieSecure:   true,
+	CookieSameSite: "Lax",
+
+	MFAIssuer:       "7solutions",
+	MFAChallengeExp: 5 * time.Minute,
+
+	OIDCStateExp: 10 * time.Minute,
+
+	OAuthCodeExp: time.Minute,
+
+	TraceExporter: "none",
+
+	RequestTimeout:  30 * time.Second,
+	ShutdownTimeout: 10 * time.Second,
+	DBTimeouts:      "rate_limit=2s, login_attempt=5s, revoked_token.IsTokenRevoked=5s, user.CountUser=5s, *=10s",
+
+	RateLimits: "POST /api/signin=10/1m, POST /api/signin/mfa=10/1m, POST /api/create-user=5/1m, POST /api/forgot-password=5/1m, " +
+		"POST /api/reset-password=10/1m, POST /api/verify-email/resend=5/1m, POST /api/token/refresh=30/1m, " +
+		"GET /api/oidc/:provider/login=20/1m, POST /api/oauth/token=60/1m, *=300/1m",
+	RateLimitIP: "600/1m",
+
+	ProxyHeader: "X-Forwarded-For",
+
+	MailDriver:       "console",
+	MailDir:          "mail",
+	MailFrom:         "no-reply@localhost",
+	MailLang:         "en",
+	MailQueueSize:    100,
+	MailRetries:      3,
+	MailRetryBackoff: time.Second,
+	SMTPPort:         "587",
 }
 
 func NewAppInitEnvironment() {
@@ -31,16 +151,23 @@ func NewAppInitEnvironment() {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			log.Println(".env file not found, loading from environment variables only.")
+		// NOTE SetConfigFile คืน error ของ os แทน ConfigFileNotFoundError เมื่อไม่มีไฟล์
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok || errors.Is(err, fs.ErrNotExist) {
+			slog.Info(".env file not found, loading from environment variables only")
 		} else {
-			log.Fatalf("Error reading config file: %s", err)
+			fatal("unable to read config file", "error", err)
 		}
 	}
 
 	if err := viper.Unmarshal(&Env); err != nil {
-		log.Fatalf("Unable to unmarshal environment variables: %s", err)
+		fatal("unable to unmarshal environment variables", "error", err)
 	}
 
-	log.Println("Environment variables loaded successfully.")
+	slog.Info("environment variables loaded")
+}
+
+// NOTE config โหลดก่อนสร้าง logger ของแอป (logger ขึ้นกับ ENV) จึงใช้ slog.Default
+func fatal(msg string, args ...interface{}) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var oidcProviderName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// provider ตาม OIDC_PROVIDERS แต่ละตัวอ่านค่าจาก OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID,
+// OIDC_<NAME>_CLIENT_SECRET และ OIDC_<NAME>_SCOPES (ค่าเริ่มต้น "openid email profile")
+// redirect URL ที่ต้องลงทะเบียนกับ provider คือ APP_HOST/api/oidc/<name>/callback
+func OIDCConfig() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(Env.OIDCProviders, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !oidcProviderName.MatchString(name) {
+			fatal("invalid OIDC_PROVIDERS", "provider", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  strings.TrimSuffix(Env.AppHost, "/") + "/api/oidc/" + name + "/callback",
+			Scopes:       strings.Fields(strings.ReplaceAll(viper.GetString(prefix+"SCOPES"), ",", " ")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			fatal("OIDC provider requires "+prefix+"ISSUER and "+prefix+"CLIENT_ID", "provider", name)
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IP / CIDR ของ reverse proxy จาก TRUSTED_PROXIES ที่เชื่อ PROXY_HEADER ได้
+func TrustedProxiesConfig() []string {
+	proxies, err := ParseTrustedProxies(Env.TrustedProxies)
+	if err != nil {
+		fatal("invalid TRUSTED_PROXIES", "error", err)
+	}
+	return proxies
+}
+
+// รูปแบบ "10.0.0.1, 10.1.0.0/16" (ว่าง = ไม่เชื่อ proxy ไหนเลย)
+func ParseTrustedProxies(value string) ([]string, error) {
+	proxies := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// NOTE fiber ข้ามค่าที่ parse ไม่ได้แบบเงียบ ๆ จึงตรวจเองก่อน
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, fmt.Errorf("%q: invalid CIDR", entry)
+			}
+		} else if net.ParseIP(entry) == nil {
+			return nil, fmt.Errorf("%q: invalid IP", entry)
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies, nil
+}
@@ -0,0 +1,34 @@
+package config_test
+
+import (
+	"7solutions/backend/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseTrustedProxies(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  string
+		Output []string
+		Error  bool
+	}{
+		{Name: "ips and ranges", Input: " 10.0.0.1, 10.1.0.0/16,::1,", Output: []string{"10.0.0.1", "10.1.0.0/16", "::1"}},
+		{Name: "empty", Input: "", Output: []string{}},
+		{Name: "invalid ip", Input: "10.0.0.256", Error: true},
+		{Name: "invalid range", Input: "10.0.0.0/33", Error: true},
+		{Name: "hostname", Input: "proxy.internal", Error: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			proxies, err := config.ParseTrustedProxies(c.Input)
+			if c.Error {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, proxies)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// route ที่ไม่ได้ระบุใน RATE_LIMITS ใช้ policy นี้ (ถ้ามี)
+const RateLimitDefaultRoute = "*"
+
+// อนุญาต Limit request ต่อ Window
+type RateLimitPolicy struct {
+	Limit  int64
+	Window time.Duration
+}
+
+// policy ของแต่ละ route จาก RATE_LIMITS key คือ "<METHOD> <path ของ route>" เช่น "POST /api/signin"
+func RateLimitConfig() map[string]RateLimitPolicy {
+	policies, err := ParseRateLimits(Env.RateLimits)
+	if err != nil {
+		fatal("invalid RATE_LIMITS", "error", err)
+	}
+	return policies
+}
+
+// limit ต่อ IP ก่อนยืนยันตัวตนจาก RATE_LIMIT_IP
+func RateLimitIPConfig() RateLimitPolicy {
+	policy, err := ParseRateLimitPolicy(Env.RateLimitIP)
+	if err != nil {
+		fatal("invalid RATE_LIMIT_IP", "error", err)
+	}
+	return policy
+}
+
+// รูปแบบ "POST /api/signin=10/1m, GET /api/user/:id=60/1m, *=300/1m" (0 = ไม่จำกัด)
+func ParseRateLimits(value string) (map[string]RateLimitPolicy, error) {
+	policies := map[string]RateLimitPolicy{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%q: missing =", entry)
+		}
+		route := strings.Join(strings.Fields(entry[:i]), " ")
+		if route != RateLimitDefaultRoute {
+			method, path, ok := strings.Cut(route, " ")
+			if !ok || !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("%q: route must be \"<METHOD> <path>\" or %q", entry, RateLimitDefaultRoute)
+			}
+			route = strings.ToUpper(method) + " " + path
+		}
+
+		policy, err := ParseRateLimitPolicy(entry[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		policies[route] = policy
+	}
+	return policies, nil
+}
+
+// รูปแบบ "<count>/<window>" เช่น "600/1m" (0 = ไม่จำกัด)
+func ParseRateLimitPolicy(value string) (RateLimitPolicy, error) {
+	policy := RateLimitPolicy{}
+	limit, window, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return policy, fmt.Errorf("limit must be \"<count>/<window>\"")
+	}
+	var err error
+	if policy.Limit, err = strconv.ParseInt(strings.TrimSpace(limit), 10, 64); err != nil || policy.Limit < 0 {
+		return policy, fmt.Errorf("invalid count %q", limit)
+	}
+	if policy.Window, err = time.ParseDuration(strings.TrimSpace(window)); err != nil || policy.Window <= 0 {
+		return policy, fmt.Errorf("invalid window %q", window)
+	}
+	return policy, nil
+}
@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"7solutions/backend/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseRateLimits(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  string
+		Output map[string]config.RateLimitPolicy
+		Error  bool
+	}{
+		{
+			Name:  "routes and default",
+			Input: "post  /api/signin=10/1m, GET /api/user/:id=60/30s,*=300/1h,",
+			Output: map[string]config.RateLimitPolicy{
+				"POST /api/signin":  {Limit: 10, Window: time.Minute},
+				"GET /api/user/:id": {Limit: 60, Window: 30 * time.Second},
+				"*":                 {Limit: 300, Window: time.Hour},
+			},
+		},
+		{Name: "empty", Input: "", Output: map[string]config.RateLimitPolicy{}},
+		{Name: "missing limit", Input: "POST /api/signin", Error: true},
+		{Name: "missing window", Input: "POST /api/signin=10", Error: true},
+		{Name: "invalid count", Input: "POST /api/signin=ten/1m", Error: true},
+		{Name: "invalid window", Input: "POST /api/signin=10/0s", Error: true},
+		{Name: "missing method", Input: "/api/signin=10/1m", Error: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			policies, err := config.ParseRateLimits(c.Input)
+			if c.Error {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, policies)
+		})
+	}
+}
+
+func Test_ParseRateLimitPolicy(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  string
+		Output config.RateLimitPolicy
+		Error  bool
+	}{
+		{Name: "policy", Input: " 600/1m ", Output: config.RateLimitPolicy{Limit: 600, Window: time.Minute}},
+		{Name: "disabled", Input: "0/1m", Output: config.RateLimitPolicy{Window: time.Minute}},
+		{Name: "empty", Input: "", Error: true},
+		{Name: "invalid count", Input: "-1/1m", Error: true},
+		{Name: "invalid window", Input: "600/soon", Error: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			policy, err := config.ParseRateLimitPolicy(c.Input)
+			if c.Error {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, policy)
+		})
+	}
+}
+
+func Test_DefaultRateLimits(t *testing.T) {
+	policies, err := config.ParseRateLimits(config.Env.RateLimits)
+	assert.NoError(t, err)
+	assert.Contains(t, policies, "POST /api/signin")
+	assert.Contains(t, policies, config.RateLimitDefaultRoute)
+}
+
+func Test_DefaultRateLimitIP(t *testing.T) {
+	policy, err := config.ParseRateLimitPolicy(config.Env.RateLimitIP)
+	assert.NoError(t, err)
+	assert.NotZero(t, policy.Limit)
+}
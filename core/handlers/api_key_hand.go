@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type apiKeyHand struct {
+	apiKeySrv services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeySrv services.APIKeyService) apiKeyHand {
+	return apiKeyHand{
+		apiKeySrv: apiKeySrv,
+	}
+}
+
+func (h apiKeyHand) CreateAPIKey(c *fiber.Ctx) error {
+	id := c.Params("id")
+	body := models.SrvCreateAPIKeyModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.apiKeySrv.CreateAPIKey(c.UserContext(), caller(c), id, body)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h apiKeyHand) GetAPIKeys(c *fiber.Ctx) error {
+	id := c.Params("id")
+	result := h.apiKeySrv.GetAPIKeys(c.UserContext(), caller(c), id)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h apiKeyHand) RevokeAPIKey(c *fiber.Ctx) error {
+	result := h.apiKeySrv.RevokeAPIKey(c.UserContext(), caller(c), c.Params("id"), c.Params("keyId"))
+	return c.Status(result.Code).JSON(result)
+}
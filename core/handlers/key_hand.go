@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"7solutions/backend/common/authorization"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type keyHand struct {
+	keyRing *authorization.KeyRing
+}
+
+func NewKeyHandler(keyRing *authorization.KeyRing) keyHand {
+	return keyHand{
+		keyRing: keyRing,
+	}
+}
+
+// public key สำหรับ service อื่นใช้ verify token (RFC 7517)
+func (h keyHand) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.keyRing.JWKS())
+}
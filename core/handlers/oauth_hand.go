@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/services"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type oauthHand struct {
+	oauthSrv services.OAuthService
+}
+
+func NewOAuthHandler(oauthSrv services.OAuthService) oauthHand {
+	return oauthHand{
+		oauthSrv: oauthSrv,
+	}
+}
+
+func (h oauthHand) CreateClient(c *fiber.Ctx) error {
+	body := models.SrvCreateOAuthClientModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.oauthSrv.CreateClient(c.UserContext(), caller(c), body)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h oauthHand) GetClients(c *fiber.Ctx) error {
+	result := h.oauthSrv.GetClients(c.UserContext(), caller(c))
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h oauthHand) DeleteClient(c *fiber.Ctx) error {
+	result := h.oauthSrv.DeleteClient(c.UserContext(), caller(c), c.Params("id"))
+	return c.Status(result.Code).JSON(result)
+}
+
+// frontend ส่ง query ที่ client ส่งมาต่อให้ เพื่อแสดงหน้าขอความยินยอม
+func (h oauthHand) Authorize(c *fiber.Ctx) error {
+	query := models.SrvOAuthAuthorizeModel{}
+	if err := c.QueryParser(&query); err != nil {
+		return errInvalidQuery
+	}
+	result := h.oauthSrv.Authorize(c.UserContext(), caller(c), query)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h oauthHand) Consent(c *fiber.Ctx) error {
+	body := models.SrvOAuthConsentModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.oauthSrv.Consent(c.UserContext(), caller(c), body)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h oauthHand) Token(c *fiber.Ctx) error {
+	body := models.SrvOAuthTokenModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return oauthError(c, 400, "invalid_request", "invalid request body")
+	}
+	if !clientAuth(c, &body.SrvOAuthClientAuthModel) {
+		return oauthError(c, 400, "invalid_request", "invalid authorization header")
+	}
+	return oauthResponse(c, h.oauthSrv.Token(c.UserContext(), body))
+}
+
+func (h oauthHand) Introspect(c *fiber.Ctx) error {
+	body := models.SrvOAuthTokenActionModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return oauthError(c, 400, "invalid_request", "invalid request body")
+	}
+	if !clientAuth(c, &body.SrvOAuthClientAuthModel) {
+		return oauthError(c, 400, "invalid_request", "invalid authorization header")
+	}
+	return oauthResponse(c, h.oauthSrv.Introspect(c.UserContext(), body))
+}
+
+func (h oauthHand) Revoke(c *fiber.Ctx) error {
+	body := models.SrvOAuthTokenActionModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return oauthError(c, 400, "invalid_request", "invalid request body")
+	}
+	if !clientAuth(c, &body.SrvOAuthClientAuthModel) {
+		return oauthError(c, 400, "invalid_request", "invalid authorization header")
+	}
+	return oauthResponse(c, h.oauthSrv.Revoke(c.UserContext(), body))
+}
+
+// client_id/client_secret จาก HTTP Basic (RFC 6749 2.3.1) ถ้ามี header จะใช้แทนค่าใน body
+func clientAuth(c *fiber.Ctx, payload *models.SrvOAuthClientAuthModel) bool {
+	fields := strings.Fields(c.Get("Authorization"))
+	if len(fields) != 2 || fields[0] != "Basic" {
+		return true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return false
+	}
+	id, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return false
+	}
+	// NOTE ค่าใน Basic ถูก form-urlencode ก่อน base64
+	if payload.ClientID, err = url.QueryUnescape(id); err != nil {
+		return false
+	}
+	if payload.ClientSecret, err = url.QueryUnescape(secret); err != nil {
+		return false
+	}
+	return true
+}
+
+// endpoint ของ OAuth ตอบตามรูปแบบใน RFC (ไม่ห่อด้วย models.Response) เพื่อให้ library ฝั่ง client ใช้ได้เลย
+func oauthResponse(c *fiber.Ctx, result models.Response) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+	if result.Status && result.Data == nil {
+		return c.Status(result.Code).Send(nil)
+	}
+	if result.Status {
+		return c.Status(result.Code).JSON(result.Data)
+	}
+	code := strings.ToLower(result.ErrorCode)
+	if code == "internal_error" {
+		code = "server_error"
+	}
+	if result.Code == fiber.StatusUnauthorized && strings.HasPrefix(c.Get("Authorization"), "Basic") {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	return oauthError(c, result.Code, code, result.Message)
+}
+
+func oauthError(c *fiber.Ctx, status int, code string, description string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error":             code,
+		"error_description": description,
+	})
+}
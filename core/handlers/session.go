@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sign in แบบ cookie สำหรับ browser: ?session=cookie
+func cookieSession(c *fiber.Ctx) bool {
+	return c.Query("session") == "cookie"
+}
+
+// ย้าย token จาก response ไปไว้ใน cookie HttpOnly ให้ JavaScript อ่านไม่ได้
+// rotate = สร้าง csrf token ใหม่ (sign in) ไม่งั้นใช้ของเดิมต่อ (refresh)
+func setSession(c *fiber.Ctx, result *models.Response, rotate bool) error {
+	token, ok := result.Data.(models.SrvSignInResModel)
+	if !ok {
+		return nil
+	}
+
+	csrf := c.Cookies(config.CSRFCookie)
+	if rotate || csrf == "" {
+		var err error
+		if csrf, err = utils.Token_Random(32); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	c.Cookie(config.Cookie(config.AccessTokenCookie, token.AccessToken, now.Add(time.Duration(token.ExpiresIn)*time.Second), true))
+	c.Cookie(config.Cookie(config.RefreshTokenCookie, token.RefreshToken, now.Add(config.Env.RefreshTokenExp), true))
+	c.Cookie(config.Cookie(config.CSRFCookie, csrf, now.Add(config.Env.RefreshTokenExp), false))
+
+	result.Data = models.SrvSessionResModel{
+		Type:      "Cookie",
+		ExpiresIn: token.ExpiresIn,
+		CSRFToken: csrf,
+	}
+	return nil
+}
+
+func clearSession(c *fiber.Ctx) {
+	for _, name := range []string{config.AccessTokenCookie, config.RefreshTokenCookie, config.CSRFCookie} {
+		c.Cookie(config.Cookie(name, "", time.Time{}, name != config.CSRFCookie))
+	}
+}
+
+// cookie ระหว่าง login ที่ OIDC provider ห้ามเป็น SameSite=Strict ไม่งั้น browser ไม่ส่งมาตอน provider redirect กลับ
+func oidcCookie(name string, value string, expires time.Time) *fiber.Cookie {
+	cookie := config.Cookie(name, value, expires, true)
+	cookie.Path = "/api/oidc/"
+	if strings.EqualFold(cookie.SameSite, fiber.CookieSameSiteStrictMode) {
+		cookie.SameSite = fiber.CookieSameSiteLaxMode
+	}
+	return cookie
+}
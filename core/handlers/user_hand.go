@@ -1,12 +1,22 @@
 package handlers
 
 import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/config"
+	"7solutions/backend/core/middlewares"
 	"7solutions/backend/core/models"
 	"7solutions/backend/core/services"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// NOTE middlewares.ErrorHandler แปลงเป็น response 400
+var (
+	errInvalidBody  = apperror.BadRequest("INVALID_BODY", "invalid request body")
+	errInvalidQuery = apperror.BadRequest("INVALID_QUERY", "invalid query string")
+)
+
 type userHand struct {
 	userSrv services.UserService
 }
@@ -20,29 +30,168 @@ func NewUserHandler(userSrv services.UserService) userHand {
 func (h userHand) CreateUser(c *fiber.Ctx) error {
 	body := models.SrvCreateUserModel{}
 	if err := c.BodyParser(&body); err != nil {
-		return err
+		return errInvalidBody
 	}
-	result := h.userSrv.CreateUser(body)
+	result := h.userSrv.CreateUser(c.UserContext(), body)
 	return c.Status(result.Code).JSON(result)
 }
 
 func (h userHand) GetUserByID(c *fiber.Ctx) error {
 	id := c.Params("id")
-	result := h.userSrv.GetUserByID(id)
+	result := h.userSrv.GetUserByID(c.UserContext(), caller(c), id)
 	return c.Status(result.Code).JSON(result)
 }
 
 func (h userHand) SignIn(c *fiber.Ctx) error {
 	body := models.SrvSignInModel{}
 	if err := c.BodyParser(&body); err != nil {
-		return err
+		return errInvalidBody
+	}
+	body.IP = c.IP()
+	result := h.userSrv.SignIn(c.UserContext(), body)
+	if cookieSession(c) {
+		if err := setSession(c, &result, true); err != nil {
+			return err
+		}
+	}
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) SignInMFA(c *fiber.Ctx) error {
+	body := models.SrvMFASignInModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.userSrv.SignInMFA(c.UserContext(), body)
+	if cookieSession(c) {
+		if err := setSession(c, &result, true); err != nil {
+			return err
+		}
+	}
+	return c.Status(result.Code).JSON(result)
+}
+
+// redirect ไปหน้า login ของ provider (?session=cookie = ได้ session แบบ cookie ตอน callback)
+func (h userHand) OIDCLogin(c *fiber.Ctx) error {
+	result := h.userSrv.OIDCLogin(c.UserContext(), c.Params("provider"))
+	data, ok := result.Data.(models.SrvOIDCLoginResModel)
+	if !ok {
+		return c.Status(result.Code).JSON(result)
+	}
+
+	expires := time.Now().Add(config.Env.OIDCStateExp)
+	c.Cookie(oidcCookie(config.OIDCStateCookie, data.State, expires))
+	if cookieSession(c) {
+		c.Cookie(oidcCookie(config.OIDCSessionCookie, "cookie", expires))
+	} else {
+		c.Cookie(oidcCookie(config.OIDCSessionCookie, "", time.Time{}))
+	}
+	return c.Redirect(data.URL, fiber.StatusFound)
+}
+
+func (h userHand) OIDCCallback(c *fiber.Ctx) error {
+	query := models.SrvOIDCCallbackModel{}
+	if err := c.QueryParser(&query); err != nil {
+		return errInvalidQuery
+	}
+	query.Provider = c.Params("provider")
+	query.BrowserState = c.Cookies(config.OIDCStateCookie)
+	cookie := c.Cookies(config.OIDCSessionCookie) == "cookie"
+
+	// NOTE state ใช้ได้ครั้งเดียว ลบ cookie ทิ้งไม่ว่าผลจะเป็นอย่างไร
+	c.Cookie(oidcCookie(config.OIDCStateCookie, "", time.Time{}))
+	c.Cookie(oidcCookie(config.OIDCSessionCookie, "", time.Time{}))
+
+	result := h.userSrv.OIDCCallback(c.UserContext(), query)
+	if cookie {
+		if err := setSession(c, &result, true); err != nil {
+			return err
+		}
+	}
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) RefreshToken(c *fiber.Ctx) error {
+	body := models.SrvRefreshTokenModel{}
+	if len(c.Body()) != 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return errInvalidBody
+		}
+	}
+	// NOTE session แบบ cookie ส่ง refresh token มาใน cookie และได้ token ใหม่กลับไปใน cookie
+	cookie := body.RefreshToken == "" && c.Cookies(config.RefreshTokenCookie) != ""
+	if cookie {
+		body.RefreshToken = c.Cookies(config.RefreshTokenCookie)
+	}
+	result := h.userSrv.RefreshToken(c.UserContext(), body)
+	if cookie {
+		if err := setSession(c, &result, false); err != nil {
+			return err
+		}
+	}
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) SignOut(c *fiber.Ctx) error {
+	body := models.SrvSignOutModel{}
+	if len(c.Body()) != 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return errInvalidBody
+		}
+	}
+	if body.RefreshToken == "" {
+		body.RefreshToken = c.Cookies(config.RefreshTokenCookie)
+	}
+	userID, _ := c.Locals("user_id").(string)
+	tokenID, _ := c.Locals("token_id").(string)
+	expiresAt, _ := c.Locals("token_exp").(time.Time)
+	result := h.userSrv.SignOut(c.UserContext(), userID, tokenID, expiresAt, body)
+	if result.Status {
+		clearSession(c)
+	}
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) RevokeUserTokens(c *fiber.Ctx) error {
+	id := c.Params("id")
+	result := h.userSrv.RevokeUserTokens(c.UserContext(), caller(c), id)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) UnlockUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	result := h.userSrv.UnlockUser(c.UserContext(), caller(c), id)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) EnrollMFA(c *fiber.Ctx) error {
+	id := c.Params("id")
+	result := h.userSrv.EnrollMFA(c.UserContext(), caller(c), id)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) ConfirmMFA(c *fiber.Ctx) error {
+	id := c.Params("id")
+	body := models.SrvMFACodeModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
 	}
-	result := h.userSrv.SignIn(body)
+	result := h.userSrv.ConfirmMFA(c.UserContext(), caller(c), id, body)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) ResetMFA(c *fiber.Ctx) error {
+	id := c.Params("id")
+	result := h.userSrv.ResetMFA(c.UserContext(), caller(c), id)
 	return c.Status(result.Code).JSON(result)
 }
 
 func (h userHand) GetUsers(c *fiber.Ctx) error {
-	result := h.userSrv.Gets()
+	query := models.SrvUserQueryModel{}
+	if err := c.QueryParser(&query); err != nil {
+		return errInvalidQuery
+	}
+	result := h.userSrv.Gets(c.UserContext(), caller(c), query)
 	return c.Status(result.Code).JSON(result)
 }
 
@@ -50,14 +199,61 @@ func (h userHand) UpdateUser(c *fiber.Ctx) error {
 	id := c.Params("id")
 	body := models.SrvUpdateUserModel{}
 	if err := c.BodyParser(&body); err != nil {
-		return err
+		return errInvalidBody
 	}
-	result := h.userSrv.UpdateUser(id, body)
+	result := h.userSrv.UpdateUser(c.UserContext(), caller(c), id, body)
 	return c.Status(result.Code).JSON(result)
 }
 
 func (h userHand) DeleteUser(c *fiber.Ctx) error {
 	id := c.Params("id")
-	result := h.userSrv.DeleteUser(id)
+	result := h.userSrv.DeleteUser(c.UserContext(), caller(c), id)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) ChangePassword(c *fiber.Ctx) error {
+	id := c.Params("id")
+	body := models.SrvChangePasswordModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.userSrv.ChangePassword(c.UserContext(), caller(c), id, body)
 	return c.Status(result.Code).JSON(result)
 }
+
+func (h userHand) ForgotPassword(c *fiber.Ctx) error {
+	body := models.SrvForgotPasswordModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.userSrv.ForgotPassword(c.UserContext(), body)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) ResetPassword(c *fiber.Ctx) error {
+	body := models.SrvResetPasswordModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.userSrv.ResetPassword(c.UserContext(), body)
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) VerifyEmail(c *fiber.Ctx) error {
+	result := h.userSrv.VerifyEmail(c.UserContext(), c.Query("token"))
+	return c.Status(result.Code).JSON(result)
+}
+
+func (h userHand) ResendVerification(c *fiber.Ctx) error {
+	body := models.SrvResendVerificationModel{}
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+	result := h.userSrv.ResendVerification(c.UserContext(), body)
+	return c.Status(result.Code).JSON(result)
+}
+
+// ผู้เรียกจากค่าที่ middleware AccessToken เก็บไว้
+func caller(c *fiber.Ctx) models.SrvCallerModel {
+	return middlewares.Caller(c)
+}
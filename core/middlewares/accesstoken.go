@@ -1,48 +1,144 @@
 package middlewares
 
 import (
+	"7solutions/backend/common/apperror"
 	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/utils"
+	"crypto/subtle"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func AccessToken(c *fiber.Ctx) error {
-	var accessToken string
-	cookie := c.Cookies("Accesstoken")
+// รับ Bearer token (หรือ cookie Accesstoken) หรือ API key ใน header apikey
+func AccessToken(auth authorization.AppAuthorization, revokedTokenRepo repositories.RevokedTokenRepository, apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository, clientRepo repositories.OAuthClientRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key := c.Get("apikey"); key != "" {
+			return apiKey(c, key, apiKeyRepo, userRepo)
+		}
 
-	authorizationHeader := c.Get("Authorization")
-	fields := strings.Fields(authorizationHeader)
+		var accessToken string
 
-	if len(fields) != 0 && fields[0] == "Bearer" {
-		accessToken = fields[1]
-	} else {
-		accessToken = cookie
+		// NOTE ใช้ cookie เฉพาะเมื่อไม่มี header Authorization เลย เพราะ CSRF ไม่ตรวจ request ที่มี Bearer
+		// (Bearer ที่ผิดต้องไม่ถอยไปใช้ cookie) และ Basic ที่ browser แนบให้เองต้องไม่ยืนยันตัวตนด้วย cookie
+		authorizationHeader := c.Get("Authorization")
+		fields := strings.Fields(authorizationHeader)
+
+		if authorizationHeader == "" {
+			accessToken = c.Cookies(config.AccessTokenCookie)
+		} else if len(fields) == 2 && fields[0] == "Bearer" {
+			accessToken = fields[1]
+		}
+
+		if accessToken == "" {
+			return abort(c, apperror.Unauthorized("UNAUTHORIZED", "unauthorized"))
+		}
+
+		sub := authorization.AppAuthorizationClaim{}
+		err := auth.ValidateToken(c.UserContext(), accessToken, &sub)
+		if err != nil {
+			return abort(c, apperror.Unauthorized("TOKEN_INVALID", err.Error()))
+		}
+
+		// NOTE Check token ถูกเพิกถอน (sign out / admin revoke)
+		revoked, err := revokedTokenRepo.IsTokenRevoked(c.UserContext(), sub.TokenId, sub.UserId, sub.IssuedTime())
+		if err != nil {
+			return abort(c, err)
+		}
+		if revoked {
+			return abort(c, errTokenRevoked)
+		}
+
+		// NOTE token ที่ออกให้ OAuth client ใช้ไม่ได้อีกเมื่อ client ถูกลบ
+		if sub.ClientId != "" {
+			_, err := clientRepo.GetOAuthClient(c.UserContext(), sub.ClientId)
+			if apperror.Is(err, apperror.KindNotFound) {
+				return abort(c, errTokenRevoked)
+			}
+			if err != nil {
+				return abort(c, err)
+			}
+		}
+
+		// NOTE token รุ่นก่อนมี role ถือเป็น user ธรรมดา
+		role := sub.Role
+		if role == "" {
+			role = models.RoleUser
+		}
+
+		c.Locals("user_id", sub.UserId)
+		c.Locals("role", role)
+		c.Locals("token_id", sub.TokenId)
+		c.Locals("token_exp", sub.ExpiresTime())
+		if sub.ClientId != "" {
+			c.Locals("client_id", sub.ClientId)
+			c.Locals("permissions", strings.Fields(sub.Scope))
+		}
+
+		return c.Next()
 	}
+}
+
+// ถ้า key ถูกใช้ล่าสุดไม่เกินช่วงนี้ ไม่ต้องบันทึกเวลาใหม่ (ลดการเขียนฐานข้อมูลทุก request)
+const apiKeyLastUsedInterval = time.Minute
+
+var (
+	errInvalidAPIKey = apperror.Unauthorized("API_KEY_INVALID", "invalid api key")
+	errTokenRevoked  = apperror.Unauthorized("TOKEN_REVOKED", "token has been revoked")
+)
 
-	if accessToken == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"code":    fiber.StatusUnauthorized,
-			"status":  false,
-			"message": "unauthorized",
-			"data":    "",
-		})
+func apiKey(c *fiber.Ctx, key string, apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository) error {
+	prefix, ok := utils.APIKey_Prefix(key)
+	if !ok {
+		return abort(c, errInvalidAPIKey)
+	}
+	res, err := apiKeyRepo.GetAPIKeyByPrefix(c.UserContext(), prefix)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return abort(c, errInvalidAPIKey)
+	}
+	if err != nil {
+		return abort(c, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.Token_Hash(key)), []byte(res.KeyHash)) != 1 {
+		return abort(c, errInvalidAPIKey)
 	}
 
-	jwtHS256 := authorization.NewJWT_HS256()
+	now := time.Now()
+	if res.RevokedAt != nil {
+		return abort(c, apperror.Unauthorized("API_KEY_REVOKED", "api key has been revoked"))
+	}
+	if res.ExpiresAt != nil && now.After(*res.ExpiresAt) {
+		return abort(c, apperror.Unauthorized("API_KEY_EXPIRED", "api key has expired"))
+	}
 
-	sub := authorization.AppAuthorizationClaim{}
-	err := jwtHS256.ValidateToken(accessToken, &sub)
+	// NOTE ใช้ role ปัจจุบันของเจ้าของ ถูกลดสิทธิ์หรือลบไปแล้ว key จะได้สิทธิ์ตาม
+	user, err := userRepo.GetUserByID(c.UserContext(), res.UserID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return abort(c, errInvalidAPIKey)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"code":    fiber.StatusUnauthorized,
-			"status":  false,
-			"message": err.Error(),
-			"data":    "",
-		})
+		return abort(c, err)
+	}
+	role := user.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	if res.LastUsedAt == nil || now.Sub(*res.LastUsedAt) >= apiKeyLastUsedInterval {
+		if err := apiKeyRepo.UpdateAPIKeyLastUsed(c.UserContext(), res.ID, now); err != nil {
+			logger.FromContext(c.UserContext()).Warn("update api key last used", "api_key_id", res.ID, "error", err)
+		}
 	}
 
-	c.Locals("user_id", sub.UserId)
+	c.Locals("user_id", res.UserID)
+	c.Locals("role", role)
+	c.Locals("api_key_id", res.ID)
+	c.Locals("permissions", res.Permissions)
 
 	return c.Next()
 }
@@ -0,0 +1,172 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/config"
+	"7solutions/backend/core/middlewares"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/utils"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AccessTokenRevocation(t *testing.T) {
+	config.Env.SignatureExp = time.Hour
+	keyRing, _ := authorization.NewKeyRing(jwt.SigningMethodHS256, authorization.StaticKeyLoader("k1",
+		authorization.SigningKey{Kid: "k1", PrivateKey: []byte("secret"), PublicKey: []byte("secret")},
+	))
+	auth := authorization.NewAppAuthorization(keyRing)
+	revokedTokenRepo := repositories.NewRevokedTokenMemoryRepository()
+
+	app := fiber.New()
+	app.Get("/me", middlewares.AccessToken(auth, revokedTokenRepo, repositories.NewAPIKeyMemoryRepository(), repositories.NewUserMemoryRepository(), repositories.NewOAuthClientMemoryRepository()), func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("user_id").(string))
+	})
+	call := func(token string) int {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		return res.StatusCode
+	}
+	signIn := func() (token string, claim authorization.AppAuthorizationClaim) {
+		token, err := auth.GenerateToken(context.Background(), authorization.AppAuthorizationClaim{UserId: "user-1", Issuer: "7solutions"})
+		assert.NoError(t, err)
+		assert.NoError(t, auth.ValidateToken(context.Background(), token, &claim))
+		assert.NotEmpty(t, claim.TokenId)
+		return token, claim
+	}
+
+	first, firstClaim := signIn()
+	second, _ := signIn()
+	assert.Equal(t, fiber.StatusOK, call(first))
+	assert.Equal(t, fiber.StatusUnauthorized, call(""))
+
+	// NOTE sign out เฉพาะ token แรก
+	assert.NoError(t, revokedTokenRepo.RevokeToken(context.Background(), firstClaim.TokenId, firstClaim.ExpiresTime()))
+	assert.Equal(t, fiber.StatusUnauthorized, call(first))
+	assert.Equal(t, fiber.StatusOK, call(second))
+
+	// NOTE admin เพิกถอนทุก token ของ user แต่ token ที่ออกหลังจากนั้นใช้ได้
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, revokedTokenRepo.RevokeUserTokens(context.Background(), "user-1", time.Now(), time.Now().Add(time.Hour)))
+	assert.Equal(t, fiber.StatusUnauthorized, call(second))
+
+	time.Sleep(2 * time.Millisecond)
+	third, _ := signIn()
+	assert.Equal(t, fiber.StatusOK, call(third))
+}
+
+func Test_AccessTokenCookie(t *testing.T) {
+	config.Env.SignatureExp = time.Hour
+	keyRing, _ := authorization.NewKeyRing(jwt.SigningMethodHS256, authorization.StaticKeyLoader("k1",
+		authorization.SigningKey{Kid: "k1", PrivateKey: []byte("secret"), PublicKey: []byte("secret")},
+	))
+	auth := authorization.NewAppAuthorization(keyRing)
+	token, err := auth.GenerateToken(context.Background(), authorization.AppAuthorizationClaim{UserId: "user-1", Issuer: "7solutions"})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/me", middlewares.AccessToken(auth, repositories.NewRevokedTokenMemoryRepository(), repositories.NewAPIKeyMemoryRepository(), repositories.NewUserMemoryRepository(), repositories.NewOAuthClientMemoryRepository()), func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("user_id").(string))
+	})
+
+	cases := []struct {
+		Name          string
+		Authorization string
+		Output        int
+	}{
+		{Name: "cookie only", Output: fiber.StatusOK},
+		{Name: "bearer wins", Authorization: "Bearer " + token, Output: fiber.StatusOK},
+		// NOTE มี header Authorization แล้วไม่ถอยไปใช้ cookie (CSRF ไม่ตรวจ request เหล่านี้)
+		{Name: "invalid bearer", Authorization: "Bearer not-a-token", Output: fiber.StatusUnauthorized},
+		{Name: "basic auth", Authorization: "Basic dXNlcjpwYXNz", Output: fiber.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/me", nil)
+			req.AddCookie(&http.Cookie{Name: config.AccessTokenCookie, Value: token})
+			if c.Authorization != "" {
+				req.Header.Set("Authorization", c.Authorization)
+			}
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, c.Output, res.StatusCode)
+		})
+	}
+}
+
+func Test_AccessTokenAPIKey(t *testing.T) {
+	apiKeyRepo := repositories.NewAPIKeyMemoryRepository()
+	userRepo := repositories.NewUserMemoryRepository()
+	_, err := userRepo.CreateUser(context.Background(), models.RepoCreateUserModel{ID: "user-1", Email: "bank@test.com", Role: models.RoleAdmin})
+	require.NoError(t, err)
+
+	create := func(userID string, expiresAt *time.Time) (key string, id string) {
+		key, prefix, err := utils.APIKey_Generate()
+		require.NoError(t, err)
+		res, err := apiKeyRepo.CreateAPIKey(context.Background(), models.RepoCreateAPIKeyModel{
+			ID: uuid.New().String(), UserID: userID, Name: "batch", Prefix: prefix, KeyHash: utils.Token_Hash(key),
+			Permissions: []string{models.PermissionUserList}, ExpiresAt: expiresAt, CreateAt: time.Now(),
+		})
+		require.NoError(t, err)
+		return key, res.ID
+	}
+
+	app := fiber.New()
+	app.Get("/me", middlewares.AccessToken(authorization.NewAuthorizationMock(), repositories.NewRevokedTokenMemoryRepository(), apiKeyRepo, userRepo, repositories.NewOAuthClientMemoryRepository()), func(c *fiber.Ctx) error {
+		caller := middlewares.Caller(c)
+		return c.SendString(caller.UserID + " " + caller.Role + " " + strings.Join(caller.Permissions, ","))
+	})
+	call := func(key string) (int, string) {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("apikey", key)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	key, id := create("user-1", nil)
+	code, body := call(key)
+	assert.Equal(t, fiber.StatusOK, code)
+	assert.Equal(t, "user-1 admin user:list", body)
+
+	keys, err := apiKeyRepo.GetAPIKeysByUserID(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, keys[0].LastUsedAt)
+
+	past := time.Now().Add(-time.Minute)
+	expired, _ := create("user-1", &past)
+	orphan, _ := create("deleted-user", nil)
+	for _, c := range []struct {
+		Key  string
+		Code string
+	}{
+		{Key: "sk_invalid", Code: "API_KEY_INVALID"},
+		{Key: key + "x", Code: "API_KEY_INVALID"},
+		{Key: expired, Code: "API_KEY_EXPIRED"},
+		{Key: orphan, Code: "API_KEY_INVALID"},
+	} {
+		code, body := call(c.Key)
+		assert.Equal(t, fiber.StatusUnauthorized, code, c.Key)
+		assert.Contains(t, body, c.Code, c.Key)
+	}
+
+	require.NoError(t, apiKeyRepo.RevokeAPIKey(context.Background(), "user-1", id, time.Now()))
+	code, body = call(key)
+	assert.Equal(t, fiber.StatusUnauthorized, code)
+	assert.Contains(t, body, "API_KEY_REVOKED")
+}
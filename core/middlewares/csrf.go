@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/config"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ป้องกัน CSRF แบบ double-submit: request ที่เปลี่ยนข้อมูลและยืนยันตัวตนด้วย cookie
+// ต้องส่ง header X-CSRF-Token ตรงกับ cookie Csrftoken (เว็บอื่นอ่าน cookie ของเราไม่ได้)
+func CSRF() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		// NOTE Bearer token และ API key ต้องใส่ header เอง browser ไม่แนบให้อัตโนมัติ
+		// (HTTP Basic ยกเว้นไม่ได้ browser จำและแนบให้เองได้)
+		fields := strings.Fields(c.Get("Authorization"))
+		if (len(fields) == 2 && fields[0] == "Bearer") || c.Get("apikey") != "" {
+			return c.Next()
+		}
+		if c.Cookies(config.AccessTokenCookie) == "" && c.Cookies(config.RefreshTokenCookie) == "" {
+			return c.Next()
+		}
+
+		cookie := c.Cookies(config.CSRFCookie)
+		header := c.Get(config.CSRFHeader)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			return abort(c, apperror.Forbidden("CSRF_TOKEN_INVALID", "invalid csrf token"))
+		}
+		return c.Next()
+	}
+}
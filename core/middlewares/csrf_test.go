@@ -0,0 +1,53 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/config"
+	"7solutions/backend/core/middlewares"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CSRF(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Method  string
+		Cookies map[string]string
+		Headers map[string]string
+		Output  int
+	}{
+		{Name: "safe method", Method: "GET", Cookies: map[string]string{config.AccessTokenCookie: "token"}, Output: fiber.StatusOK},
+		{Name: "no session cookie", Method: "POST", Output: fiber.StatusOK},
+		{Name: "bearer token", Method: "POST", Cookies: map[string]string{config.AccessTokenCookie: "token"}, Headers: map[string]string{"Authorization": "Bearer token"}, Output: fiber.StatusOK},
+		{Name: "basic auth", Method: "POST", Cookies: map[string]string{config.AccessTokenCookie: "token"}, Headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, Output: fiber.StatusForbidden},
+		{Name: "basic auth without session cookie", Method: "POST", Headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, Output: fiber.StatusOK},
+		{Name: "api key", Method: "DELETE", Cookies: map[string]string{config.AccessTokenCookie: "token"}, Headers: map[string]string{"apikey": "sk_a_b"}, Output: fiber.StatusOK},
+		{Name: "missing header", Method: "POST", Cookies: map[string]string{config.AccessTokenCookie: "token", config.CSRFCookie: "csrf"}, Output: fiber.StatusForbidden},
+		{Name: "missing cookie", Method: "PUT", Cookies: map[string]string{config.AccessTokenCookie: "token"}, Headers: map[string]string{config.CSRFHeader: "csrf"}, Output: fiber.StatusForbidden},
+		{Name: "wrong header", Method: "POST", Cookies: map[string]string{config.RefreshTokenCookie: "token", config.CSRFCookie: "csrf"}, Headers: map[string]string{config.CSRFHeader: "other"}, Output: fiber.StatusForbidden},
+		{Name: "matching header", Method: "POST", Cookies: map[string]string{config.AccessTokenCookie: "token", config.CSRFCookie: "csrf"}, Headers: map[string]string{config.CSRFHeader: "csrf"}, Output: fiber.StatusOK},
+	}
+
+	app := fiber.New()
+	app.Use(middlewares.CSRF())
+	app.All("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			req := httptest.NewRequest(c.Method, "/", nil)
+			for name, value := range c.Headers {
+				req.Header.Set(name, value)
+			}
+			for name, value := range c.Cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			res, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// สาเหตุที่ ctx ของ request ถูกยกเลิกเมื่อ client ปิดการเชื่อมต่อก่อนได้คำตอบ (ดู context.Cause)
+var ErrClientDisconnected = errors.New("client disconnected")
+
+// ตรวจว่า client ปิดการเชื่อมต่อหรือยังทุกช่วงนี้ระหว่างรอ handler
+const disconnectPollInterval = 200 * time.Millisecond
+
+// เรียก cancel เมื่อ client ปิดการเชื่อมต่อ คืน stop ที่ต้องเรียกก่อน handler คืนค่า
+// NOTE fasthttp ไม่แจ้งเมื่อ client ตัดการเชื่อมต่อ จึง peek socket เป็นระยะ (ไม่กินข้อมูลของ request ถัดไป)
+// connection ที่ peek ไม่ได้ (เช่น app.Test หรือระบบที่ไม่ใช่ unix) ไม่ถูกตรวจ
+func watchDisconnect(conn net.Conn, cancel func(error)) (stop func()) {
+	if conn == nil {
+		return func() {}
+	}
+	closed, ok := peekClosed(conn)
+	if !ok {
+		return func() {}
+	}
+	if closed {
+		cancel(ErrClientDisconnected)
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(disconnectPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if closed, _ := peekClosed(conn); closed {
+				cancel(ErrClientDisconnected)
+				return
+			}
+		}
+	}()
+	// NOTE รอ goroutine จบก่อน fasthttp อ่าน request ถัดไปจาก connection เดียวกัน
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package middlewares
+
+import "net"
+
+// NOTE ระบบที่ไม่ใช่ unix ยังไม่รองรับการตรวจว่า client ตัดการเชื่อมต่อ
+func peekClosed(conn net.Conn) (closed bool, ok bool) {
+	return false, false
+}
@@ -0,0 +1,44 @@
+//go:build unix
+
+package middlewares
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// peek หนึ่ง byte แบบไม่ block ได้ EOF หรือ error = client ปิดแล้ว
+// มีข้อมูลรออยู่ (request ถัดไปแบบ pipeline) หรือยังไม่มีอะไรมา = ยังเชื่อมต่ออยู่
+// ok เป็น false เมื่อ connection ไม่ใช่ socket ของระบบ
+func peekClosed(conn net.Conn) (closed bool, ok bool) {
+	if tlsConn, isTLS := conn.(*tls.Conn); isTLS {
+		conn = tlsConn.NetConn()
+	}
+	sysConn, isSys := conn.(syscall.Conn)
+	if !isSys {
+		return false, false
+	}
+	raw, err := sysConn.SyscallConn()
+	if err != nil {
+		return false, false
+	}
+
+	var n int
+	var peekErr error
+	buf := make([]byte, 1)
+	err = raw.Read(func(fd uintptr) bool {
+		n, _, peekErr = syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		return true
+	})
+	switch {
+	case err != nil:
+		return true, true
+	case errors.Is(peekErr, syscall.EAGAIN), errors.Is(peekErr, syscall.EWOULDBLOCK), errors.Is(peekErr, syscall.EINTR):
+		return false, true
+	case peekErr != nil:
+		return true, true
+	}
+	return n == 0, true
+}
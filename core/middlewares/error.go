@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/logger"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// ตอบ error ตามชนิดของ domain error (internal error เก็บรายละเอียดใน log)
+func abort(c *fiber.Ctx, err error) error {
+	appErr := apperror.From(err)
+	switch appErr.Kind {
+	case apperror.KindInternal:
+		logger.FromContext(c.UserContext()).Error("internal error", "error", appErr.Err)
+	case apperror.KindTimeout:
+		logger.FromContext(c.UserContext()).Warn("request timed out", "error", appErr.Err)
+	}
+	return c.Status(appErr.Status()).JSON(fiber.Map{
+		"code":      appErr.Status(),
+		"status":    false,
+		"message":   appErr.Message,
+		"errorCode": appErr.Code,
+		"data":      "",
+	})
+}
+
+// ใช้เป็น fiber.Config.ErrorHandler ให้ error ที่ handler/fiber return ออกมาเป็น JSON แบบเดียวกัน
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		// NOTE เช่น route ไม่มี (404), method ไม่ถูก (405)
+		errorCode := strings.ToUpper(strings.ReplaceAll(utils.StatusMessage(fiberErr.Code), " ", "_"))
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"code":      fiberErr.Code,
+			"status":    false,
+			"message":   fiberErr.Message,
+			"errorCode": errorCode,
+			"data":      "",
+		})
+	}
+	return abort(c, err)
+}
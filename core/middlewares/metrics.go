@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/metrics"
+	"crypto/subtle"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// นับ request และจับเวลาตาม route ที่ลงทะเบียนไว้ (ใส่ต่อจาก AccessLog)
+func Metrics(m *metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		handleError(c, c.Next())
+
+		m.ObserveRequest(c.Method(), c.Route().Path, c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// ป้องกัน /metrics ด้วย Bearer token คงที่ (METRICS_TOKEN) ถ้าว่างเปิดให้ทุกคน
+func MetricsToken(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Next()
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+token)) != 1 {
+			return abort(c, apperror.Unauthorized("UNAUTHORIZED", "unauthorized"))
+		}
+		return c.Next()
+	}
+}
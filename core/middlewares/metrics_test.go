@@ -0,0 +1,54 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/core/middlewares"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Metrics(t *testing.T) {
+	m := metrics.New()
+	app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler})
+	app.Use(middlewares.Metrics(m))
+	app.Get("/metrics", adaptor.HTTPHandler(m.Handler()))
+	app.Get("/api/user/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return apperror.Internal(errors.New("db down"))
+	})
+
+	cases := []struct {
+		Name   string
+		Path   string
+		Status int
+	}{
+		{Name: "ok", Path: "/api/user/1", Status: 200},
+		{Name: "same route", Path: "/api/user/2", Status: 200},
+		{Name: "error returned by handler", Path: "/fail", Status: 500},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest("GET", c.Path, nil), -1)
+			require.NoError(t, err)
+			assert.Equal(t, c.Status, res.StatusCode)
+		})
+	}
+
+	res, err := app.Test(httptest.NewRequest("GET", "/metrics", nil), -1)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `http_requests_total{method="GET",route="/api/user/:id",status="200"} 2`)
+	assert.Contains(t, string(raw), `http_requests_total{method="GET",route="/fail",status="500"} 1`)
+	assert.NotContains(t, string(raw), `route="/api/user/1"`)
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/core/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ต้องใช้ต่อจาก AccessToken ผ่านได้ถ้า role ตรงกับตัวใดตัวหนึ่ง
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, r := range roles {
+			if r == role {
+				return c.Next()
+			}
+		}
+		return forbidden(c)
+	}
+}
+
+// ต้องใช้ต่อจาก AccessToken ผ่านได้ถ้ามีครบทุก permission
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		caller := Caller(c)
+		for _, p := range permissions {
+			if !caller.Can(p) {
+				return forbidden(c)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// ผู้เรียกจากค่าที่ AccessToken ตั้งไว้ใน ctx
+func Caller(c *fiber.Ctx) models.SrvCallerModel {
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	apiKeyID, _ := c.Locals("api_key_id").(string)
+	permissions, _ := c.Locals("permissions").([]string)
+	clientID, _ := c.Locals("client_id").(string)
+	return models.SrvCallerModel{UserID: userID, Role: role, APIKeyID: apiKeyID, Permissions: permissions, ClientID: clientID}
+}
+
+func forbidden(c *fiber.Ctx) error {
+	return abort(c, apperror.Forbidden("FORBIDDEN", "forbidden"))
+}
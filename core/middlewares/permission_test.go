@@ -0,0 +1,61 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/core/middlewares"
+	"7solutions/backend/core/models"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RequirePermission(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Role    string
+		APIKey  []string // สิทธิ์ของ API key (nil = Bearer token)
+		Client  []string // scope ของ token ที่ออกให้ OAuth client
+		UserID  string
+		Handler fiber.Handler
+		Output  int
+	}{
+		{Name: "admin has permission", Role: models.RoleAdmin, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusOK},
+		{Name: "user has no permission", Role: models.RoleUser, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusForbidden},
+		{Name: "api key has permission", Role: models.RoleAdmin, APIKey: []string{models.PermissionUserList}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusOK},
+		{Name: "api key not scoped", Role: models.RoleAdmin, APIKey: []string{models.PermissionUserRead}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusForbidden},
+		{Name: "api key owner lost permission", Role: models.RoleUser, APIKey: []string{models.PermissionUserList}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusForbidden},
+		{Name: "client scoped", Role: models.RoleAdmin, UserID: "u1", Client: []string{models.PermissionUserList}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusOK},
+		{Name: "client not scoped", Role: models.RoleAdmin, UserID: "u1", Client: []string{}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusForbidden},
+		{Name: "client user lacks permission", Role: models.RoleUser, UserID: "u1", Client: []string{models.PermissionUserList}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusForbidden},
+		{Name: "client credentials scoped", Role: models.RoleUser, Client: []string{models.PermissionUserList}, Handler: middlewares.RequirePermission(models.PermissionUserList), Output: fiber.StatusOK},
+		{Name: "role allowed", Role: models.RoleUser, Handler: middlewares.RequireRole(models.RoleAdmin, models.RoleUser), Output: fiber.StatusOK},
+		{Name: "role not allowed", Role: models.RoleUser, Handler: middlewares.RequireRole(models.RoleAdmin), Output: fiber.StatusForbidden},
+		{Name: "no role", Role: "", Handler: middlewares.RequireRole(models.RoleAdmin), Output: fiber.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				ctx.Locals("role", c.Role)
+				ctx.Locals("user_id", c.UserID)
+				if c.APIKey != nil {
+					ctx.Locals("api_key_id", "key-1")
+					ctx.Locals("permissions", c.APIKey)
+				}
+				if c.Client != nil {
+					ctx.Locals("client_id", "client-1")
+					ctx.Locals("permissions", c.Client)
+				}
+				return ctx.Next()
+			}, c.Handler, func(ctx *fiber.Ctx) error {
+				return ctx.SendStatus(fiber.StatusOK)
+			})
+
+			res, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, res.StatusCode)
+		})
+	}
+}
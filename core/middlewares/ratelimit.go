@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/config"
+	"7solutions/backend/core/repositories"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// จำกัดจำนวน request ต่อ route ตาม policy (key "<METHOD> <path ของ route>" หรือ config.RateLimitDefaultRoute)
+// นับแยกตาม API key, user (หลัง AccessToken) หรือ IP ตามลำดับ แบบ sliding window
+// NOTE ใส่เป็น handler ของ route ต่อจาก AccessToken เพื่อให้รู้ user_id
+func RateLimit(store repositories.RateLimitRepository, policies map[string]config.RateLimitPolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route := c.Method() + " " + c.Route().Path
+		policy, ok := policies[route]
+		if !ok {
+			route = config.RateLimitDefaultRoute
+			policy, ok = policies[route]
+		}
+		if !ok || policy.Limit == 0 {
+			return c.Next()
+		}
+
+		return limitRequest(c, store, route+"|"+rateLimitKey(c), policy)
+	}
+}
+
+// จำกัดจำนวน request ต่อ IP ก่อน AccessToken เพื่อให้ request ที่ยืนยันตัวตนไม่ผ่าน (เช่น เดา token) ถูกนับด้วย
+// NOTE ใช้ตัวนับร่วมกันทุก route ที่ต้อง login จึงควรตั้ง limit สูงกว่า policy ของ RateLimit
+func RateLimitIP(store repositories.RateLimitRepository, policy config.RateLimitPolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if policy.Limit == 0 {
+			return c.Next()
+		}
+		return limitRequest(c, store, "auth|ip:"+c.IP(), policy)
+	}
+}
+
+// นับ request ของ key แบบ sliding window ใส่ header RateLimit-* และตอบ 429 เมื่อเกิน
+func limitRequest(c *fiber.Ctx, store repositories.RateLimitRepository, key string, policy config.RateLimitPolicy) error {
+	now := time.Now()
+	windowStart := now.Truncate(policy.Window)
+	counter, err := store.IncrementRateLimit(c.UserContext(), key, windowStart, policy.Window)
+	if err != nil {
+		// NOTE ที่เก็บตัวนับล่มไม่ควรทำให้ทั้งระบบใช้ไม่ได้
+		logger.FromContext(c.UserContext()).Warn("rate limit store unavailable", "error", err)
+		return c.Next()
+	}
+
+	// NOTE ประมาณ sliding window: นับหน้าต่างก่อนหน้าตามสัดส่วนเวลาที่ยังทับกันอยู่
+	elapsed := float64(now.Sub(windowStart)) / float64(policy.Window)
+	used := int64(math.Ceil(float64(counter.PreviousCount)*(1-elapsed))) + counter.Count
+	reset := int64(math.Ceil(windowStart.Add(policy.Window).Sub(now).Seconds()))
+
+	c.Set("RateLimit-Limit", strconv.FormatInt(policy.Limit, 10))
+	c.Set("RateLimit-Remaining", strconv.FormatInt(max(policy.Limit-used, 0), 10))
+	c.Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+
+	if used > policy.Limit {
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(reset, 10))
+		return abort(c, apperror.TooManyRequests("RATE_LIMITED", "too many requests, try again later"))
+	}
+	return c.Next()
+}
+
+func rateLimitKey(c *fiber.Ctx) string {
+	if keyID, _ := c.Locals("api_key_id").(string); keyID != "" {
+		return "key:" + keyID
+	}
+	if userID, _ := c.Locals("user_id").(string); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.IP()
+}
@@ -0,0 +1,133 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/config"
+	"7solutions/backend/core/middlewares"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitApp(store repositories.RateLimitRepository, policies map[string]config.RateLimitPolicy) *fiber.App {
+	app := fiber.New()
+	setUser := func(c *fiber.Ctx) error {
+		if user := c.Get("X-User"); user != "" {
+			c.Locals("user_id", user)
+		}
+		return c.Next()
+	}
+	ok := func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	}
+	limit := middlewares.RateLimit(store, policies)
+	app.Post("/api/signin", setUser, limit, ok)
+	app.Get("/api/user/:id", setUser, limit, ok)
+	return app
+}
+
+func request(t *testing.T, app *fiber.App, method string, path string, user string) (status int, header func(string) string) {
+	req := httptest.NewRequest(method, path, nil)
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	return res.StatusCode, res.Header.Get
+}
+
+func Test_RateLimit(t *testing.T) {
+	app := newRateLimitApp(repositories.NewRateLimitMemoryRepository(), map[string]config.RateLimitPolicy{
+		"POST /api/signin": {Limit: 2, Window: time.Hour},
+		"*":                {Limit: 3, Window: time.Hour},
+	})
+
+	status, header := request(t, app, "POST", "/api/signin", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", header("RateLimit-Limit"))
+	assert.Equal(t, "1", header("RateLimit-Remaining"))
+	assert.NotEmpty(t, header("RateLimit-Reset"))
+
+	status, _ = request(t, app, "POST", "/api/signin", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	status, header = request(t, app, "POST", "/api/signin", "")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "0", header("RateLimit-Remaining"))
+	assert.NotEmpty(t, header("Retry-After"))
+
+	// NOTE route อื่นใช้ policy "*" และนับแยกกัน ส่วน :id ต่างกันถือเป็น route เดียวกัน
+	for i, path := range []string{"/api/user/1", "/api/user/2", "/api/user/3"} {
+		status, header = request(t, app, "GET", path, "")
+		assert.Equal(t, fiber.StatusOK, status, i)
+	}
+	assert.Equal(t, "3", header("RateLimit-Limit"))
+	status, _ = request(t, app, "GET", "/api/user/4", "")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+
+	// NOTE user ที่ login แล้วนับแยกจาก IP
+	status, _ = request(t, app, "GET", "/api/user/1", "user-1")
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func Test_RateLimitNoPolicy(t *testing.T) {
+	app := newRateLimitApp(repositories.NewRateLimitMemoryRepository(), map[string]config.RateLimitPolicy{
+		"POST /api/signin": {Limit: 0, Window: time.Hour},
+	})
+	for i := 0; i < 5; i++ {
+		status, header := request(t, app, "POST", "/api/signin", "")
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Empty(t, header("RateLimit-Limit"))
+		status, _ = request(t, app, "GET", "/api/user/1", "")
+		assert.Equal(t, fiber.StatusOK, status)
+	}
+}
+
+func Test_RateLimitStoreError(t *testing.T) {
+	store := repositories.NewRateLimitRepositoryMock()
+	store.On("IncrementRateLimit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(models.RepoResRateLimitModel{}, errors.New("connection refused"))
+	app := newRateLimitApp(store, map[string]config.RateLimitPolicy{"*": {Limit: 1, Window: time.Minute}})
+
+	// NOTE ที่เก็บตัวนับล่มให้ผ่านไปได้
+	status, _ := request(t, app, "POST", "/api/signin", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	store.AssertExpectations(t)
+}
+
+func Test_RateLimitIP(t *testing.T) {
+	app := fiber.New()
+	unauthorized := func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	// NOTE ใส่ก่อน handler ยืนยันตัวตน request ที่ถูกปฏิเสธจึงถูกนับด้วย
+	ipLimit := middlewares.RateLimitIP(repositories.NewRateLimitMemoryRepository(), config.RateLimitPolicy{Limit: 2, Window: time.Hour})
+	app.Get("/api/user/:id", ipLimit, unauthorized)
+	app.Delete("/api/user/:id", ipLimit, unauthorized)
+
+	status, header := request(t, app, "GET", "/api/user/1", "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, "1", header("RateLimit-Remaining"))
+	status, _ = request(t, app, "DELETE", "/api/user/2", "")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	// NOTE นับรวมทุก route ต่อ IP
+	status, header = request(t, app, "GET", "/api/user/3", "")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.NotEmpty(t, header("Retry-After"))
+}
+
+func Test_RateLimitIPDisabled(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/user/:id", middlewares.RateLimitIP(repositories.NewRateLimitRepositoryMock(), config.RateLimitPolicy{Window: time.Minute}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	status, header := request(t, app, "GET", "/api/user/1", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Empty(t, header("RateLimit-Limit"))
+}
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/config"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// NOTE รับ request id จาก proxy/client ได้เฉพาะรูปแบบที่ปลอดภัยจะใส่ใน log และ header
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// ใช้ X-Request-ID ที่ส่งมา (หรือสร้างใหม่) ตอบกลับใน header เดียวกัน
+// และเก็บ logger ที่มี request_id ไว้ใน c.UserContext() ให้ทุก log ของ request ผูกกันได้
+// NOTE ใส่ต่อจาก RequestContext
+func RequestID(log *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(config.RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		c.Set(config.RequestIDHeader, id)
+		c.Locals("request_id", id)
+		c.SetUserContext(logger.WithContext(c.UserContext(), log.With("request_id", id)))
+		return c.Next()
+	}
+}
+
+// log ทุก request เมื่อตอบเสร็จ (ใส่ต่อจาก RequestID)
+// NOTE บันทึกเฉพาะ path ไม่รวม query string เพราะบาง route ส่ง token มาใน query
+func AccessLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		handleError(c, c.Next())
+
+		status := c.Response().StatusCode()
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", c.Route().Path),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		}
+		for _, key := range []string{"user_id", "client_id", "api_key_id"} {
+			if value, _ := c.Locals(key).(string); value != "" {
+				attrs = append(attrs, slog.String(key, value))
+			}
+		}
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.FromContext(c.UserContext()).LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
+
+// NOTE ให้ ErrorHandler เขียน response ก่อนจึงจะรู้ status จริง
+func handleError(c *fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+	if err := c.App().Config().ErrorHandler(c, err); err != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,122 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/core/middlewares"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestLogApp(buf *bytes.Buffer) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler})
+	app.Use(middlewares.RequestID(logger.New(buf, "production", slog.LevelInfo)))
+	app.Use(middlewares.AccessLog())
+	app.Get("/api/user/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "u1")
+		logger.FromContext(c.UserContext()).Info("handler")
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return apperror.Internal(errors.New("db down"))
+	})
+	return app
+}
+
+// แต่ละบรรทัดของ log แบบ JSON
+func logEntries(t *testing.T, buf *bytes.Buffer) (entries []map[string]interface{}) {
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry), scanner.Text())
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func Test_RequestID(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Header   string
+		Expected string // ว่าง = สร้างใหม่
+	}{
+		{Name: "propagated", Header: "req-123", Expected: "req-123"},
+		{Name: "generated", Header: ""},
+		{Name: "unsafe value replaced", Header: "bad id\nforged=1"},
+		{Name: "too long replaced", Header: string(bytes.Repeat([]byte("a"), 129))},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			req := httptest.NewRequest("GET", "/api/user/u1?token=secret", nil)
+			req.Header.Set("X-Request-ID", c.Header)
+			res, err := newRequestLogApp(buf).Test(req)
+			require.NoError(t, err)
+
+			id := res.Header.Get("X-Request-ID")
+			if c.Expected != "" {
+				assert.Equal(t, c.Expected, id)
+			} else {
+				assert.Len(t, id, 36)
+			}
+			entries := logEntries(t, buf)
+			require.Len(t, entries, 2)
+			for _, entry := range entries {
+				assert.Equal(t, id, entry["request_id"])
+			}
+		})
+	}
+}
+
+func Test_AccessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := newRequestLogApp(buf)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/api/user/u1?token=secret", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	entries := logEntries(t, buf)
+	require.Len(t, entries, 2)
+	access := entries[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "INFO", access["level"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/api/user/:id", access["route"])
+	assert.Equal(t, "/api/user/u1", access["path"])
+	assert.Equal(t, float64(200), access["status"])
+	assert.Equal(t, "u1", access["user_id"])
+	assert.Contains(t, access, "latency_ms")
+	assert.NotContains(t, buf.String(), "secret")
+
+	// NOTE error จาก handler ต้องได้ status จาก ErrorHandler และ log internal error ผูก request id เดียวกัน
+	buf.Reset()
+	res, err = app.Test(httptest.NewRequest("GET", "/fail", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+	entries = logEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "internal error", entries[0]["msg"])
+	assert.Equal(t, "db down", entries[0]["error"])
+	assert.Equal(t, "ERROR", entries[1]["level"])
+	assert.Equal(t, float64(500), entries[1]["status"])
+	assert.Equal(t, entries[0]["request_id"], entries[1]["request_id"])
+	assert.NotContains(t, entries[1], "user_id")
+
+	buf.Reset()
+	res, err = app.Test(httptest.NewRequest("GET", "/missing", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+	entries = logEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, float64(404), entries[0]["status"])
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ตั้ง c.UserContext() ของ request จาก base (ถูกยกเลิกเมื่อปิด server) และจำกัดเวลาไม่เกิน timeout (0 = ไม่จำกัด)
+// ctx ถูกยกเลิกด้วยเมื่อ client ปิดการเชื่อมต่อก่อนได้คำตอบ (context.Cause เป็น ErrClientDisconnected)
+// service / repository ที่ใช้ ctx นี้จะหยุดรอฐานข้อมูลและตอบ 503 TIMEOUT เมื่อหมดเวลาหรือถูกยกเลิก
+// NOTE ต้องเป็น middleware ตัวแรก และไม่ใช้ c.Context() เพราะ fasthttp ยกเลิกทันทีที่เริ่ม shutdown
+// (ไม่รอ request ที่ค้าง) และไม่ถูกยกเลิกเมื่อ client ตัดการเชื่อมต่อ
+func RequestContext(base context.Context, timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancelCause := context.WithCancelCause(base)
+		defer cancelCause(nil)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		stop := watchDisconnect(c.Context().Conn(), cancelCause)
+		defer stop()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,135 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/core/middlewares"
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequestContext(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Timeout  time.Duration
+		Cancel   bool
+		Status   int
+		Deadline bool
+	}{
+		{Name: "finishes in time", Timeout: time.Minute, Status: fiber.StatusOK, Deadline: true},
+		{Name: "no timeout", Timeout: 0, Status: fiber.StatusOK},
+		{Name: "timed out", Timeout: 10 * time.Millisecond, Status: fiber.StatusServiceUnavailable, Deadline: true},
+		{Name: "server shutting down", Timeout: time.Minute, Cancel: true, Status: fiber.StatusServiceUnavailable, Deadline: true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			base, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if c.Cancel {
+				cancel()
+			}
+
+			var requestCtx context.Context
+			app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler})
+			app.Use(middlewares.RequestContext(base, c.Timeout))
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				requestCtx = ctx.UserContext()
+				_, deadline := requestCtx.Deadline()
+				assert.Equal(t, c.Deadline, deadline)
+
+				// NOTE จำลองคำสั่งฐานข้อมูลที่ใช้เวลานาน
+				select {
+				case <-requestCtx.Done():
+					return apperror.Timeout(requestCtx.Err())
+				case <-time.After(50 * time.Millisecond):
+					return ctx.SendStatus(fiber.StatusOK)
+				}
+			})
+
+			res, err := app.Test(httptest.NewRequest("GET", "/", nil), -1)
+			require.NoError(t, err)
+			assert.Equal(t, c.Status, res.StatusCode)
+			// NOTE ตอบเสร็จแล้ว ctx ของ request ต้องถูกยกเลิกเสมอ
+			assert.Error(t, requestCtx.Err())
+		})
+	}
+}
+
+// app ที่ฟังบน socket จริง (app.Test ไม่มี socket ให้ตรวจการตัดการเชื่อมต่อ)
+func listenApp(t *testing.T, app *fiber.App) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+	return ln.Addr().String()
+}
+
+func Test_RequestContextClientDisconnect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("disconnect detection needs a unix socket")
+	}
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(middlewares.RequestContext(context.Background(), time.Minute))
+	app.Get("/", func(c *fiber.Ctx) error {
+		close(started)
+		select {
+		case <-c.UserContext().Done():
+			cause <- context.Cause(c.UserContext())
+		case <-time.After(5 * time.Second):
+			cause <- nil
+		}
+		return nil
+	})
+
+	conn, err := net.Dial("tcp", listenApp(t, app))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n"))
+	require.NoError(t, err)
+	<-started
+	require.NoError(t, conn.Close())
+
+	assert.ErrorIs(t, <-cause, middlewares.ErrClientDisconnected)
+}
+
+func Test_RequestContextKeepAlive(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(middlewares.RequestContext(context.Background(), time.Minute))
+	app.Get("/", func(c *fiber.Ctx) error {
+		// NOTE นานพอให้ตรวจ connection หลายรอบ
+		select {
+		case <-c.UserContext().Done():
+			return apperror.Timeout(c.UserContext().Err())
+		case <-time.After(500 * time.Millisecond):
+			return c.SendString("ok")
+		}
+	})
+
+	conn, err := net.Dial("tcp", listenApp(t, app))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// NOTE request ถัดไปที่ส่งมารอ (pipeline) ต้องไม่ถูกกินและไม่ทำให้ request แรกถูกยกเลิก
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\nGET / HTTP/1.1\r\nHost: test\r\n\r\n"))
+	require.NoError(t, err)
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		res, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode, i)
+		assert.Equal(t, "ok", string(body), i)
+	}
+}
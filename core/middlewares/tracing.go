@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// สร้าง span ของ request (ต่อจาก traceparent ที่ส่งมาถ้ามี) แล้วเก็บใน c.UserContext()
+// ให้ service / repository สร้าง span ลูกต่อได้ และเพิ่ม trace_id ใน log ของ request (ใส่ต่อจาก RequestID)
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+		ctx, span := tracing.Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("trace_id", sc.TraceID().String()))
+		}
+		c.SetUserContext(ctx)
+		handleError(c, c.Next())
+
+		// NOTE รู้ route จริงหลัง c.Next() เท่านั้น
+		status := c.Response().StatusCode()
+		span.SetName(c.Method() + " " + c.Route().Path)
+		span.SetAttributes(semconv.HTTPRoute(c.Route().Path), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return nil
+	}
+}
+
+// อ่าน/เขียน header ของ fasthttp ให้ propagator ของ otel
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	h.header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() (keys []string) {
+	h.header.VisitAll(func(key []byte, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
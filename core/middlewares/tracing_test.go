@@ -0,0 +1,99 @@
+package middlewares_test
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/tracing"
+	"7solutions/backend/core/middlewares"
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ใช้ tracer provider ที่เก็บ span ไว้ตรวจแทนของจริง
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func Test_Tracing(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	cases := []struct {
+		Name        string
+		Path        string
+		TraceParent string
+		Status      int
+		SpanName    string
+		Error       bool
+	}{
+		{Name: "new trace", Path: "/api/user/1", Status: 200, SpanName: "GET /api/user/:id"},
+		{Name: "continues traceparent", Path: "/api/user/1", TraceParent: "00-" + traceID + "-00f067aa0ba902b7-01", Status: 200, SpanName: "GET /api/user/:id"},
+		{Name: "server error", Path: "/fail", Status: 500, SpanName: "GET /fail", Error: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			recorder := recordSpans(t)
+			buf := &bytes.Buffer{}
+			app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler})
+			app.Use(middlewares.RequestID(logger.New(buf, "production", slog.LevelInfo)))
+			app.Use(middlewares.Tracing())
+			app.Get("/api/user/:id", func(c *fiber.Ctx) error {
+				_, span := tracing.Start(c.UserContext(), "child")
+				span.End()
+				logger.FromContext(c.UserContext()).Info("handler")
+				return c.SendStatus(fiber.StatusOK)
+			})
+			app.Get("/fail", func(c *fiber.Ctx) error {
+				return apperror.Internal(errors.New("db down"))
+			})
+
+			req := httptest.NewRequest("GET", c.Path, nil)
+			if c.TraceParent != "" {
+				req.Header.Set("traceparent", c.TraceParent)
+			}
+			res, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, c.Status, res.StatusCode)
+
+			spans := recorder.Ended()
+			require.NotEmpty(t, spans)
+			server := spans[len(spans)-1]
+			assert.Equal(t, c.SpanName, server.Name())
+			assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+			assert.Equal(t, c.Error, server.Status().Code.String() == "Error")
+			if c.TraceParent != "" {
+				assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+				assert.True(t, server.Parent().IsRemote())
+			}
+
+			// NOTE span ลูกใน handler และ log ของ request ผูกกับ trace เดียวกัน
+			for _, span := range spans[:len(spans)-1] {
+				assert.Equal(t, server.SpanContext().TraceID(), span.SpanContext().TraceID())
+				assert.Equal(t, server.SpanContext().SpanID(), span.Parent().SpanID())
+			}
+			for _, entry := range logEntries(t, buf) {
+				assert.Equal(t, server.SpanContext().TraceID().String(), entry["trace_id"])
+			}
+		})
+	}
+}
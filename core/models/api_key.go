@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// API key สำหรับเรียกแบบ service-to-service ใช้แทน Bearer token ผ่าน header apikey
+// NOTE เก็บเฉพาะ hash ส่วน prefix ใช้ค้นหาและแสดงให้ผู้ใช้จำได้
+type RepoCreateAPIKeyModel struct {
+	ID          string     `json:"id" bson:"id"`
+	UserID      string     `json:"userId" bson:"userId"`
+	Name        string     `json:"name" bson:"name"`
+	Prefix      string     `json:"prefix" bson:"prefix"`
+	KeyHash     string     `json:"keyHash" bson:"keyHash"`
+	Permissions []string   `json:"permissions" bson:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt    time.Time  `json:"createAt" bson:"createAt"`
+}
+
+type RepoResAPIKeyModel struct {
+	ID          string     `json:"id" bson:"id"`
+	UserID      string     `json:"userId" bson:"userId"`
+	Name        string     `json:"name" bson:"name"`
+	Prefix      string     `json:"prefix" bson:"prefix"`
+	KeyHash     string     `json:"keyHash" bson:"keyHash"`
+	Permissions []string   `json:"permissions" bson:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt    time.Time  `json:"createAt" bson:"createAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt" bson:"lastUsedAt"`
+	RevokedAt   *time.Time `json:"revokedAt" bson:"revokedAt"`
+}
+
+// permissions ต้องเป็นสิทธิ์ที่เจ้าของ key มีอยู่ ว่าง = ใช้ได้เฉพาะข้อมูลของเจ้าของเอง
+type SrvCreateAPIKeyModel struct {
+	Name        string     `json:"name" bson:"name"`
+	Permissions []string   `json:"permissions" bson:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt" bson:"expiresAt"` // ไม่ระบุ = ไม่หมดอายุ
+}
+
+type SrvResAPIKeyModel struct {
+	ID          string     `json:"id" bson:"id"`
+	UserID      string     `json:"userId" bson:"userId"`
+	Name        string     `json:"name" bson:"name"`
+	Prefix      string     `json:"prefix" bson:"prefix"`
+	Permissions []string   `json:"permissions" bson:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt    time.Time  `json:"createAt" bson:"createAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt" bson:"lastUsedAt"`
+	RevokedAt   *time.Time `json:"revokedAt" bson:"revokedAt"`
+}
+
+// key แสดงครั้งเดียวตอนสร้าง
+type SrvCreateAPIKeyResModel struct {
+	SrvResAPIKeyModel `bson:",inline"`
+	Key               string `json:"key" bson:"key"`
+}
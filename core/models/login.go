@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// จำนวนครั้งที่ sign in ไม่สำเร็จของ key (email หรือ IP) ภายในช่วงเวลาที่นับ
+type RepoResLoginAttemptModel struct {
+	Key           string     `json:"key" bson:"key"`
+	Failures      int        `json:"failures" bson:"failures"`
+	LastFailureAt time.Time  `json:"lastFailureAt" bson:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil" bson:"lockedUntil"`
+	ExpiresAt     time.Time  `json:"expiresAt" bson:"expiresAt"`
+}
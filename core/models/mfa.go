@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// การลงทะเบียน TOTP ของ user (secret เข้ารหัสไว้ recovery code เก็บเฉพาะ hash)
+type RepoCreateMFAModel struct {
+	UserID   string    `json:"userId" bson:"userId"`
+	Secret   string    `json:"secret" bson:"secret"`
+	CreateAt time.Time `json:"createAt" bson:"createAt"`
+}
+
+type RepoResMFAModel struct {
+	UserID        string     `json:"userId" bson:"userId"`
+	Secret        string     `json:"secret" bson:"secret"`
+	Enabled       bool       `json:"enabled" bson:"enabled"`
+	RecoveryCodes []string   `json:"recoveryCodes" bson:"recoveryCodes"`
+	LastStep      int64      `json:"lastStep" bson:"lastStep"` // time step ล่าสุดที่ใช้ไป (กันใช้รหัสซ้ำ)
+	CreateAt      time.Time  `json:"createAt" bson:"createAt"`
+	EnabledAt     *time.Time `json:"enabledAt" bson:"enabledAt"`
+
+	// challenge ที่รอรหัสหลัง sign in ด้วยรหัสผ่าน (เก็บเฉพาะ hash)
+	ChallengeHash      string     `json:"challengeHash" bson:"challengeHash,omitempty"`
+	ChallengeExpiresAt *time.Time `json:"challengeExpiresAt" bson:"challengeExpiresAt,omitempty"`
+}
+
+type SrvMFACodeModel struct {
+	Code string `json:"code" bson:"code"`
+}
+
+type SrvMFAEnrollResModel struct {
+	Secret string `json:"secret" bson:"secret"`
+	URI    string `json:"uri" bson:"uri"` // otpauth:// สำหรับสร้าง QR code
+}
+
+type SrvMFARecoveryCodesResModel struct {
+	RecoveryCodes []string `json:"recoveryCodes" bson:"recoveryCodes"`
+}
+
+// ตอบจาก sign in เมื่อ user เปิด MFA ต้องนำ mfaToken ไปแลกที่ /api/signin/mfa พร้อมรหัส
+type SrvMFAChallengeResModel struct {
+	MFARequired bool   `json:"mfaRequired" bson:"mfaRequired"`
+	MFAToken    string `json:"mfaToken" bson:"mfaToken"`
+	ExpiresIn   int64  `json:"expiresIn" bson:"expiresIn"`
+}
+
+// code เป็นรหัส TOTP 6 หลัก หรือ recovery code
+type SrvMFASignInModel struct {
+	MFAToken string `json:"mfaToken" bson:"mfaToken"`
+	Code     string `json:"code" bson:"code"`
+}
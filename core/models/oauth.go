@@ -0,0 +1,153 @@
+package models
+
+import "time"
+
+// grant ที่ OAuth client ใช้ได้
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+)
+
+// OAuth client ที่ลงทะเบียนไว้ เก็บเฉพาะ hash ของ secret (ว่าง = public client ต้องใช้ PKCE และใช้ client_credentials ไม่ได้)
+type RepoCreateOAuthClientModel struct {
+	ID           string    `json:"id" bson:"id"`
+	Name         string    `json:"name" bson:"name"`
+	SecretHash   string    `json:"secretHash" bson:"secretHash"`
+	RedirectURIs []string  `json:"redirectUris" bson:"redirectUris"`
+	Scopes       []string  `json:"scopes" bson:"scopes"`
+	GrantTypes   []string  `json:"grantTypes" bson:"grantTypes"`
+	CreateAt     time.Time `json:"createAt" bson:"createAt"`
+}
+
+type RepoResOAuthClientModel struct {
+	ID           string    `json:"id" bson:"id"`
+	Name         string    `json:"name" bson:"name"`
+	SecretHash   string    `json:"secretHash" bson:"secretHash"`
+	RedirectURIs []string  `json:"redirectUris" bson:"redirectUris"`
+	Scopes       []string  `json:"scopes" bson:"scopes"`
+	GrantTypes   []string  `json:"grantTypes" bson:"grantTypes"`
+	CreateAt     time.Time `json:"createAt" bson:"createAt"`
+}
+
+// authorization code เก็บเฉพาะ hash และใช้ได้ครั้งเดียว
+type RepoCreateOAuthCodeModel struct {
+	CodeHash      string    `json:"codeHash" bson:"codeHash"`
+	ClientID      string    `json:"clientId" bson:"clientId"`
+	UserID        string    `json:"userId" bson:"userId"`
+	RedirectURI   string    `json:"redirectUri" bson:"redirectUri"`
+	Scopes        []string  `json:"scopes" bson:"scopes"`
+	CodeChallenge string    `json:"codeChallenge" bson:"codeChallenge"`
+	ExpiresAt     time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt      time.Time `json:"createAt" bson:"createAt"`
+}
+
+type RepoResOAuthCodeModel struct {
+	CodeHash      string    `json:"codeHash" bson:"codeHash"`
+	ClientID      string    `json:"clientId" bson:"clientId"`
+	UserID        string    `json:"userId" bson:"userId"`
+	RedirectURI   string    `json:"redirectUri" bson:"redirectUri"`
+	Scopes        []string  `json:"scopes" bson:"scopes"`
+	CodeChallenge string    `json:"codeChallenge" bson:"codeChallenge"`
+	ExpiresAt     time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt      time.Time `json:"createAt" bson:"createAt"`
+}
+
+// confidential = ออก client secret ให้ (ไม่งั้นเป็น public client เช่น SPA หรือ mobile app)
+type SrvCreateOAuthClientModel struct {
+	Name         string   `json:"name" bson:"name"`
+	RedirectURIs []string `json:"redirectUris" bson:"redirectUris"`
+	Scopes       []string `json:"scopes" bson:"scopes"`
+	GrantTypes   []string `json:"grantTypes" bson:"grantTypes"` // ไม่ระบุ = authorization_code
+	Confidential bool     `json:"confidential" bson:"confidential"`
+}
+
+type SrvResOAuthClientModel struct {
+	ID           string    `json:"id" bson:"id"`
+	Name         string    `json:"name" bson:"name"`
+	RedirectURIs []string  `json:"redirectUris" bson:"redirectUris"`
+	Scopes       []string  `json:"scopes" bson:"scopes"`
+	GrantTypes   []string  `json:"grantTypes" bson:"grantTypes"`
+	Confidential bool      `json:"confidential" bson:"confidential"`
+	CreateAt     time.Time `json:"createAt" bson:"createAt"`
+}
+
+// secret แสดงครั้งเดียวตอนสร้าง
+type SrvCreateOAuthClientResModel struct {
+	SrvResOAuthClientModel `bson:",inline"`
+	Secret                 string `json:"secret,omitempty" bson:"secret"`
+}
+
+// query ของ /api/oauth/authorize (RFC 6749 4.1.1 + PKCE RFC 7636)
+type SrvOAuthAuthorizeModel struct {
+	ResponseType        string `json:"response_type" query:"response_type"`
+	ClientID            string `json:"client_id" query:"client_id"`
+	RedirectURI         string `json:"redirect_uri" query:"redirect_uri"`
+	Scope               string `json:"scope" query:"scope"`
+	State               string `json:"state" query:"state"`
+	CodeChallenge       string `json:"code_challenge" query:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method"`
+}
+
+// ข้อมูลสำหรับหน้าขอความยินยอม
+type SrvOAuthConsentResModel struct {
+	ClientID    string   `json:"clientId"`
+	ClientName  string   `json:"clientName"`
+	Scopes      []string `json:"scopes"`
+	RedirectURI string   `json:"redirectUri"`
+}
+
+// ผลการขอความยินยอม frontend พา browser ไปที่ RedirectURI (มี code หรือ error)
+type SrvOAuthConsentModel struct {
+	SrvOAuthAuthorizeModel
+	Approve bool `json:"approve"`
+}
+
+type SrvOAuthRedirectResModel struct {
+	RedirectURI string `json:"redirectUri"`
+}
+
+// client ยืนยันตัวด้วย HTTP Basic หรือ client_id/client_secret ใน body (handler รวมไว้ที่นี่)
+type SrvOAuthClientAuthModel struct {
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+}
+
+// body ของ /api/oauth/token (RFC 6749 4.1.3 และ 4.4.2)
+type SrvOAuthTokenModel struct {
+	SrvOAuthClientAuthModel
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	Code         string `json:"code" form:"code"`
+	RedirectURI  string `json:"redirect_uri" form:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier" form:"code_verifier"`
+	Scope        string `json:"scope" form:"scope"`
+}
+
+// response ตาม RFC 6749 5.1
+type SrvOAuthTokenResModel struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// body ของ /api/oauth/introspect (RFC 7662) และ /api/oauth/revoke (RFC 7009)
+type SrvOAuthTokenActionModel struct {
+	SrvOAuthClientAuthModel
+	Token         string `json:"token" form:"token"`
+	TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+}
+
+// response ตาม RFC 7662 2.2 token ที่ใช้ไม่ได้ตอบแค่ active: false
+type SrvOAuthIntrospectResModel struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Expires   int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	Audience  string `json:"aud,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+	Role      string `json:"role,omitempty"`
+}
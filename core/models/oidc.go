@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// state ระหว่างส่ง user ไป login ที่ OIDC provider เก็บเฉพาะ hash ของ state และใช้ได้ครั้งเดียว
+type RepoCreateOIDCStateModel struct {
+	StateHash    string    `json:"stateHash" bson:"stateHash"`
+	Provider     string    `json:"provider" bson:"provider"`
+	Nonce        string    `json:"nonce" bson:"nonce"`
+	CodeVerifier string    `json:"codeVerifier" bson:"codeVerifier"`
+	ExpiresAt    time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt     time.Time `json:"createAt" bson:"createAt"`
+}
+
+type RepoResOIDCStateModel struct {
+	StateHash    string    `json:"stateHash" bson:"stateHash"`
+	Provider     string    `json:"provider" bson:"provider"`
+	Nonce        string    `json:"nonce" bson:"nonce"`
+	CodeVerifier string    `json:"codeVerifier" bson:"codeVerifier"`
+	ExpiresAt    time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreateAt     time.Time `json:"createAt" bson:"createAt"`
+}
+
+// บัญชีที่ OIDC provider (provider + subject) ที่ผูกกับ user ของเรา
+type RepoCreateIdentityModel struct {
+	ID       string    `json:"id" bson:"id"`
+	UserID   string    `json:"userId" bson:"userId"`
+	Provider string    `json:"provider" bson:"provider"`
+	Subject  string    `json:"subject" bson:"subject"`
+	Email    string    `json:"email" bson:"email"`
+	CreateAt time.Time `json:"createAt" bson:"createAt"`
+}
+
+type RepoResIdentityModel struct {
+	ID       string    `json:"id" bson:"id"`
+	UserID   string    `json:"userId" bson:"userId"`
+	Provider string    `json:"provider" bson:"provider"`
+	Subject  string    `json:"subject" bson:"subject"`
+	Email    string    `json:"email" bson:"email"`
+	CreateAt time.Time `json:"createAt" bson:"createAt"`
+}
+
+type SrvOIDCLoginResModel struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// query ที่ provider redirect กลับมาที่ /api/oidc/:provider/callback
+type SrvOIDCCallbackModel struct {
+	Provider         string `query:"-"`
+	Code             string `query:"code"`
+	State            string `query:"state"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+	BrowserState     string `query:"-"` // handler ใส่จาก cookie ของ browser ที่เริ่ม login
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ตัวนับ request ของ key ในหน้าต่างเวลาปัจจุบันและหน้าต่างก่อนหน้า (ใช้ประมาณแบบ sliding window)
+type RepoResRateLimitModel struct {
+	Key           string    `json:"key" bson:"key"`
+	WindowStart   time.Time `json:"windowStart" bson:"windowStart"`
+	Count         int64     `json:"count" bson:"count"`
+	PreviousCount int64     `json:"previousCount" bson:"-"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import "slices"
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+const (
+	PermissionUserRead    = "user:read"    // อ่านข้อมูล user คนอื่น
+	PermissionUserList    = "user:list"    // ดูรายชื่อ user ทั้งหมด
+	PermissionUserWrite   = "user:write"   // แก้ไข user คนอื่น รวมถึงเปลี่ยน role
+	PermissionUserDelete  = "user:delete"  // ลบ user คนอื่น
+	PermissionTokenRevoke = "token:revoke" // เพิกถอน token ของ user คนอื่น
+	PermissionUserUnlock  = "user:unlock"  // ปลดล็อก user ที่ sign in ผิดเกินกำหนด
+	PermissionMFAReset    = "mfa:reset"    // ปิด MFA ของ user คนอื่น (เช่น ทำอุปกรณ์หาย)
+	PermissionAPIKeyWrite = "apikey:write" // สร้าง ดู และเพิกถอน API key ของ user คนอื่น
+	PermissionOAuthClient = "oauth:client" // ลงทะเบียนและลบ OAuth client
+
+	PermissionUserReadPrivate = "user:read-private" // เห็น field ที่เฉพาะผู้ดูแลเห็น เช่น lastLoginAt
+)
+
+// สิทธิ์ของแต่ละ role ส่วนข้อมูลของตัวเองทุก role อ่าน/แก้ไขได้เสมอ
+var RolePermissions = map[string][]string{
+	RoleAdmin: {PermissionUserRead, PermissionUserList, PermissionUserWrite, PermissionUserDelete, PermissionTokenRevoke, PermissionUserUnlock, PermissionMFAReset, PermissionAPIKeyWrite, PermissionOAuthClient, PermissionUserReadPrivate},
+	RoleUser:  {},
+}
+
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// permission ที่มีอยู่ใน role ใด role หนึ่ง
+func ValidPermission(permission string) bool {
+	for _, permissions := range RolePermissions {
+		if slices.Contains(permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func HasPermission(role string, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ผู้เรียกที่ผ่าน middleware AccessToken แล้ว
+type SrvCallerModel struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+
+	// เรียกด้วย API key ใช้ได้เฉพาะ Permissions ที่ key ได้รับ (และ role ของเจ้าของยังมีอยู่)
+	// ข้อมูลของเจ้าของ key ได้แค่อ่าน ส่วนแก้ไข ลบ หรือเพิกถอนต้องมี permission นั้นใน key ด้วย
+	APIKeyID    string   `json:"apiKeyId,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+
+	// เรียกด้วย token ของ OAuth client ใช้ได้เฉพาะ scope ใน Permissions แม้เป็นข้อมูลของตัวเอง
+	ClientID string `json:"clientId,omitempty"`
+}
+
+// เรียกแทน user ผ่าน API key หรือ OAuth client (ไม่ใช่ session ที่ user sign in เอง)
+func (c SrvCallerModel) Delegated() bool {
+	return c.APIKeyID != "" || c.ClientID != ""
+}
+
+func (c SrvCallerModel) Can(permission string) bool {
+	if c.Delegated() && !slices.Contains(c.Permissions, permission) {
+		return false
+	}
+	// NOTE token แบบ client_credentials ไม่มี user ได้สิทธิ์ตาม scope ที่ผู้ดูแลลงทะเบียน client ไว้
+	if c.ClientID != "" && c.UserID == "" {
+		return true
+	}
+	return HasPermission(c.Role, permission)
+}
+
+// เป็นเจ้าของข้อมูลเอง หรือมีสิทธิ์ permission
+func (c SrvCallerModel) CanAccess(userID string, permission string) bool {
+	if c.ClientID != "" && !slices.Contains(c.Permissions, permission) {
+		return false
+	}
+	// NOTE key ที่หลุดต้องยึดบัญชีเจ้าของไม่ได้ (เช่น เปลี่ยน email แล้วขอตั้งรหัสผ่านใหม่)
+	if c.APIKeyID != "" && permission != PermissionUserRead {
+		return c.Can(permission)
+	}
+	return c.UserID == userID || c.Can(permission)
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+type RepoCreateRefreshTokenModel struct {
+	ID        string     `json:"id" bson:"id"`
+	FamilyID  string     `json:"familyId" bson:"familyId"`
+	UserID    string     `json:"userId" bson:"userId"`
+	TokenHash string     `json:"tokenHash" bson:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreateAt  time.Time  `json:"createAt" bson:"createAt"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+	RevokedAt *time.Time `json:"revokedAt" bson:"revokedAt"`
+}
+
+type RepoResRefreshTokenModel struct {
+	ID        string     `json:"id" bson:"id"`
+	FamilyID  string     `json:"familyId" bson:"familyId"`
+	UserID    string     `json:"userId" bson:"userId"`
+	TokenHash string     `json:"tokenHash" bson:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreateAt  time.Time  `json:"createAt" bson:"createAt"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+	RevokedAt *time.Time `json:"revokedAt" bson:"revokedAt"`
+}
+
+type SrvRefreshTokenModel struct {
+	RefreshToken string `json:"refreshToken" bson:"refreshToken"`
+}
+
+// token ที่ถูกเพิกถอน ระบุด้วย jti (ทีละ token) หรือ userId (ทุก token ที่ออกก่อน revokedAt)
+type RepoRevokedTokenModel struct {
+	TokenID   string    `json:"jti,omitempty" bson:"jti,omitempty"`
+	UserID    string    `json:"userId,omitempty" bson:"userId,omitempty"`
+	RevokedAt time.Time `json:"revokedAt" bson:"revokedAt"`
+	ExpiresAt time.Time `json:"expiresAt" bson:"expiresAt"`
+}
+
+type SrvSignOutModel struct {
+	RefreshToken string `json:"refreshToken" bson:"refreshToken"`
+}
+
+// token สำหรับตั้งรหัสผ่านใหม่ เก็บเฉพาะ hash และใช้ได้ครั้งเดียว
+type RepoCreatePasswordResetModel struct {
+	ID        string     `json:"id" bson:"id"`
+	UserID    string     `json:"userId" bson:"userId"`
+	TokenHash string     `json:"tokenHash" bson:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreateAt  time.Time  `json:"createAt" bson:"createAt"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+}
+
+type RepoResPasswordResetModel struct {
+	ID        string     `json:"id" bson:"id"`
+	UserID    string     `json:"userId" bson:"userId"`
+	TokenHash string     `json:"tokenHash" bson:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreateAt  time.Time  `json:"createAt" bson:"createAt"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+}
+
+// token ยืนยัน email ผูกกับ email ที่ส่งไป (เปลี่ยน email แล้ว token เดิมใช้ไม่ได้)
+type RepoCreateEmailVerificationModel struct {
+	ID        string     `json:"id" bson:"id"`
+	UserID    string     `json:"userId" bson:"userId"`
+	Email     string     `json:"email" bson:"email"`
+	TokenHash string     `json:"tokenHash" bson:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreateAt  time.Time  `json:"createAt" bson:"createAt"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+}
+
+type RepoResEmailVerificationModel struct {
+	ID        string     `json:"id" bson:"id"`
+	UserID    string     `json:"userId" bson:"userId"`
+	Email     string     `json:"email" bson:"email"`
+	TokenHash string     `json:"tokenHash" bson:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	CreateAt  time.Time  `json:"createAt" bson:"createAt"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+}
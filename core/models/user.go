@@ -3,11 +3,14 @@ package models
 import "time"
 
 type RepoResUserModel struct {
-	ID       string    `json:"id" bson:"id"`
-	Name     string    `json:"name" bson:"name"`
-	Email    string    `json:"email" bson:"email"`
-	Password string    `json:"password" bson:"password"`
-	CreateAt time.Time `json:"createAt" bson:"createAt"`
+	ID            string     `json:"id" bson:"id"`
+	Name          string     `json:"name" bson:"name"`
+	Email         string     `json:"email" bson:"email"`
+	Password      string     `json:"password" bson:"password"`
+	Role          string     `json:"role" bson:"role"`
+	CreateAt      time.Time  `json:"createAt" bson:"createAt"`
+	LastLoginAt   *time.Time `json:"lastLoginAt" bson:"lastLoginAt,omitempty"`
+	EmailVerified bool       `json:"emailVerified" bson:"emailVerified"`
 }
 
 type RepoCreateUserModel struct {
@@ -15,6 +18,7 @@ type RepoCreateUserModel struct {
 	Name     string    `json:"name" bson:"name"`
 	Email    string    `json:"email" bson:"email"`
 	Password string    `json:"password" bson:"password"`
+	Role     string    `json:"role" bson:"role"`
 	CreateAt time.Time `json:"createAt" bson:"createAt"`
 }
 
@@ -24,19 +28,34 @@ type SrvCreateUserModel struct {
 	Password string `json:"password" bson:"password"`
 }
 
+// ข้อมูล user ที่ส่งออกทาง API ทุก endpoint (ไม่มี password)
 type SrvResUserModel struct {
-	ID       string `json:"id" bson:"id"`
-	Name     string `json:"name" bson:"name"`
-	Email    string `json:"email" bson:"email"`
-	Password string `json:"password" bson:"password"`
-	CreateAt string `json:"createAt" bson:"createAt"`
+	ID            string `json:"id" bson:"id"`
+	Name          string `json:"name" bson:"name"`
+	Email         string `json:"email" bson:"email"`
+	Role          string `json:"role" bson:"role"`
+	CreateAt      string `json:"createAt" bson:"createAt"`
+	EmailVerified bool   `json:"emailVerified" bson:"emailVerified"`
+
+	// NOTE field ด้านล่างแสดงเฉพาะผู้ที่มีสิทธิ์ PermissionUserReadPrivate
+	LastLoginAt string `json:"lastLoginAt,omitempty" bson:"lastLoginAt"`
 }
 
 type Response struct {
-	Status  bool        `json:"status"`
-	Message string      `json:"message"`
-	Code    int         `json:"code"`
-	Data    interface{} `json:"data"`
+	Status    bool               `json:"status"`
+	Message   string             `json:"message"`
+	Code      int                `json:"code"`
+	ErrorCode string             `json:"errorCode,omitempty"`
+	Data      interface{}        `json:"data"`
+	Meta      *ResponseMetaModel `json:"meta,omitempty"`
+}
+
+// ข้อมูลการแบ่งหน้าของ response ที่เป็นรายการ
+type ResponseMetaModel struct {
+	Total      int64  `json:"total"`
+	Limit      int64  `json:"limit"`
+	Offset     int64  `json:"offset,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 type RepoFilterUserModel struct {
@@ -47,19 +66,88 @@ type RepoFilterUserModel struct {
 type SrvSignInModel struct {
 	Email    string `json:"email" bson:"email"`
 	Password string `json:"password" bson:"password"`
+	IP       string `json:"-" bson:"-"` // handler ใส่จาก request ใช้นับ sign in ผิดต่อ IP
 }
 
 type SrvSignInResModel struct {
-	Type        string `json:"type" bson:"type"`
-	AccessToken string `json:"accessToken" bson:"accessToken"`
+	Type         string `json:"type" bson:"type"`
+	AccessToken  string `json:"accessToken" bson:"accessToken"`
+	RefreshToken string `json:"refreshToken" bson:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn" bson:"expiresIn"`
+}
+
+// ตอบแทน SrvSignInResModel เมื่อ sign in แบบ cookie (token อยู่ใน cookie HttpOnly)
+type SrvSessionResModel struct {
+	Type      string `json:"type" bson:"type"`
+	ExpiresIn int64  `json:"expiresIn" bson:"expiresIn"`
+	CSRFToken string `json:"csrfToken" bson:"csrfToken"` // ส่งกลับใน header X-CSRF-Token
 }
 
 type RepoUpdateUserModel struct {
-	Name  string `json:"name" bson:"name,omitempty"`
-	Email string `json:"email" bson:"email,omitempty"`
+	Name          string     `json:"name" bson:"name,omitempty"`
+	Email         string     `json:"email" bson:"email,omitempty"`
+	Role          string     `json:"role" bson:"role,omitempty"`
+	Password      string     `json:"password" bson:"password,omitempty"` // bcrypt hash
+	LastLoginAt   *time.Time `json:"lastLoginAt" bson:"lastLoginAt,omitempty"`
+	EmailVerified *bool      `json:"emailVerified" bson:"emailVerified,omitempty"` // nil = ไม่เปลี่ยน
 }
 
 type SrvUpdateUserModel struct {
 	Name  string `json:"name" bson:"name"`
 	Email string `json:"email" bson:"email"`
+	Role  string `json:"role" bson:"role"` // admin เท่านั้นที่เปลี่ยนได้
+}
+
+type SrvResendVerificationModel struct {
+	Email string `json:"email" bson:"email"`
+}
+
+type SrvChangePasswordModel struct {
+	CurrentPassword string `json:"currentPassword" bson:"currentPassword"`
+	NewPassword     string `json:"newPassword" bson:"newPassword"`
+}
+
+type SrvForgotPasswordModel struct {
+	Email string `json:"email" bson:"email"`
+}
+
+type SrvResetPasswordModel struct {
+	Token    string `json:"token" bson:"token"`
+	Password string `json:"password" bson:"password"`
+}
+
+// query string ของ GET /api/users
+type SrvUserQueryModel struct {
+	Name        string `json:"name" query:"name"`
+	Email       string `json:"email" query:"email"`
+	CreatedFrom string `json:"createdFrom" query:"createdFrom"` // RFC 3339 หรือ 2006-01-02
+	CreatedTo   string `json:"createdTo" query:"createdTo"`
+	Sort        string `json:"sort" query:"sort"` // createAt, name, email ใส่ - ข้างหน้าเพื่อเรียงจากมากไปน้อย
+	Limit       int64  `json:"limit" query:"limit"`
+	Offset      int64  `json:"offset" query:"offset"`
+	Cursor      string `json:"cursor" query:"cursor"` // nextCursor จากหน้าก่อน (ใช้แทน offset)
+}
+
+type RepoUserQueryModel struct {
+	Name        string
+	Email       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      string
+	SortDesc    bool
+	Limit       int64
+	Offset      int64
+	After       *RepoUserCursorModel
+}
+
+// ตำแหน่งของรายการสุดท้ายในหน้า: ค่าของ field ที่ใช้เรียง + id
+type RepoUserCursorModel struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+type RepoResUserPageModel struct {
+	Users []RepoResUserModel
+	Total int64
+	Next  *RepoUserCursorModel
 }
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, payload models.RepoCreateAPIKeyModel) (result models.RepoResAPIKeyModel, err error)
+
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (result models.RepoResAPIKeyModel, err error)
+
+	// key ทั้งหมดของ user (รวมที่เพิกถอนแล้ว) เรียงจากใหม่ไปเก่า
+	GetAPIKeysByUserID(ctx context.Context, userID string) (result []models.RepoResAPIKeyModel, err error)
+
+	// คืน ErrAPIKeyNotFound ถ้าไม่มี key ของ user นี้หรือเพิกถอนไปแล้ว
+	RevokeAPIKey(ctx context.Context, userID string, id string, revokedAt time.Time) error
+
+	UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
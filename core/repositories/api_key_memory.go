@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type apiKeyMemory struct {
+	mu   sync.Mutex
+	keys map[string]models.RepoResAPIKeyModel
+}
+
+func NewAPIKeyMemoryRepository() APIKeyRepository {
+	return &apiKeyMemory{
+		keys: map[string]models.RepoResAPIKeyModel{},
+	}
+}
+
+func (r *apiKeyMemory) CreateAPIKey(_ context.Context, payload models.RepoCreateAPIKeyModel) (result models.RepoResAPIKeyModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result = models.RepoResAPIKeyModel{
+		ID:          payload.ID,
+		UserID:      payload.UserID,
+		Name:        payload.Name,
+		Prefix:      payload.Prefix,
+		KeyHash:     payload.KeyHash,
+		Permissions: slices.Clone(payload.Permissions),
+		ExpiresAt:   payload.ExpiresAt,
+		CreateAt:    payload.CreateAt,
+	}
+	r.keys[result.ID] = result
+	return result, nil
+}
+
+func (r *apiKeyMemory) GetAPIKeyByPrefix(_ context.Context, prefix string) (result models.RepoResAPIKeyModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return key, nil
+		}
+	}
+	return result, ErrAPIKeyNotFound
+}
+
+func (r *apiKeyMemory) GetAPIKeysByUserID(_ context.Context, userID string) (result []models.RepoResAPIKeyModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result = []models.RepoResAPIKeyModel{}
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			result = append(result, key)
+		}
+	}
+	slices.SortFunc(result, func(a, b models.RepoResAPIKeyModel) int {
+		return b.CreateAt.Compare(a.CreateAt)
+	})
+	return result, nil
+}
+
+func (r *apiKeyMemory) RevokeAPIKey(_ context.Context, userID string, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userID || key.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &revokedAt
+	r.keys[id] = key
+	return nil
+}
+
+func (r *apiKeyMemory) UpdateAPIKeyLastUsed(_ context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &usedAt
+	r.keys[id] = key
+	return nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type apiKeyRepoMock struct {
+	mock.Mock
+}
+
+func NewAPIKeyRepositoryMock() *apiKeyRepoMock {
+	return &apiKeyRepoMock{}
+}
+
+func (m *apiKeyRepoMock) CreateAPIKey(ctx context.Context, payload models.RepoCreateAPIKeyModel) (result models.RepoResAPIKeyModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResAPIKeyModel), args.Error(1)
+}
+
+func (m *apiKeyRepoMock) GetAPIKeyByPrefix(ctx context.Context, prefix string) (result models.RepoResAPIKeyModel, err error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(models.RepoResAPIKeyModel), args.Error(1)
+}
+
+func (m *apiKeyRepoMock) GetAPIKeysByUserID(ctx context.Context, userID string) (result []models.RepoResAPIKeyModel, err error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.RepoResAPIKeyModel), args.Error(1)
+}
+
+func (m *apiKeyRepoMock) RevokeAPIKey(ctx context.Context, userID string, id string, revokedAt time.Time) error {
+	args := m.Called(ctx, userID, id, revokedAt)
+	return args.Error(0)
+}
+
+func (m *apiKeyRepoMock) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type apiKeyRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewAPIKeyRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) APIKeyRepository {
+	return &apiKeyRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *apiKeyRepo) CreateAPIKey(ctx context.Context, payload models.RepoCreateAPIKeyModel) (result models.RepoResAPIKeyModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "CreateAPIKey")
+	defer cancel()
+
+	result = models.RepoResAPIKeyModel{
+		ID:          payload.ID,
+		UserID:      payload.UserID,
+		Name:        payload.Name,
+		Prefix:      payload.Prefix,
+		KeyHash:     payload.KeyHash,
+		Permissions: payload.Permissions,
+		ExpiresAt:   payload.ExpiresAt,
+		CreateAt:    payload.CreateAt,
+	}
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, result)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeyRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (result models.RepoResAPIKeyModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "GetAPIKeyByPrefix")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"prefix": prefix})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrAPIKeyNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeyRepo) GetAPIKeysByUserID(ctx context.Context, userID string) (result []models.RepoResAPIKeyModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "GetAPIKeysByUserID")
+	defer cancel()
+
+	opt := options.Find().SetSort(bson.D{{Key: "createAt", Value: -1}})
+	cursor, err := r.db.Collection(r.collection).Find(ctx, bson.M{"userId": userID}, opt)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	result = []models.RepoResAPIKeyModel{}
+	if err := cursor.All(ctx, &result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeyRepo) RevokeAPIKey(ctx context.Context, userID string, id string, revokedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "RevokeAPIKey")
+	defer cancel()
+
+	filter := bson.M{"id": id, "userId": userID, "revokedAt": nil}
+	res, err := r.db.Collection(r.collection).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revokedAt": revokedAt}})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.MatchedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "UpdateAPIKeyLastUsed")
+	defer cancel()
+
+	res, err := r.db.Collection(r.collection).UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": bson.M{"lastUsedAt": usedAt}})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.MatchedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type apiKeySQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewAPIKeySQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) APIKeyRepository {
+	return &apiKeySQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const apiKeySQLColumns = `id, user_id, name, prefix, key_hash, permissions, expires_at, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (result models.RepoResAPIKeyModel, err error) {
+	var permissions string
+	err = row.Scan(&result.ID, &result.UserID, &result.Name, &result.Prefix, &result.KeyHash, &permissions,
+		sqlNullTime{dst: &result.ExpiresAt}, sqlTime{dst: &result.CreateAt}, sqlNullTime{dst: &result.LastUsedAt}, sqlNullTime{dst: &result.RevokedAt})
+	result.Permissions = splitSQLList(permissions)
+	return result, err
+}
+
+func (r *apiKeySQLRepo) CreateAPIKey(ctx context.Context, payload models.RepoCreateAPIKeyModel) (result models.RepoResAPIKeyModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "CreateAPIKey")
+	defer cancel()
+
+	query := `INSERT INTO api_keys (id, user_id, name, prefix, key_hash, permissions, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.UserID, payload.Name, payload.Prefix, payload.KeyHash,
+		strings.Join(payload.Permissions, ","), r.dialect.nullTimeValue(payload.ExpiresAt), r.dialect.timeValue(payload.CreateAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	result = models.RepoResAPIKeyModel{
+		ID:          payload.ID,
+		UserID:      payload.UserID,
+		Name:        payload.Name,
+		Prefix:      payload.Prefix,
+		KeyHash:     payload.KeyHash,
+		Permissions: payload.Permissions,
+		ExpiresAt:   payload.ExpiresAt,
+		CreateAt:    payload.CreateAt,
+	}
+	return result, nil
+}
+
+func (r *apiKeySQLRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (result models.RepoResAPIKeyModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "GetAPIKeyByPrefix")
+	defer cancel()
+
+	query := `SELECT ` + apiKeySQLColumns + ` FROM api_keys WHERE prefix = ?`
+	result, err = scanAPIKey(r.db.QueryRowContext(ctx, r.dialect.rebind(query), prefix))
+	if err != nil {
+		return result, sqlError(err, ErrAPIKeyNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeySQLRepo) GetAPIKeysByUserID(ctx context.Context, userID string) (result []models.RepoResAPIKeyModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", "GetAPIKeysByUserID")
+	defer cancel()
+
+	query := `SELECT ` + apiKeySQLColumns + ` FROM api_keys WHERE user_id = ? ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), userID)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	defer rows.Close()
+
+	result = []models.RepoResAPIKeyModel{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return result, sqlError(err, nil)
+		}
+		result = append(result, key)
+	}
+	if err := rows.Err(); err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeySQLRepo) RevokeAPIKey(ctx context.Context, userID string, id string, revokedAt time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	return r.update(ctx, "RevokeAPIKey", query, r.dialect.timeValue(revokedAt), id, userID)
+}
+
+func (r *apiKeySQLRepo) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	return r.update(ctx, "UpdateAPIKeyLastUsed", `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, r.dialect.timeValue(usedAt), id)
+}
+
+func (r *apiKeySQLRepo) update(ctx context.Context, method string, query string, args ...any) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "api_key", method)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	if updated == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+type EmailVerificationRepository interface {
+	CreateEmailVerification(ctx context.Context, payload models.RepoCreateEmailVerificationModel) (result models.RepoResEmailVerificationModel, err error)
+
+	GetEmailVerificationByHash(ctx context.Context, tokenHash string) (result models.RepoResEmailVerificationModel, err error)
+
+	// ทำเครื่องหมายว่าใช้แล้วแบบ atomic คืน ErrEmailVerificationUsed ถ้ามีคนใช้ไปก่อน
+	UseEmailVerification(ctx context.Context, id string, usedAt time.Time) (result models.RepoResEmailVerificationModel, err error)
+
+	// ทำให้ token ที่ยังไม่ถูกใช้ของ user ใช้ไม่ได้ทั้งหมด
+	UseUserEmailVerifications(ctx context.Context, userID string, usedAt time.Time) error
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type emailVerificationMemory struct {
+	mu     sync.Mutex
+	tokens map[string]models.RepoResEmailVerificationModel
+}
+
+func NewEmailVerificationMemoryRepository() EmailVerificationRepository {
+	return &emailVerificationMemory{
+		tokens: map[string]models.RepoResEmailVerificationModel{},
+	}
+}
+
+func (r *emailVerificationMemory) CreateEmailVerification(_ context.Context, payload models.RepoCreateEmailVerificationModel) (result models.RepoResEmailVerificationModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบ token ที่หมดอายุแทน TTL index
+	now := time.Now()
+	for id, token := range r.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(r.tokens, id)
+		}
+	}
+
+	result = models.RepoResEmailVerificationModel(payload)
+	r.tokens[result.ID] = result
+	return result, nil
+}
+
+func (r *emailVerificationMemory) GetEmailVerificationByHash(_ context.Context, tokenHash string) (result models.RepoResEmailVerificationModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return result, ErrEmailVerificationNotFound
+}
+
+func (r *emailVerificationMemory) UseEmailVerification(_ context.Context, id string, usedAt time.Time) (result models.RepoResEmailVerificationModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.tokens[id]
+	if !ok || result.UsedAt != nil {
+		return result, ErrEmailVerificationUsed
+	}
+	result.UsedAt = &usedAt
+	r.tokens[id] = result
+	return result, nil
+}
+
+func (r *emailVerificationMemory) UseUserEmailVerifications(_ context.Context, userID string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
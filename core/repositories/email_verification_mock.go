@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type emailVerificationRepoMock struct {
+	mock.Mock
+}
+
+func NewEmailVerificationRepositoryMock() *emailVerificationRepoMock {
+	return &emailVerificationRepoMock{}
+}
+
+func (m *emailVerificationRepoMock) CreateEmailVerification(ctx context.Context, payload models.RepoCreateEmailVerificationModel) (result models.RepoResEmailVerificationModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResEmailVerificationModel), args.Error(1)
+}
+
+func (m *emailVerificationRepoMock) GetEmailVerificationByHash(ctx context.Context, tokenHash string) (result models.RepoResEmailVerificationModel, err error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(models.RepoResEmailVerificationModel), args.Error(1)
+}
+
+func (m *emailVerificationRepoMock) UseEmailVerification(ctx context.Context, id string, usedAt time.Time) (result models.RepoResEmailVerificationModel, err error) {
+	args := m.Called(ctx, id, usedAt)
+	return args.Get(0).(models.RepoResEmailVerificationModel), args.Error(1)
+}
+
+func (m *emailVerificationRepoMock) UseUserEmailVerifications(ctx context.Context, userID string, usedAt time.Time) error {
+	args := m.Called(ctx, userID, usedAt)
+	return args.Error(0)
+}
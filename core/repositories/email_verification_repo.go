@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type emailVerificationRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewEmailVerificationRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) EmailVerificationRepository {
+	return &emailVerificationRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *emailVerificationRepo) CreateEmailVerification(ctx context.Context, payload models.RepoCreateEmailVerificationModel) (result models.RepoResEmailVerificationModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "CreateEmailVerification")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResEmailVerificationModel(payload), nil
+}
+
+func (r *emailVerificationRepo) GetEmailVerificationByHash(ctx context.Context, tokenHash string) (result models.RepoResEmailVerificationModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "GetEmailVerificationByHash")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"tokenHash": tokenHash})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrEmailVerificationNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *emailVerificationRepo) UseEmailVerification(ctx context.Context, id string, usedAt time.Time) (result models.RepoResEmailVerificationModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "UseEmailVerification")
+	defer cancel()
+
+	after := options.After
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	filter := bson.M{"id": id, "usedAt": nil}
+	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"usedAt": usedAt}}, &opt)
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrEmailVerificationUsed)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *emailVerificationRepo) UseUserEmailVerifications(ctx context.Context, userID string, usedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "UseUserEmailVerifications")
+	defer cancel()
+
+	filter := bson.M{"userId": userID, "usedAt": nil}
+	_, err := r.db.Collection(r.collection).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"usedAt": usedAt}})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
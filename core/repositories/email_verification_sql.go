@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+type emailVerificationSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewEmailVerificationSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) EmailVerificationRepository {
+	return &emailVerificationSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const emailVerificationSQLColumns = `id, user_id, email, token_hash, expires_at, created_at, used_at`
+
+func scanEmailVerification(row *sql.Row) (result models.RepoResEmailVerificationModel, err error) {
+	err = row.Scan(&result.ID, &result.UserID, &result.Email, &result.TokenHash,
+		sqlTime{dst: &result.ExpiresAt}, sqlTime{dst: &result.CreateAt}, sqlNullTime{dst: &result.UsedAt})
+	return result, err
+}
+
+func (r *emailVerificationSQLRepo) CreateEmailVerification(ctx context.Context, payload models.RepoCreateEmailVerificationModel) (result models.RepoResEmailVerificationModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "CreateEmailVerification")
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบ token ที่หมดอายุตอนสร้างใหม่
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM email_verifications WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query := `INSERT INTO email_verifications (` + emailVerificationSQLColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.UserID, payload.Email, payload.TokenHash,
+		r.dialect.timeValue(payload.ExpiresAt), r.dialect.timeValue(payload.CreateAt), r.dialect.nullTimeValue(payload.UsedAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResEmailVerificationModel(payload), nil
+}
+
+func (r *emailVerificationSQLRepo) GetEmailVerificationByHash(ctx context.Context, tokenHash string) (result models.RepoResEmailVerificationModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "GetEmailVerificationByHash")
+	defer cancel()
+
+	query := `SELECT ` + emailVerificationSQLColumns + ` FROM email_verifications WHERE token_hash = ?`
+	result, err = scanEmailVerification(r.db.QueryRowContext(ctx, r.dialect.rebind(query), tokenHash))
+	if err != nil {
+		return result, sqlError(err, ErrEmailVerificationNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *emailVerificationSQLRepo) UseEmailVerification(ctx context.Context, id string, usedAt time.Time) (result models.RepoResEmailVerificationModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "UseEmailVerification")
+	defer cancel()
+
+	query := `UPDATE email_verifications SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(usedAt), id)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	if updated == 0 {
+		return result, ErrEmailVerificationUsed
+	}
+
+	query = `SELECT ` + emailVerificationSQLColumns + ` FROM email_verifications WHERE id = ?`
+	result, err = scanEmailVerification(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err != nil {
+		return result, sqlError(err, ErrEmailVerificationNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *emailVerificationSQLRepo) UseUserEmailVerifications(ctx context.Context, userID string, usedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "email_verification", "UseUserEmailVerifications")
+	defer cancel()
+
+	query := `UPDATE email_verifications SET used_at = ? WHERE user_id = ? AND used_at IS NULL`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(usedAt), userID)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	return nil
+}
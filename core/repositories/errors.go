@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"7solutions/backend/common/apperror"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrUserNotFound         = apperror.NotFound("USER_NOT_FOUND", "user not found")
+	ErrUserEmailExists      = apperror.Conflict("EMAIL_ALREADY_EXISTS", "email already exists")
+	ErrRefreshTokenNotFound = apperror.NotFound("REFRESH_TOKEN_NOT_FOUND", "refresh token not found")
+
+	// refresh token ถูกใช้หรือถูกเพิกถอนไปแล้ว
+	ErrRefreshTokenUsed = apperror.Conflict("REFRESH_TOKEN_USED", "refresh token already used")
+
+	ErrPasswordResetNotFound = apperror.NotFound("RESET_TOKEN_NOT_FOUND", "reset token not found")
+	ErrPasswordResetUsed     = apperror.Conflict("RESET_TOKEN_USED", "reset token already used")
+
+	ErrEmailVerificationNotFound = apperror.NotFound("VERIFY_TOKEN_NOT_FOUND", "verification token not found")
+	ErrEmailVerificationUsed     = apperror.Conflict("VERIFY_TOKEN_USED", "verification token already used")
+
+	ErrLoginAttemptNotFound = apperror.NotFound("LOGIN_ATTEMPT_NOT_FOUND", "login attempt not found")
+
+	ErrMFANotFound             = apperror.NotFound("MFA_NOT_FOUND", "mfa not enrolled")
+	ErrMFAAlreadyEnabled       = apperror.Conflict("MFA_ALREADY_ENABLED", "mfa already enabled")
+	ErrMFAChallengeNotFound    = apperror.NotFound("MFA_CHALLENGE_NOT_FOUND", "mfa challenge not found")
+	ErrMFACodeUsed             = apperror.Conflict("MFA_CODE_USED", "mfa code already used")
+	ErrMFARecoveryCodeNotFound = apperror.NotFound("MFA_RECOVERY_CODE_NOT_FOUND", "recovery code not found")
+
+	ErrAPIKeyNotFound = apperror.NotFound("API_KEY_NOT_FOUND", "api key not found")
+
+	ErrOIDCStateNotFound = apperror.NotFound("OIDC_STATE_NOT_FOUND", "oidc state not found")
+	ErrIdentityNotFound  = apperror.NotFound("IDENTITY_NOT_FOUND", "identity not found")
+	ErrIdentityExists    = apperror.Conflict("IDENTITY_ALREADY_EXISTS", "identity already linked")
+
+	ErrOAuthClientNotFound = apperror.NotFound("OAUTH_CLIENT_NOT_FOUND", "oauth client not found")
+	ErrOAuthCodeNotFound   = apperror.NotFound("OAUTH_CODE_NOT_FOUND", "authorization code not found")
+)
+
+// แปลง error ของ mongo เป็น domain error (ไม่พบข้อมูล -> notFound, หมดเวลา/ถูกยกเลิก -> timeout, อื่นๆ -> internal)
+func mongoError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	if notFound != nil && errors.Is(err, mongo.ErrNoDocuments) {
+		return notFound
+	}
+	if mongo.IsTimeout(err) || isContextError(err) {
+		return apperror.Timeout(err)
+	}
+	return apperror.Internal(err)
+}
+
+// ctx ของ request หมดเวลา (timeout ต่อ operation / REQUEST_TIMEOUT) หรือถูกยกเลิกตอนปิด server
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import "context"
+
+import "7solutions/backend/core/models"
+
+type IdentityRepository interface {
+	// คืน ErrIdentityExists ถ้าบัญชีนี้ของ provider ผูกกับ user อื่นไปแล้ว
+	CreateIdentity(ctx context.Context, payload models.RepoCreateIdentityModel) (result models.RepoResIdentityModel, err error)
+
+	GetIdentity(ctx context.Context, provider string, subject string) (result models.RepoResIdentityModel, err error)
+
+	DeleteIdentity(ctx context.Context, id string) error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type identityMemory struct {
+	mu         sync.Mutex
+	identities map[string]models.RepoResIdentityModel
+}
+
+func NewIdentityMemoryRepository() IdentityRepository {
+	return &identityMemory{
+		identities: map[string]models.RepoResIdentityModel{},
+	}
+}
+
+func (r *identityMemory) CreateIdentity(_ context.Context, payload models.RepoCreateIdentityModel) (result models.RepoResIdentityModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider == payload.Provider && identity.Subject == payload.Subject {
+			return result, ErrIdentityExists
+		}
+	}
+	result = models.RepoResIdentityModel(payload)
+	r.identities[result.ID] = result
+	return result, nil
+}
+
+func (r *identityMemory) GetIdentity(_ context.Context, provider string, subject string) (result models.RepoResIdentityModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return result, ErrIdentityNotFound
+}
+
+func (r *identityMemory) DeleteIdentity(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.identities[id]; !ok {
+		return ErrIdentityNotFound
+	}
+	delete(r.identities, id)
+	return nil
+}
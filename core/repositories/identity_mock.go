@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type identityRepoMock struct {
+	mock.Mock
+}
+
+func NewIdentityRepositoryMock() *identityRepoMock {
+	return &identityRepoMock{}
+}
+
+func (m *identityRepoMock) CreateIdentity(ctx context.Context, payload models.RepoCreateIdentityModel) (result models.RepoResIdentityModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResIdentityModel), args.Error(1)
+}
+
+func (m *identityRepoMock) GetIdentity(ctx context.Context, provider string, subject string) (result models.RepoResIdentityModel, err error) {
+	args := m.Called(ctx, provider, subject)
+	return args.Get(0).(models.RepoResIdentityModel), args.Error(1)
+}
+
+func (m *identityRepoMock) DeleteIdentity(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
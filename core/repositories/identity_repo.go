@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type identityRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewIdentityRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) IdentityRepository {
+	return &identityRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *identityRepo) CreateIdentity(ctx context.Context, payload models.RepoCreateIdentityModel) (result models.RepoResIdentityModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "identity", "CreateIdentity")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	// NOTE provider + subject ซ้ำชน unique index -> conflict
+	if mongo.IsDuplicateKeyError(err) {
+		return result, ErrIdentityExists
+	}
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResIdentityModel(payload), nil
+}
+
+func (r *identityRepo) GetIdentity(ctx context.Context, provider string, subject string) (result models.RepoResIdentityModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "identity", "GetIdentity")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"provider": provider, "subject": subject})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrIdentityNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *identityRepo) DeleteIdentity(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "identity", "DeleteIdentity")
+	defer cancel()
+
+	res, err := r.db.Collection(r.collection).DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.DeletedCount == 0 {
+		return ErrIdentityNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+type identitySQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewIdentitySQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) IdentityRepository {
+	return &identitySQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const identitySQLColumns = `id, user_id, provider, subject, email, created_at`
+
+func (r *identitySQLRepo) CreateIdentity(ctx context.Context, payload models.RepoCreateIdentityModel) (result models.RepoResIdentityModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "identity", "CreateIdentity")
+	defer cancel()
+
+	query := `INSERT INTO user_identities (` + identitySQLColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.UserID, payload.Provider, payload.Subject,
+		payload.Email, r.dialect.timeValue(payload.CreateAt))
+	// NOTE provider + subject ซ้ำชน unique index -> conflict
+	if r.dialect.isUniqueViolation(err) {
+		return result, ErrIdentityExists
+	}
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResIdentityModel(payload), nil
+}
+
+func (r *identitySQLRepo) GetIdentity(ctx context.Context, provider string, subject string) (result models.RepoResIdentityModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "identity", "GetIdentity")
+	defer cancel()
+
+	query := `SELECT ` + identitySQLColumns + ` FROM user_identities WHERE provider = ? AND subject = ?`
+	err = r.db.QueryRowContext(ctx, r.dialect.rebind(query), provider, subject).Scan(&result.ID, &result.UserID,
+		&result.Provider, &result.Subject, &result.Email, sqlTime{dst: &result.CreateAt})
+	if err != nil {
+		return result, sqlError(err, ErrIdentityNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *identitySQLRepo) DeleteIdentity(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "identity", "DeleteIdentity")
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM user_identities WHERE id = ?`), id)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	if deleted == 0 {
+		return ErrIdentityNotFound
+	}
+
+	return nil
+}
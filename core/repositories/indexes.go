@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NOTE เทียบ email แบบไม่สนตัวพิมพ์ ใช้ทั้งตอนสร้าง index และตอนค้นหา
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// index ของแต่ละ collection สร้างตอน start ผ่าน config.NewAppIndexes
+var (
+	UserIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true).SetCollation(emailCollation)},
+	}
+
+	// TTL index ลบ refresh token ที่หมดอายุ + index สำหรับค้นหา
+	RefreshTokenIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "familyId", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	}
+
+	// TTL index ลบ reset token ที่หมดอายุ + index สำหรับค้นหา
+	PasswordResetIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	}
+
+	// TTL index ลบ token ยืนยัน email ที่หมดอายุ + index สำหรับค้นหา
+	EmailVerificationIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	}
+
+	// TTL index ลบการนับ sign in ผิดที่หมดอายุ + key ไม่ซ้ำ (upsert พร้อมกันได้)
+	LoginAttemptIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+
+	// prefix ใช้ค้นหา key ที่ส่งมา
+	APIKeyIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "prefix", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createAt", Value: -1}}},
+	}
+
+	// TTL index ลบ state ของ OIDC login ที่หมดอายุ + index สำหรับค้นหา
+	OIDCStateIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "stateHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+
+	// บัญชีที่ provider ผูกได้กับ user เดียว
+	IdentityIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	}
+
+	// client_id ไม่ซ้ำ + เรียงตามเวลาที่ลงทะเบียน
+	OAuthClientIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "createAt", Value: -1}}},
+	}
+
+	// TTL index ลบ authorization code ที่หมดอายุ + index สำหรับค้นหา
+	OAuthCodeIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "codeHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+
+	// 1 document ต่อ user + index สำหรับค้นหา challenge
+	MFAIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "challengeHash", Value: 1}}, Options: options.Index().SetSparse(true)},
+	}
+
+	// TTL index ลบตัวนับที่พ้นหน้าต่างแล้ว + 1 document ต่อ key ต่อหน้าต่าง
+	RateLimitIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "key", Value: 1}, {Key: "windowStart", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+
+	// TTL index ให้ Mongo ลบรายการที่ token หมดอายุไปแล้วเอง
+	RevokedTokenIndexes = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "jti", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "revokedAt", Value: -1}}, Options: options.Index().SetSparse(true)},
+	}
+)
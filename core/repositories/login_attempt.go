@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+// นับ sign in ที่ไม่สำเร็จต่อ key เก็บในฐานข้อมูลเพื่อให้ทุก instance เห็นสถานะเดียวกัน
+type LoginAttemptRepository interface {
+	// คืน ErrLoginAttemptNotFound ถ้าไม่มีหรือหมดอายุแล้ว
+	GetLoginAttempt(ctx context.Context, key string) (result models.RepoResLoginAttemptModel, err error)
+
+	// เพิ่มจำนวนครั้งแบบ atomic (เริ่มนับ 1 ใหม่ถ้าครั้งล่าสุดเก่ากว่า windowStart) เก็บไว้อย่างน้อยถึง expiresAt
+	AddLoginFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time, expiresAt time.Time) (result models.RepoResLoginAttemptModel, err error)
+
+	// ล็อก key จนถึง lockedUntil
+	LockLoginAttempt(ctx context.Context, key string, lockedUntil time.Time) error
+
+	// ล้างการนับและปลดล็อก
+	ResetLoginAttempt(ctx context.Context, key string) error
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type loginAttemptMemory struct {
+	mu       sync.Mutex
+	attempts map[string]models.RepoResLoginAttemptModel
+}
+
+func NewLoginAttemptMemoryRepository() LoginAttemptRepository {
+	return &loginAttemptMemory{
+		attempts: map[string]models.RepoResLoginAttemptModel{},
+	}
+}
+
+func (r *loginAttemptMemory) GetLoginAttempt(_ context.Context, key string) (result models.RepoResLoginAttemptModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.attempts[key]
+	if !ok || time.Now().After(result.ExpiresAt) {
+		return models.RepoResLoginAttemptModel{}, ErrLoginAttemptNotFound
+	}
+	return result, nil
+}
+
+func (r *loginAttemptMemory) AddLoginFailure(_ context.Context, key string, failedAt time.Time, windowStart time.Time, expiresAt time.Time) (result models.RepoResLoginAttemptModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบรายการที่หมดอายุแทน TTL index
+	now := time.Now()
+	for k, attempt := range r.attempts {
+		if now.After(attempt.ExpiresAt) {
+			delete(r.attempts, k)
+		}
+	}
+
+	result, ok := r.attempts[key]
+	if !ok {
+		result = models.RepoResLoginAttemptModel{Key: key}
+	}
+	if ok && result.LastFailureAt.After(windowStart) {
+		result.Failures++
+	} else {
+		result.Failures = 1
+	}
+	result.LastFailureAt = failedAt
+	if expiresAt.After(result.ExpiresAt) {
+		result.ExpiresAt = expiresAt
+	}
+	r.attempts[key] = result
+	return result, nil
+}
+
+func (r *loginAttemptMemory) LockLoginAttempt(_ context.Context, key string, lockedUntil time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.attempts[key]
+	if !ok {
+		return ErrLoginAttemptNotFound
+	}
+	result.LockedUntil = &lockedUntil
+	if lockedUntil.After(result.ExpiresAt) {
+		result.ExpiresAt = lockedUntil
+	}
+	r.attempts[key] = result
+	return nil
+}
+
+func (r *loginAttemptMemory) ResetLoginAttempt(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
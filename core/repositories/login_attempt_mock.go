@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type loginAttemptRepoMock struct {
+	mock.Mock
+}
+
+func NewLoginAttemptRepositoryMock() *loginAttemptRepoMock {
+	return &loginAttemptRepoMock{}
+}
+
+func (m *loginAttemptRepoMock) GetLoginAttempt(ctx context.Context, key string) (result models.RepoResLoginAttemptModel, err error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(models.RepoResLoginAttemptModel), args.Error(1)
+}
+
+func (m *loginAttemptRepoMock) AddLoginFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time, expiresAt time.Time) (result models.RepoResLoginAttemptModel, err error) {
+	args := m.Called(ctx, key, failedAt, windowStart, expiresAt)
+	return args.Get(0).(models.RepoResLoginAttemptModel), args.Error(1)
+}
+
+func (m *loginAttemptRepoMock) LockLoginAttempt(ctx context.Context, key string, lockedUntil time.Time) error {
+	args := m.Called(ctx, key, lockedUntil)
+	return args.Error(0)
+}
+
+func (m *loginAttemptRepoMock) ResetLoginAttempt(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type loginAttemptRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewLoginAttemptRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) LoginAttemptRepository {
+	return &loginAttemptRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *loginAttemptRepo) GetLoginAttempt(ctx context.Context, key string) (result models.RepoResLoginAttemptModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "GetLoginAttempt")
+	defer cancel()
+
+	// NOTE TTL index ลบไม่ทันที จึงกรองรายการที่หมดอายุเอง
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"key": key, "expiresAt": bson.M{"$gt": time.Now()}})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrLoginAttemptNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptRepo) AddLoginFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time, expiresAt time.Time) (result models.RepoResLoginAttemptModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "AddLoginFailure")
+	defer cancel()
+
+	after := options.After
+	upsert := true
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+		Upsert:         &upsert,
+	}
+
+	// NOTE ใช้ update pipeline เพื่อเพิ่ม/เริ่มนับใหม่ใน operation เดียว (หลาย instance เรียกพร้อมกันได้)
+	update := bson.A{bson.M{"$set": bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$and": bson.A{
+				bson.M{"$gt": bson.A{"$lastFailureAt", windowStart}},
+				bson.M{"$gt": bson.A{"$expiresAt", failedAt}},
+			}},
+			bson.M{"$add": bson.A{"$failures", 1}},
+			1,
+		}},
+		"lastFailureAt": failedAt,
+		"expiresAt":     bson.M{"$max": bson.A{"$expiresAt", expiresAt}},
+	}}}
+	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, bson.M{"key": key}, update, &opt)
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), nil)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptRepo) LockLoginAttempt(ctx context.Context, key string, lockedUntil time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "LockLoginAttempt")
+	defer cancel()
+
+	update := bson.A{bson.M{"$set": bson.M{
+		"lockedUntil": lockedUntil,
+		"expiresAt":   bson.M{"$max": bson.A{"$expiresAt", lockedUntil}},
+	}}}
+	res, err := r.db.Collection(r.collection).UpdateOne(ctx, bson.M{"key": key}, update)
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.MatchedCount == 0 {
+		return ErrLoginAttemptNotFound
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepo) ResetLoginAttempt(ctx context.Context, key string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "ResetLoginAttempt")
+	defer cancel()
+
+	_, err := r.db.Collection(r.collection).DeleteOne(ctx, bson.M{"key": key})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+type loginAttemptSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewLoginAttemptSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) LoginAttemptRepository {
+	return &loginAttemptSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const loginAttemptSQLColumns = `attempt_key, failures, last_failure_at, locked_until, expires_at`
+
+func scanLoginAttempt(row *sql.Row) (result models.RepoResLoginAttemptModel, err error) {
+	err = row.Scan(&result.Key, &result.Failures,
+		sqlTime{dst: &result.LastFailureAt}, sqlNullTime{dst: &result.LockedUntil}, sqlTime{dst: &result.ExpiresAt})
+	return result, err
+}
+
+func (r *loginAttemptSQLRepo) GetLoginAttempt(ctx context.Context, key string) (result models.RepoResLoginAttemptModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "GetLoginAttempt")
+	defer cancel()
+
+	query := `SELECT ` + loginAttemptSQLColumns + ` FROM login_attempts WHERE attempt_key = ? AND expires_at > ?`
+	result, err = scanLoginAttempt(r.db.QueryRowContext(ctx, r.dialect.rebind(query), key, r.dialect.timeValue(time.Now())))
+	if err != nil {
+		return result, sqlError(err, ErrLoginAttemptNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptSQLRepo) AddLoginFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time, expiresAt time.Time) (result models.RepoResLoginAttemptModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "AddLoginFailure")
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบรายการที่หมดอายุตอนเพิ่มใหม่
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM login_attempts WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	// NOTE upsert ใน statement เดียว หลาย instance เรียกพร้อมกันได้
+	query := `INSERT INTO login_attempts (attempt_key, failures, last_failure_at, expires_at) VALUES (?, 1, ?, ?)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at > ? THEN login_attempts.failures + 1 ELSE 1 END,
+			last_failure_at = excluded.last_failure_at,
+			expires_at = CASE WHEN login_attempts.expires_at > excluded.expires_at THEN login_attempts.expires_at ELSE excluded.expires_at END`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), key,
+		r.dialect.timeValue(failedAt), r.dialect.timeValue(expiresAt), r.dialect.timeValue(windowStart))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query = `SELECT ` + loginAttemptSQLColumns + ` FROM login_attempts WHERE attempt_key = ?`
+	result, err = scanLoginAttempt(r.db.QueryRowContext(ctx, r.dialect.rebind(query), key))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *loginAttemptSQLRepo) LockLoginAttempt(ctx context.Context, key string, lockedUntil time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "LockLoginAttempt")
+	defer cancel()
+
+	query := `UPDATE login_attempts SET locked_until = ?,
+		expires_at = CASE WHEN expires_at > ? THEN expires_at ELSE ? END
+		WHERE attempt_key = ?`
+	until := r.dialect.timeValue(lockedUntil)
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query), until, until, until, key)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	if updated == 0 {
+		return ErrLoginAttemptNotFound
+	}
+
+	return nil
+}
+
+func (r *loginAttemptSQLRepo) ResetLoginAttempt(ctx context.Context, key string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "login_attempt", "ResetLoginAttempt")
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM login_attempts WHERE attempt_key = ?`), key)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	return nil
+}
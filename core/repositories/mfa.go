@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+type MFARepository interface {
+	// ลงทะเบียนใหม่ (แทนที่ของเดิมที่ยังไม่ยืนยัน) คืน ErrMFAAlreadyEnabled ถ้าเปิดใช้อยู่แล้ว
+	CreateMFA(ctx context.Context, payload models.RepoCreateMFAModel) (result models.RepoResMFAModel, err error)
+
+	GetMFAByUserID(ctx context.Context, userID string) (result models.RepoResMFAModel, err error)
+
+	GetMFAByChallengeHash(ctx context.Context, challengeHash string) (result models.RepoResMFAModel, err error)
+
+	// ยืนยันการลงทะเบียนพร้อม hash ของ recovery code คืน ErrMFAAlreadyEnabled ถ้าเปิดใช้อยู่แล้ว
+	EnableMFA(ctx context.Context, userID string, recoveryCodes []string, enabledAt time.Time) (result models.RepoResMFAModel, err error)
+
+	// ตั้ง challenge ใหม่ (แทนที่ของเดิม)
+	SetMFAChallenge(ctx context.Context, userID string, challengeHash string, expiresAt time.Time) error
+
+	// ใช้ challenge ได้ครั้งเดียวแบบ atomic คืน ErrMFAChallengeNotFound ถ้าถูกใช้ไปแล้ว
+	UseMFAChallenge(ctx context.Context, userID string, challengeHash string) error
+
+	// บันทึก time step ที่ใช้ คืน ErrMFACodeUsed ถ้าไม่ใหม่กว่าครั้งล่าสุด
+	UseMFAStep(ctx context.Context, userID string, step int64) error
+
+	// ใช้ recovery code ได้ครั้งเดียวแบบ atomic คืน ErrMFARecoveryCodeNotFound ถ้าไม่มี
+	UseMFARecoveryCode(ctx context.Context, userID string, codeHash string) error
+
+	DeleteMFA(ctx context.Context, userID string) error
+}
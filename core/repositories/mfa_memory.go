@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type mfaMemory struct {
+	mu  sync.Mutex
+	mfa map[string]models.RepoResMFAModel
+}
+
+func NewMFAMemoryRepository() MFARepository {
+	return &mfaMemory{
+		mfa: map[string]models.RepoResMFAModel{},
+	}
+}
+
+func (r *mfaMemory) CreateMFA(_ context.Context, payload models.RepoCreateMFAModel) (result models.RepoResMFAModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mfa[payload.UserID].Enabled {
+		return result, ErrMFAAlreadyEnabled
+	}
+	result = models.RepoResMFAModel{
+		UserID:   payload.UserID,
+		Secret:   payload.Secret,
+		CreateAt: payload.CreateAt,
+	}
+	r.mfa[payload.UserID] = result
+	return result, nil
+}
+
+func (r *mfaMemory) GetMFAByUserID(_ context.Context, userID string) (result models.RepoResMFAModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.mfa[userID]
+	if !ok {
+		return result, ErrMFANotFound
+	}
+	return result, nil
+}
+
+func (r *mfaMemory) GetMFAByChallengeHash(_ context.Context, challengeHash string) (result models.RepoResMFAModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, mfa := range r.mfa {
+		if challengeHash != "" && mfa.ChallengeHash == challengeHash {
+			return mfa, nil
+		}
+	}
+	return result, ErrMFAChallengeNotFound
+}
+
+func (r *mfaMemory) EnableMFA(_ context.Context, userID string, recoveryCodes []string, enabledAt time.Time) (result models.RepoResMFAModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.mfa[userID]
+	if !ok {
+		return result, ErrMFANotFound
+	}
+	if result.Enabled {
+		return result, ErrMFAAlreadyEnabled
+	}
+	result.Enabled = true
+	result.RecoveryCodes = recoveryCodes
+	result.EnabledAt = &enabledAt
+	r.mfa[userID] = result
+	return result, nil
+}
+
+func (r *mfaMemory) SetMFAChallenge(_ context.Context, userID string, challengeHash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.mfa[userID]
+	if !ok {
+		return ErrMFANotFound
+	}
+	result.ChallengeHash = challengeHash
+	result.ChallengeExpiresAt = &expiresAt
+	r.mfa[userID] = result
+	return nil
+}
+
+func (r *mfaMemory) UseMFAChallenge(_ context.Context, userID string, challengeHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.mfa[userID]
+	if !ok || challengeHash == "" || result.ChallengeHash != challengeHash {
+		return ErrMFAChallengeNotFound
+	}
+	result.ChallengeHash = ""
+	result.ChallengeExpiresAt = nil
+	r.mfa[userID] = result
+	return nil
+}
+
+func (r *mfaMemory) UseMFAStep(_ context.Context, userID string, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.mfa[userID]
+	if !ok {
+		return ErrMFANotFound
+	}
+	if step <= result.LastStep {
+		return ErrMFACodeUsed
+	}
+	result.LastStep = step
+	r.mfa[userID] = result
+	return nil
+}
+
+func (r *mfaMemory) UseMFARecoveryCode(_ context.Context, userID string, codeHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.mfa[userID]
+	if !ok {
+		return ErrMFARecoveryCodeNotFound
+	}
+	i := slices.Index(result.RecoveryCodes, codeHash)
+	if i < 0 {
+		return ErrMFARecoveryCodeNotFound
+	}
+	result.RecoveryCodes = slices.Delete(slices.Clone(result.RecoveryCodes), i, i+1)
+	r.mfa[userID] = result
+	return nil
+}
+
+func (r *mfaMemory) DeleteMFA(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.mfa[userID]; !ok {
+		return ErrMFANotFound
+	}
+	delete(r.mfa, userID)
+	return nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mfaRepoMock struct {
+	mock.Mock
+}
+
+func NewMFARepositoryMock() *mfaRepoMock {
+	return &mfaRepoMock{}
+}
+
+func (m *mfaRepoMock) CreateMFA(ctx context.Context, payload models.RepoCreateMFAModel) (result models.RepoResMFAModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResMFAModel), args.Error(1)
+}
+
+func (m *mfaRepoMock) GetMFAByUserID(ctx context.Context, userID string) (result models.RepoResMFAModel, err error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.RepoResMFAModel), args.Error(1)
+}
+
+func (m *mfaRepoMock) GetMFAByChallengeHash(ctx context.Context, challengeHash string) (result models.RepoResMFAModel, err error) {
+	args := m.Called(ctx, challengeHash)
+	return args.Get(0).(models.RepoResMFAModel), args.Error(1)
+}
+
+func (m *mfaRepoMock) EnableMFA(ctx context.Context, userID string, recoveryCodes []string, enabledAt time.Time) (result models.RepoResMFAModel, err error) {
+	args := m.Called(ctx, userID, recoveryCodes, enabledAt)
+	return args.Get(0).(models.RepoResMFAModel), args.Error(1)
+}
+
+func (m *mfaRepoMock) SetMFAChallenge(ctx context.Context, userID string, challengeHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, challengeHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mfaRepoMock) UseMFAChallenge(ctx context.Context, userID string, challengeHash string) error {
+	args := m.Called(ctx, userID, challengeHash)
+	return args.Error(0)
+}
+
+func (m *mfaRepoMock) UseMFAStep(ctx context.Context, userID string, step int64) error {
+	args := m.Called(ctx, userID, step)
+	return args.Error(0)
+}
+
+func (m *mfaRepoMock) UseMFARecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Error(0)
+}
+
+func (m *mfaRepoMock) DeleteMFA(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mfaRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewMFARepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) MFARepository {
+	return &mfaRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *mfaRepo) CreateMFA(ctx context.Context, payload models.RepoCreateMFAModel) (result models.RepoResMFAModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", "CreateMFA")
+	defer cancel()
+
+	result = models.RepoResMFAModel{
+		UserID:        payload.UserID,
+		Secret:        payload.Secret,
+		RecoveryCodes: []string{},
+		CreateAt:      payload.CreateAt,
+	}
+	// NOTE ถ้าเปิดใช้อยู่แล้ว filter ไม่ตรง upsert จะชน unique index ของ userId
+	filter := bson.M{"userId": payload.UserID, "enabled": false}
+	_, err = r.db.Collection(r.collection).ReplaceOne(ctx, filter, result, options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return result, ErrMFAAlreadyEnabled
+	}
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepo) GetMFAByUserID(ctx context.Context, userID string) (result models.RepoResMFAModel, err error) {
+	return r.findOne(ctx, "GetMFAByUserID", bson.M{"userId": userID}, ErrMFANotFound)
+}
+
+func (r *mfaRepo) GetMFAByChallengeHash(ctx context.Context, challengeHash string) (result models.RepoResMFAModel, err error) {
+	return r.findOne(ctx, "GetMFAByChallengeHash", bson.M{"challengeHash": challengeHash}, ErrMFAChallengeNotFound)
+}
+
+func (r *mfaRepo) findOne(ctx context.Context, method string, filter bson.M, notFound error) (result models.RepoResMFAModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", method)
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, filter)
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), notFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepo) EnableMFA(ctx context.Context, userID string, recoveryCodes []string, enabledAt time.Time) (result models.RepoResMFAModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", "EnableMFA")
+	defer cancel()
+
+	after := options.After
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	filter := bson.M{"userId": userID, "enabled": false}
+	update := bson.M{"$set": bson.M{"enabled": true, "recoveryCodes": recoveryCodes, "enabledAt": enabledAt}}
+	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, filter, update, &opt)
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrMFAAlreadyEnabled)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *mfaRepo) SetMFAChallenge(ctx context.Context, userID string, challengeHash string, expiresAt time.Time) error {
+	update := bson.M{"$set": bson.M{"challengeHash": challengeHash, "challengeExpiresAt": expiresAt}}
+	return r.updateOne(ctx, "SetMFAChallenge", bson.M{"userId": userID}, update, ErrMFANotFound)
+}
+
+func (r *mfaRepo) UseMFAChallenge(ctx context.Context, userID string, challengeHash string) error {
+	filter := bson.M{"userId": userID, "challengeHash": challengeHash}
+	update := bson.M{"$unset": bson.M{"challengeHash": "", "challengeExpiresAt": ""}}
+	return r.updateOne(ctx, "UseMFAChallenge", filter, update, ErrMFAChallengeNotFound)
+}
+
+func (r *mfaRepo) UseMFAStep(ctx context.Context, userID string, step int64) error {
+	filter := bson.M{"userId": userID, "lastStep": bson.M{"$lt": step}}
+	return r.updateOne(ctx, "UseMFAStep", filter, bson.M{"$set": bson.M{"lastStep": step}}, ErrMFACodeUsed)
+}
+
+func (r *mfaRepo) UseMFARecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	filter := bson.M{"userId": userID, "recoveryCodes": codeHash}
+	return r.updateOne(ctx, "UseMFARecoveryCode", filter, bson.M{"$pull": bson.M{"recoveryCodes": codeHash}}, ErrMFARecoveryCodeNotFound)
+}
+
+// NOTE filter ไม่ตรง (ไม่มีหรือเงื่อนไขไม่ผ่าน) คืน notFound
+func (r *mfaRepo) updateOne(ctx context.Context, method string, filter bson.M, update bson.M, notFound error) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", method)
+	defer cancel()
+
+	res, err := r.db.Collection(r.collection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.MatchedCount == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+func (r *mfaRepo) DeleteMFA(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", "DeleteMFA")
+	defer cancel()
+
+	res, err := r.db.Collection(r.collection).DeleteOne(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.DeletedCount == 0 {
+		return ErrMFANotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+type mfaSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewMFASQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) MFARepository {
+	return &mfaSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const mfaSQLColumns = `user_id, secret, enabled, recovery_codes, last_step, challenge_hash, challenge_expires_at, created_at, enabled_at`
+
+func scanMFA(row *sql.Row) (result models.RepoResMFAModel, err error) {
+	var recoveryCodes string
+	var challengeHash sql.NullString
+	err = row.Scan(&result.UserID, &result.Secret, &result.Enabled, &recoveryCodes, &result.LastStep, &challengeHash,
+		sqlNullTime{dst: &result.ChallengeExpiresAt}, sqlTime{dst: &result.CreateAt}, sqlNullTime{dst: &result.EnabledAt})
+	result.RecoveryCodes = splitSQLList(recoveryCodes)
+	result.ChallengeHash = challengeHash.String
+	return result, err
+}
+
+// list ที่เก็บเป็น TEXT คั่นด้วย comma
+func splitSQLList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+func (r *mfaSQLRepo) CreateMFA(ctx context.Context, payload models.RepoCreateMFAModel) (result models.RepoResMFAModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", "CreateMFA")
+	defer cancel()
+
+	// NOTE แทนที่การลงทะเบียนที่ยังไม่ยืนยันเท่านั้น
+	query := `INSERT INTO user_mfa (user_id, secret, enabled, recovery_codes, last_step, created_at) VALUES (?, ?, ?, '', 0, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = excluded.secret,
+			recovery_codes = '',
+			last_step = 0,
+			challenge_hash = NULL,
+			challenge_expires_at = NULL,
+			created_at = excluded.created_at
+		WHERE user_mfa.enabled = ?`
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		payload.UserID, payload.Secret, false, r.dialect.timeValue(payload.CreateAt), false)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	if inserted == 0 {
+		return result, ErrMFAAlreadyEnabled
+	}
+
+	return r.GetMFAByUserID(ctx, payload.UserID)
+}
+
+func (r *mfaSQLRepo) GetMFAByUserID(ctx context.Context, userID string) (result models.RepoResMFAModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", "GetMFAByUserID")
+	defer cancel()
+
+	query := `SELECT ` + mfaSQLColumns + ` FROM user_mfa WHERE user_id = ?`
+	result, err = scanMFA(r.db.QueryRowContext(ctx, r.dialect.rebind(query), userID))
+	if err != nil {
+		return result, sqlError(err, ErrMFANotFound)
+	}
+
+	return result, nil
+}
+
+func (r *mfaSQLRepo) GetMFAByChallengeHash(ctx context.Context, challengeHash string) (result models.RepoResMFAModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", "GetMFAByChallengeHash")
+	defer cancel()
+
+	query := `SELECT ` + mfaSQLColumns + ` FROM user_mfa WHERE challenge_hash = ?`
+	result, err = scanMFA(r.db.QueryRowContext(ctx, r.dialect.rebind(query), challengeHash))
+	if err != nil {
+		return result, sqlError(err, ErrMFAChallengeNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *mfaSQLRepo) EnableMFA(ctx context.Context, userID string, recoveryCodes []string, enabledAt time.Time) (result models.RepoResMFAModel, err error) {
+	query := `UPDATE user_mfa SET enabled = ?, recovery_codes = ?, enabled_at = ? WHERE user_id = ? AND enabled = ?`
+	err = r.update(ctx, "EnableMFA", query, ErrMFAAlreadyEnabled,
+		true, strings.Join(recoveryCodes, ","), r.dialect.timeValue(enabledAt), userID, false)
+	if err != nil {
+		return result, err
+	}
+
+	return r.GetMFAByUserID(ctx, userID)
+}
+
+func (r *mfaSQLRepo) SetMFAChallenge(ctx context.Context, userID string, challengeHash string, expiresAt time.Time) error {
+	query := `UPDATE user_mfa SET challenge_hash = ?, challenge_expires_at = ? WHERE user_id = ?`
+	return r.update(ctx, "SetMFAChallenge", query, ErrMFANotFound, challengeHash, r.dialect.timeValue(expiresAt), userID)
+}
+
+func (r *mfaSQLRepo) UseMFAChallenge(ctx context.Context, userID string, challengeHash string) error {
+	query := `UPDATE user_mfa SET challenge_hash = NULL, challenge_expires_at = NULL WHERE user_id = ? AND challenge_hash = ?`
+	return r.update(ctx, "UseMFAChallenge", query, ErrMFAChallengeNotFound, userID, challengeHash)
+}
+
+func (r *mfaSQLRepo) UseMFAStep(ctx context.Context, userID string, step int64) error {
+	query := `UPDATE user_mfa SET last_step = ? WHERE user_id = ? AND last_step < ?`
+	return r.update(ctx, "UseMFAStep", query, ErrMFACodeUsed, step, userID, step)
+}
+
+func (r *mfaSQLRepo) UseMFARecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	mfa, err := r.GetMFAByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	index := slices.Index(mfa.RecoveryCodes, codeHash)
+	if index < 0 {
+		return ErrMFARecoveryCodeNotFound
+	}
+	remaining := slices.Delete(slices.Clone(mfa.RecoveryCodes), index, index+1)
+
+	// NOTE อัปเดตเฉพาะเมื่อรายการยังไม่ถูกเปลี่ยน ใช้รหัสเดียวกันพร้อมกันได้ครั้งเดียว
+	query := `UPDATE user_mfa SET recovery_codes = ? WHERE user_id = ? AND recovery_codes = ?`
+	return r.update(ctx, "UseMFARecoveryCode", query, ErrMFARecoveryCodeNotFound,
+		strings.Join(remaining, ","), userID, strings.Join(mfa.RecoveryCodes, ","))
+}
+
+func (r *mfaSQLRepo) DeleteMFA(ctx context.Context, userID string) error {
+	return r.update(ctx, "DeleteMFA", `DELETE FROM user_mfa WHERE user_id = ?`, ErrMFANotFound, userID)
+}
+
+// NOTE ไม่มีแถวที่ตรงเงื่อนไขคืน notFound
+func (r *mfaSQLRepo) update(ctx context.Context, method string, query string, notFound error, args ...any) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "mfa", method)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	if updated == 0 {
+		return notFound
+	}
+
+	return nil
+}
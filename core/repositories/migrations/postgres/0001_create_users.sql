@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    password   TEXT NOT NULL,
+    role       TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX users_email_key ON users (lower(email));
+CREATE INDEX users_created_at_idx ON users (created_at, id);
@@ -0,0 +1,10 @@
+CREATE TABLE revoked_tokens (
+    jti        TEXT,
+    user_id    TEXT,
+    revoked_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX revoked_tokens_jti_idx ON revoked_tokens (jti);
+CREATE INDEX revoked_tokens_user_id_idx ON revoked_tokens (user_id, revoked_at);
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE email_verifications (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX email_verifications_user_id_idx ON email_verifications (user_id);
+CREATE INDEX email_verifications_expires_at_idx ON email_verifications (expires_at);
@@ -0,0 +1,9 @@
+CREATE TABLE login_attempts (
+    attempt_key     TEXT PRIMARY KEY,
+    failures        INTEGER NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    locked_until    TIMESTAMPTZ,
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX login_attempts_expires_at_idx ON login_attempts (expires_at);
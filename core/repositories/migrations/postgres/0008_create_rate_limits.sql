@@ -0,0 +1,9 @@
+CREATE TABLE rate_limits (
+    limit_key    TEXT NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    count        BIGINT NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (limit_key, window_start)
+);
+
+CREATE INDEX rate_limits_expires_at_idx ON rate_limits (expires_at);
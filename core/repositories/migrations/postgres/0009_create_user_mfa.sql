@@ -0,0 +1,13 @@
+CREATE TABLE user_mfa (
+    user_id              TEXT PRIMARY KEY,
+    secret               TEXT NOT NULL,
+    enabled              BOOLEAN NOT NULL DEFAULT FALSE,
+    recovery_codes       TEXT NOT NULL DEFAULT '',
+    last_step            BIGINT NOT NULL DEFAULT 0,
+    challenge_hash       TEXT,
+    challenge_expires_at TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL,
+    enabled_at           TIMESTAMPTZ
+);
+
+CREATE INDEX user_mfa_challenge_hash_idx ON user_mfa (challenge_hash);
@@ -0,0 +1,23 @@
+-- NOTE redirect_uris คั่นด้วยช่องว่าง scopes และ grant_types คั่นด้วย comma
+CREATE TABLE oauth_clients (
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL,
+    secret_hash   TEXT NOT NULL DEFAULT '',
+    redirect_uris TEXT NOT NULL DEFAULT '',
+    scopes        TEXT NOT NULL DEFAULT '',
+    grant_types   TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE oauth_codes (
+    code_hash      TEXT PRIMARY KEY,
+    client_id      TEXT NOT NULL,
+    user_id        TEXT NOT NULL,
+    redirect_uri   TEXT NOT NULL,
+    scopes         TEXT NOT NULL DEFAULT '',
+    code_challenge TEXT NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX oauth_codes_expires_at_idx ON oauth_codes (expires_at);
@@ -0,0 +1,11 @@
+-- NOTE เวลาเก็บเป็น unix microsecond (UTC)
+CREATE TABLE revoked_tokens (
+    jti        TEXT,
+    user_id    TEXT,
+    revoked_at INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX revoked_tokens_jti_idx ON revoked_tokens (jti);
+CREATE INDEX revoked_tokens_user_id_idx ON revoked_tokens (user_id, revoked_at);
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
@@ -0,0 +1,2 @@
+-- NOTE เวลาเก็บเป็น unix microsecond (UTC)
+ALTER TABLE users ADD COLUMN last_login_at INTEGER;
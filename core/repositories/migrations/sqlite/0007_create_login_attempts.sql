@@ -0,0 +1,10 @@
+-- NOTE เวลาเก็บเป็น unix microsecond (UTC)
+CREATE TABLE login_attempts (
+    attempt_key     TEXT PRIMARY KEY,
+    failures        INTEGER NOT NULL,
+    last_failure_at INTEGER NOT NULL,
+    locked_until    INTEGER,
+    expires_at      INTEGER NOT NULL
+);
+
+CREATE INDEX login_attempts_expires_at_idx ON login_attempts (expires_at);
@@ -0,0 +1,10 @@
+-- NOTE เวลาเก็บเป็น unix microsecond (UTC)
+CREATE TABLE rate_limits (
+    limit_key    TEXT NOT NULL,
+    window_start INTEGER NOT NULL,
+    count        INTEGER NOT NULL,
+    expires_at   INTEGER NOT NULL,
+    PRIMARY KEY (limit_key, window_start)
+);
+
+CREATE INDEX rate_limits_expires_at_idx ON rate_limits (expires_at);
@@ -0,0 +1,14 @@
+-- NOTE recovery_codes เก็บ hash คั่นด้วย comma
+CREATE TABLE user_mfa (
+    user_id              TEXT PRIMARY KEY,
+    secret               TEXT NOT NULL,
+    enabled              INTEGER NOT NULL DEFAULT 0,
+    recovery_codes       TEXT NOT NULL DEFAULT '',
+    last_step            INTEGER NOT NULL DEFAULT 0,
+    challenge_hash       TEXT,
+    challenge_expires_at INTEGER,
+    created_at           INTEGER NOT NULL,
+    enabled_at           INTEGER
+);
+
+CREATE INDEX user_mfa_challenge_hash_idx ON user_mfa (challenge_hash);
@@ -0,0 +1,15 @@
+-- NOTE permissions คั่นด้วย comma
+CREATE TABLE api_keys (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL UNIQUE,
+    key_hash     TEXT NOT NULL,
+    permissions  TEXT NOT NULL DEFAULT '',
+    expires_at   INTEGER,
+    created_at   INTEGER NOT NULL,
+    last_used_at INTEGER,
+    revoked_at   INTEGER
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,23 @@
+-- NOTE เวลาเก็บเป็น unix microsecond (UTC)
+CREATE TABLE oidc_states (
+    state_hash    TEXT PRIMARY KEY,
+    provider      TEXT NOT NULL,
+    nonce         TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at    INTEGER NOT NULL,
+    created_at    INTEGER NOT NULL
+);
+
+CREATE INDEX oidc_states_expires_at_idx ON oidc_states (expires_at);
+
+CREATE TABLE user_identities (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    provider   TEXT NOT NULL,
+    subject    TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
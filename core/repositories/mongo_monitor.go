@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/common/tracing"
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mongoOperationKey struct{}
+
+type mongoOperationLabel struct {
+	repository string
+	method     string
+}
+
+// context ของคำสั่ง mongo ที่ติดชื่อ repository/method ไว้ให้ monitor ใช้เป็น label
+func mongoOperation(ctx context.Context, repository string, method string) context.Context {
+	return context.WithValue(ctx, mongoOperationKey{}, mongoOperationLabel{repository, method})
+}
+
+// monitor ของ mongo driver ที่จับเวลาและนับ error ของแต่ละคำสั่งตาม repository method
+// และสร้าง span ของคำสั่งต่อจาก span ใน ctx (ถ้ามี)
+// NOTE คำสั่งที่ไม่ได้มาจาก repository (เช่น ping, สร้าง index) ไม่มี label จะถูกข้ามไป
+func NewMongoMonitor(m *metrics.Metrics) *event.CommandMonitor {
+	// NOTE span ที่เปิดอยู่ key คือ RequestID ของคำสั่ง (ไม่ซ้ำกันใน process)
+	spans := sync.Map{}
+
+	finish := func(ctx context.Context, finished event.CommandFinishedEvent, failure string) {
+		if value, ok := spans.LoadAndDelete(finished.RequestID); ok {
+			span := value.(trace.Span)
+			if failure != "" {
+				span.SetStatus(codes.Error, failure)
+			}
+			span.End()
+		}
+
+		label, ok := ctx.Value(mongoOperationKey{}).(mongoOperationLabel)
+		if !ok {
+			return
+		}
+		m.ObserveMongo(label.repository, label.method, finished.Duration, failure != "")
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			label, ok := ctx.Value(mongoOperationKey{}).(mongoOperationLabel)
+			if !ok || !trace.SpanContextFromContext(ctx).IsValid() {
+				return
+			}
+			_, span := tracing.Start(ctx, "mongo."+e.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemNameMongoDB,
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBOperationName(e.CommandName),
+					attribute.String("app.repository", label.repository),
+					attribute.String("app.repository.method", label.method),
+				),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			finish(ctx, e.CommandFinishedEvent, "")
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			finish(ctx, e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import "context"
+
+import "7solutions/backend/core/models"
+
+type OAuthClientRepository interface {
+	CreateOAuthClient(ctx context.Context, payload models.RepoCreateOAuthClientModel) (result models.RepoResOAuthClientModel, err error)
+
+	GetOAuthClient(ctx context.Context, id string) (result models.RepoResOAuthClientModel, err error)
+
+	// client ทั้งหมดเรียงจากใหม่ไปเก่า
+	GetOAuthClients(ctx context.Context) (result []models.RepoResOAuthClientModel, err error)
+
+	DeleteOAuthClient(ctx context.Context, id string) error
+}
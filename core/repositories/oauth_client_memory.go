@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"slices"
+	"sync"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type oauthClientMemory struct {
+	mu      sync.Mutex
+	clients map[string]models.RepoResOAuthClientModel
+}
+
+func NewOAuthClientMemoryRepository() OAuthClientRepository {
+	return &oauthClientMemory{
+		clients: map[string]models.RepoResOAuthClientModel{},
+	}
+}
+
+func (r *oauthClientMemory) CreateOAuthClient(_ context.Context, payload models.RepoCreateOAuthClientModel) (result models.RepoResOAuthClientModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result = models.RepoResOAuthClientModel(payload)
+	r.clients[result.ID] = result
+	return result, nil
+}
+
+func (r *oauthClientMemory) GetOAuthClient(_ context.Context, id string) (result models.RepoResOAuthClientModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.clients[id]
+	if !ok {
+		return result, ErrOAuthClientNotFound
+	}
+	return result, nil
+}
+
+func (r *oauthClientMemory) GetOAuthClients(_ context.Context) (result []models.RepoResOAuthClientModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result = []models.RepoResOAuthClientModel{}
+	for _, client := range r.clients {
+		result = append(result, client)
+	}
+	slices.SortFunc(result, func(a, b models.RepoResOAuthClientModel) int {
+		return b.CreateAt.Compare(a.CreateAt)
+	})
+	return result, nil
+}
+
+func (r *oauthClientMemory) DeleteOAuthClient(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[id]; !ok {
+		return ErrOAuthClientNotFound
+	}
+	delete(r.clients, id)
+	return nil
+}
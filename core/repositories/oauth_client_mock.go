@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type oauthClientRepoMock struct {
+	mock.Mock
+}
+
+func NewOAuthClientRepositoryMock() *oauthClientRepoMock {
+	return &oauthClientRepoMock{}
+}
+
+func (m *oauthClientRepoMock) CreateOAuthClient(ctx context.Context, payload models.RepoCreateOAuthClientModel) (result models.RepoResOAuthClientModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResOAuthClientModel), args.Error(1)
+}
+
+func (m *oauthClientRepoMock) GetOAuthClient(ctx context.Context, id string) (result models.RepoResOAuthClientModel, err error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.RepoResOAuthClientModel), args.Error(1)
+}
+
+func (m *oauthClientRepoMock) GetOAuthClients(ctx context.Context) (result []models.RepoResOAuthClientModel, err error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.RepoResOAuthClientModel), args.Error(1)
+}
+
+func (m *oauthClientRepoMock) DeleteOAuthClient(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type oauthClientRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewOAuthClientRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) OAuthClientRepository {
+	return &oauthClientRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *oauthClientRepo) CreateOAuthClient(ctx context.Context, payload models.RepoCreateOAuthClientModel) (result models.RepoResOAuthClientModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "CreateOAuthClient")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResOAuthClientModel(payload), nil
+}
+
+func (r *oauthClientRepo) GetOAuthClient(ctx context.Context, id string) (result models.RepoResOAuthClientModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "GetOAuthClient")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"id": id})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrOAuthClientNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *oauthClientRepo) GetOAuthClients(ctx context.Context) (result []models.RepoResOAuthClientModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "GetOAuthClients")
+	defer cancel()
+
+	opt := options.Find().SetSort(bson.D{{Key: "createAt", Value: -1}})
+	cursor, err := r.db.Collection(r.collection).Find(ctx, bson.M{}, opt)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	result = []models.RepoResOAuthClientModel{}
+	if err := cursor.All(ctx, &result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *oauthClientRepo) DeleteOAuthClient(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "DeleteOAuthClient")
+	defer cancel()
+
+	res, err := r.db.Collection(r.collection).DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+	if res.DeletedCount == 0 {
+		return ErrOAuthClientNotFound
+	}
+
+	return nil
+}
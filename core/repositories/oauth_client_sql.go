@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+)
+
+type oauthClientSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewOAuthClientSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) OAuthClientRepository {
+	return &oauthClientSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const oauthClientSQLColumns = `id, name, secret_hash, redirect_uris, scopes, grant_types, created_at`
+
+// NOTE redirect URI อาจมี comma จึงคั่นด้วยช่องว่าง (URI มีช่องว่างไม่ได้)
+func scanOAuthClient(row interface{ Scan(...interface{}) error }) (result models.RepoResOAuthClientModel, err error) {
+	var redirectURIs, scopes, grantTypes string
+	err = row.Scan(&result.ID, &result.Name, &result.SecretHash, &redirectURIs, &scopes, &grantTypes, sqlTime{dst: &result.CreateAt})
+	result.RedirectURIs = strings.Fields(redirectURIs)
+	result.Scopes = splitSQLList(scopes)
+	result.GrantTypes = splitSQLList(grantTypes)
+	return result, err
+}
+
+func (r *oauthClientSQLRepo) CreateOAuthClient(ctx context.Context, payload models.RepoCreateOAuthClientModel) (result models.RepoResOAuthClientModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "CreateOAuthClient")
+	defer cancel()
+
+	query := `INSERT INTO oauth_clients (` + oauthClientSQLColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.Name, payload.SecretHash, strings.Join(payload.RedirectURIs, " "),
+		strings.Join(payload.Scopes, ","), strings.Join(payload.GrantTypes, ","), r.dialect.timeValue(payload.CreateAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResOAuthClientModel(payload), nil
+}
+
+func (r *oauthClientSQLRepo) GetOAuthClient(ctx context.Context, id string) (result models.RepoResOAuthClientModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "GetOAuthClient")
+	defer cancel()
+
+	query := `SELECT ` + oauthClientSQLColumns + ` FROM oauth_clients WHERE id = ?`
+	result, err = scanOAuthClient(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err != nil {
+		return result, sqlError(err, ErrOAuthClientNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *oauthClientSQLRepo) GetOAuthClients(ctx context.Context) (result []models.RepoResOAuthClientModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "GetOAuthClients")
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+oauthClientSQLColumns+` FROM oauth_clients ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	defer rows.Close()
+
+	result = []models.RepoResOAuthClientModel{}
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return result, sqlError(err, nil)
+		}
+		result = append(result, client)
+	}
+	if err := rows.Err(); err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *oauthClientSQLRepo) DeleteOAuthClient(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_client", "DeleteOAuthClient")
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM oauth_clients WHERE id = ?`), id)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	if deleted == 0 {
+		return ErrOAuthClientNotFound
+	}
+
+	return nil
+}
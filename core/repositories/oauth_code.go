@@ -0,0 +1,12 @@
+package repositories
+
+import "context"
+
+import "7solutions/backend/core/models"
+
+type OAuthCodeRepository interface {
+	CreateOAuthCode(ctx context.Context, payload models.RepoCreateOAuthCodeModel) (result models.RepoResOAuthCodeModel, err error)
+
+	// ดึงแล้วลบแบบ atomic (ใช้ได้ครั้งเดียว) คืน ErrOAuthCodeNotFound ถ้าไม่มีหรือถูกใช้ไปแล้ว
+	UseOAuthCode(ctx context.Context, codeHash string) (result models.RepoResOAuthCodeModel, err error)
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type oauthCodeMemory struct {
+	mu    sync.Mutex
+	codes map[string]models.RepoResOAuthCodeModel
+}
+
+func NewOAuthCodeMemoryRepository() OAuthCodeRepository {
+	return &oauthCodeMemory{
+		codes: map[string]models.RepoResOAuthCodeModel{},
+	}
+}
+
+func (r *oauthCodeMemory) CreateOAuthCode(_ context.Context, payload models.RepoCreateOAuthCodeModel) (result models.RepoResOAuthCodeModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบ code ที่หมดอายุแทน TTL index
+	now := time.Now()
+	for hash, code := range r.codes {
+		if now.After(code.ExpiresAt) {
+			delete(r.codes, hash)
+		}
+	}
+
+	result = models.RepoResOAuthCodeModel(payload)
+	r.codes[result.CodeHash] = result
+	return result, nil
+}
+
+func (r *oauthCodeMemory) UseOAuthCode(_ context.Context, codeHash string) (result models.RepoResOAuthCodeModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.codes[codeHash]
+	if !ok {
+		return result, ErrOAuthCodeNotFound
+	}
+	delete(r.codes, codeHash)
+	return result, nil
+}
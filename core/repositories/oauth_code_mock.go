@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type oauthCodeRepoMock struct {
+	mock.Mock
+}
+
+func NewOAuthCodeRepositoryMock() *oauthCodeRepoMock {
+	return &oauthCodeRepoMock{}
+}
+
+func (m *oauthCodeRepoMock) CreateOAuthCode(ctx context.Context, payload models.RepoCreateOAuthCodeModel) (result models.RepoResOAuthCodeModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResOAuthCodeModel), args.Error(1)
+}
+
+func (m *oauthCodeRepoMock) UseOAuthCode(ctx context.Context, codeHash string) (result models.RepoResOAuthCodeModel, err error) {
+	args := m.Called(ctx, codeHash)
+	return args.Get(0).(models.RepoResOAuthCodeModel), args.Error(1)
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type oauthCodeRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewOAuthCodeRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) OAuthCodeRepository {
+	return &oauthCodeRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *oauthCodeRepo) CreateOAuthCode(ctx context.Context, payload models.RepoCreateOAuthCodeModel) (result models.RepoResOAuthCodeModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_code", "CreateOAuthCode")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResOAuthCodeModel(payload), nil
+}
+
+func (r *oauthCodeRepo) UseOAuthCode(ctx context.Context, codeHash string) (result models.RepoResOAuthCodeModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_code", "UseOAuthCode")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOneAndDelete(ctx, bson.M{"codeHash": codeHash})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrOAuthCodeNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
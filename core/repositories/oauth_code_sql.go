@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type oauthCodeSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewOAuthCodeSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) OAuthCodeRepository {
+	return &oauthCodeSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const oauthCodeSQLColumns = `code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at`
+
+func (r *oauthCodeSQLRepo) CreateOAuthCode(ctx context.Context, payload models.RepoCreateOAuthCodeModel) (result models.RepoResOAuthCodeModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_code", "CreateOAuthCode")
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบ code ที่หมดอายุตอนสร้างใหม่
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM oauth_codes WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query := `INSERT INTO oauth_codes (` + oauthCodeSQLColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.CodeHash, payload.ClientID, payload.UserID, payload.RedirectURI,
+		strings.Join(payload.Scopes, ","), payload.CodeChallenge, r.dialect.timeValue(payload.ExpiresAt), r.dialect.timeValue(payload.CreateAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResOAuthCodeModel(payload), nil
+}
+
+func (r *oauthCodeSQLRepo) UseOAuthCode(ctx context.Context, codeHash string) (result models.RepoResOAuthCodeModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oauth_code", "UseOAuthCode")
+	defer cancel()
+
+	var scopes string
+	query := `DELETE FROM oauth_codes WHERE code_hash = ? RETURNING ` + oauthCodeSQLColumns
+	err = r.db.QueryRowContext(ctx, r.dialect.rebind(query), codeHash).Scan(&result.CodeHash, &result.ClientID, &result.UserID,
+		&result.RedirectURI, &scopes, &result.CodeChallenge, sqlTime{dst: &result.ExpiresAt}, sqlTime{dst: &result.CreateAt})
+	if err != nil {
+		return result, sqlError(err, ErrOAuthCodeNotFound)
+	}
+	result.Scopes = splitSQLList(scopes)
+
+	return result, nil
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import "context"
+
+import "7solutions/backend/core/models"
+
+type OIDCStateRepository interface {
+	CreateOIDCState(ctx context.Context, payload models.RepoCreateOIDCStateModel) (result models.RepoResOIDCStateModel, err error)
+
+	// ดึงแล้วลบแบบ atomic (ใช้ได้ครั้งเดียว) คืน ErrOIDCStateNotFound ถ้าไม่มีหรือถูกใช้ไปแล้ว
+	UseOIDCState(ctx context.Context, stateHash string) (result models.RepoResOIDCStateModel, err error)
+}
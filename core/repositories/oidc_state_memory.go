@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type oidcStateMemory struct {
+	mu     sync.Mutex
+	states map[string]models.RepoResOIDCStateModel
+}
+
+func NewOIDCStateMemoryRepository() OIDCStateRepository {
+	return &oidcStateMemory{
+		states: map[string]models.RepoResOIDCStateModel{},
+	}
+}
+
+func (r *oidcStateMemory) CreateOIDCState(_ context.Context, payload models.RepoCreateOIDCStateModel) (result models.RepoResOIDCStateModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบ state ที่หมดอายุแทน TTL index
+	now := time.Now()
+	for hash, state := range r.states {
+		if now.After(state.ExpiresAt) {
+			delete(r.states, hash)
+		}
+	}
+
+	result = models.RepoResOIDCStateModel(payload)
+	r.states[result.StateHash] = result
+	return result, nil
+}
+
+func (r *oidcStateMemory) UseOIDCState(_ context.Context, stateHash string) (result models.RepoResOIDCStateModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.states[stateHash]
+	if !ok {
+		return result, ErrOIDCStateNotFound
+	}
+	delete(r.states, stateHash)
+	return result, nil
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type oidcStateRepoMock struct {
+	mock.Mock
+}
+
+func NewOIDCStateRepositoryMock() *oidcStateRepoMock {
+	return &oidcStateRepoMock{}
+}
+
+func (m *oidcStateRepoMock) CreateOIDCState(ctx context.Context, payload models.RepoCreateOIDCStateModel) (result models.RepoResOIDCStateModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResOIDCStateModel), args.Error(1)
+}
+
+func (m *oidcStateRepoMock) UseOIDCState(ctx context.Context, stateHash string) (result models.RepoResOIDCStateModel, err error) {
+	args := m.Called(ctx, stateHash)
+	return args.Get(0).(models.RepoResOIDCStateModel), args.Error(1)
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type oidcStateRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewOIDCStateRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) OIDCStateRepository {
+	return &oidcStateRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *oidcStateRepo) CreateOIDCState(ctx context.Context, payload models.RepoCreateOIDCStateModel) (result models.RepoResOIDCStateModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oidc_state", "CreateOIDCState")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResOIDCStateModel(payload), nil
+}
+
+func (r *oidcStateRepo) UseOIDCState(ctx context.Context, stateHash string) (result models.RepoResOIDCStateModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oidc_state", "UseOIDCState")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOneAndDelete(ctx, bson.M{"stateHash": stateHash})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrOIDCStateNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+type oidcStateSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewOIDCStateSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) OIDCStateRepository {
+	return &oidcStateSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const oidcStateSQLColumns = `state_hash, provider, nonce, code_verifier, expires_at, created_at`
+
+func (r *oidcStateSQLRepo) CreateOIDCState(ctx context.Context, payload models.RepoCreateOIDCStateModel) (result models.RepoResOIDCStateModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oidc_state", "CreateOIDCState")
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบ state ที่หมดอายุตอนสร้างใหม่
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM oidc_states WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query := `INSERT INTO oidc_states (` + oidcStateSQLColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.StateHash, payload.Provider, payload.Nonce, payload.CodeVerifier,
+		r.dialect.timeValue(payload.ExpiresAt), r.dialect.timeValue(payload.CreateAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResOIDCStateModel(payload), nil
+}
+
+func (r *oidcStateSQLRepo) UseOIDCState(ctx context.Context, stateHash string) (result models.RepoResOIDCStateModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "oidc_state", "UseOIDCState")
+	defer cancel()
+
+	// NOTE postgres และ sqlite (3.35+) รองรับ DELETE ... RETURNING ผู้ที่ลบได้เท่านั้นที่ได้ state
+	query := `DELETE FROM oidc_states WHERE state_hash = ? RETURNING ` + oidcStateSQLColumns
+	err = r.db.QueryRowContext(ctx, r.dialect.rebind(query), stateHash).Scan(&result.StateHash, &result.Provider, &result.Nonce,
+		&result.CodeVerifier, sqlTime{dst: &result.ExpiresAt}, sqlTime{dst: &result.CreateAt})
+	if err != nil {
+		return result, sqlError(err, ErrOIDCStateNotFound)
+	}
+
+	return result, nil
+}
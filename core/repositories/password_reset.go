@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+type PasswordResetRepository interface {
+	CreatePasswordReset(ctx context.Context, payload models.RepoCreatePasswordResetModel) (result models.RepoResPasswordResetModel, err error)
+
+	GetPasswordResetByHash(ctx context.Context, tokenHash string) (result models.RepoResPasswordResetModel, err error)
+
+	// ทำเครื่องหมายว่าใช้แล้วแบบ atomic คืน ErrPasswordResetUsed ถ้ามีคนใช้ไปก่อน
+	UsePasswordReset(ctx context.Context, id string, usedAt time.Time) (result models.RepoResPasswordResetModel, err error)
+
+	// ทำให้ token ที่ยังไม่ถูกใช้ของ user ใช้ไม่ได้ทั้งหมด
+	UseUserPasswordResets(ctx context.Context, userID string, usedAt time.Time) error
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type passwordResetMemory struct {
+	mu     sync.Mutex
+	tokens map[string]models.RepoResPasswordResetModel
+}
+
+func NewPasswordResetMemoryRepository() PasswordResetRepository {
+	return &passwordResetMemory{
+		tokens: map[string]models.RepoResPasswordResetModel{},
+	}
+}
+
+func (r *passwordResetMemory) CreatePasswordReset(_ context.Context, payload models.RepoCreatePasswordResetModel) (result models.RepoResPasswordResetModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบ token ที่หมดอายุแทน TTL index
+	now := time.Now()
+	for id, token := range r.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(r.tokens, id)
+		}
+	}
+
+	result = models.RepoResPasswordResetModel(payload)
+	r.tokens[result.ID] = result
+	return result, nil
+}
+
+func (r *passwordResetMemory) GetPasswordResetByHash(_ context.Context, tokenHash string) (result models.RepoResPasswordResetModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return result, ErrPasswordResetNotFound
+}
+
+func (r *passwordResetMemory) UsePasswordReset(_ context.Context, id string, usedAt time.Time) (result models.RepoResPasswordResetModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.tokens[id]
+	if !ok || result.UsedAt != nil {
+		return result, ErrPasswordResetUsed
+	}
+	result.UsedAt = &usedAt
+	r.tokens[id] = result
+	return result, nil
+}
+
+func (r *passwordResetMemory) UseUserPasswordResets(_ context.Context, userID string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type passwordResetRepoMock struct {
+	mock.Mock
+}
+
+func NewPasswordResetRepositoryMock() *passwordResetRepoMock {
+	return &passwordResetRepoMock{}
+}
+
+func (m *passwordResetRepoMock) CreatePasswordReset(ctx context.Context, payload models.RepoCreatePasswordResetModel) (result models.RepoResPasswordResetModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResPasswordResetModel), args.Error(1)
+}
+
+func (m *passwordResetRepoMock) GetPasswordResetByHash(ctx context.Context, tokenHash string) (result models.RepoResPasswordResetModel, err error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(models.RepoResPasswordResetModel), args.Error(1)
+}
+
+func (m *passwordResetRepoMock) UsePasswordReset(ctx context.Context, id string, usedAt time.Time) (result models.RepoResPasswordResetModel, err error) {
+	args := m.Called(ctx, id, usedAt)
+	return args.Get(0).(models.RepoResPasswordResetModel), args.Error(1)
+}
+
+func (m *passwordResetRepoMock) UseUserPasswordResets(ctx context.Context, userID string, usedAt time.Time) error {
+	args := m.Called(ctx, userID, usedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type passwordResetRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewPasswordResetRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) PasswordResetRepository {
+	return &passwordResetRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *passwordResetRepo) CreatePasswordReset(ctx context.Context, payload models.RepoCreatePasswordResetModel) (result models.RepoResPasswordResetModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "CreatePasswordReset")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResPasswordResetModel(payload), nil
+}
+
+func (r *passwordResetRepo) GetPasswordResetByHash(ctx context.Context, tokenHash string) (result models.RepoResPasswordResetModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "GetPasswordResetByHash")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"tokenHash": tokenHash})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrPasswordResetNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *passwordResetRepo) UsePasswordReset(ctx context.Context, id string, usedAt time.Time) (result models.RepoResPasswordResetModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "UsePasswordReset")
+	defer cancel()
+
+	after := options.After
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	filter := bson.M{"id": id, "usedAt": nil}
+	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"usedAt": usedAt}}, &opt)
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrPasswordResetUsed)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *passwordResetRepo) UseUserPasswordResets(ctx context.Context, userID string, usedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "UseUserPasswordResets")
+	defer cancel()
+
+	filter := bson.M{"userId": userID, "usedAt": nil}
+	_, err := r.db.Collection(r.collection).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"usedAt": usedAt}})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+type passwordResetSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewPasswordResetSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) PasswordResetRepository {
+	return &passwordResetSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const passwordResetSQLColumns = `id, user_id, token_hash, expires_at, created_at, used_at`
+
+func scanPasswordReset(row *sql.Row) (result models.RepoResPasswordResetModel, err error) {
+	err = row.Scan(&result.ID, &result.UserID, &result.TokenHash,
+		sqlTime{dst: &result.ExpiresAt}, sqlTime{dst: &result.CreateAt}, sqlNullTime{dst: &result.UsedAt})
+	return result, err
+}
+
+func (r *passwordResetSQLRepo) CreatePasswordReset(ctx context.Context, payload models.RepoCreatePasswordResetModel) (result models.RepoResPasswordResetModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "CreatePasswordReset")
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบ token ที่หมดอายุตอนสร้างใหม่
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM password_resets WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query := `INSERT INTO password_resets (` + passwordResetSQLColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.UserID, payload.TokenHash,
+		r.dialect.timeValue(payload.ExpiresAt), r.dialect.timeValue(payload.CreateAt), r.dialect.nullTimeValue(payload.UsedAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResPasswordResetModel(payload), nil
+}
+
+func (r *passwordResetSQLRepo) GetPasswordResetByHash(ctx context.Context, tokenHash string) (result models.RepoResPasswordResetModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "GetPasswordResetByHash")
+	defer cancel()
+
+	query := `SELECT ` + passwordResetSQLColumns + ` FROM password_resets WHERE token_hash = ?`
+	result, err = scanPasswordReset(r.db.QueryRowContext(ctx, r.dialect.rebind(query), tokenHash))
+	if err != nil {
+		return result, sqlError(err, ErrPasswordResetNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *passwordResetSQLRepo) UsePasswordReset(ctx context.Context, id string, usedAt time.Time) (result models.RepoResPasswordResetModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "UsePasswordReset")
+	defer cancel()
+
+	query := `UPDATE password_resets SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(usedAt), id)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	if updated == 0 {
+		return result, ErrPasswordResetUsed
+	}
+
+	query = `SELECT ` + passwordResetSQLColumns + ` FROM password_resets WHERE id = ?`
+	result, err = scanPasswordReset(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err != nil {
+		return result, sqlError(err, ErrPasswordResetNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *passwordResetSQLRepo) UseUserPasswordResets(ctx context.Context, userID string, usedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "password_reset", "UseUserPasswordResets")
+	defer cancel()
+
+	query := `UPDATE password_resets SET used_at = ? WHERE user_id = ? AND used_at IS NULL`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(usedAt), userID)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	return nil
+}
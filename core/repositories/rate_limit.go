@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+// ตัวนับ rate limit เก็บในฐานข้อมูลเพื่อให้ทุก instance นับร่วมกัน
+type RateLimitRepository interface {
+	// เพิ่มตัวนับของ key ในหน้าต่างที่เริ่มที่ windowStart แบบ atomic
+	// คืนจำนวนของหน้าต่างนี้ (รวมครั้งนี้) และของหน้าต่างก่อนหน้า
+	IncrementRateLimit(ctx context.Context, key string, windowStart time.Time, window time.Duration) (result models.RepoResRateLimitModel, err error)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process นับแยกแต่ละ instance
+type rateLimitMemory struct {
+	mu        sync.Mutex
+	counters  map[string]rateLimitCounter
+	lastSweep time.Time
+}
+
+type rateLimitCounter struct {
+	windowStart time.Time
+	count       int64
+	previous    int64
+	expiresAt   time.Time
+}
+
+func NewRateLimitMemoryRepository() RateLimitRepository {
+	return &rateLimitMemory{
+		counters: map[string]rateLimitCounter{},
+	}
+}
+
+func (r *rateLimitMemory) IncrementRateLimit(_ context.Context, key string, windowStart time.Time, window time.Duration) (result models.RepoResRateLimitModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบตัวนับที่หมดอายุไม่เกินนาทีละครั้ง
+	now := time.Now()
+	if now.Sub(r.lastSweep) > time.Minute {
+		for k, counter := range r.counters {
+			if now.After(counter.expiresAt) {
+				delete(r.counters, k)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	counter := r.counters[key]
+	switch {
+	case counter.windowStart.Equal(windowStart):
+	case counter.windowStart.Equal(windowStart.Add(-window)):
+		counter.previous, counter.count = counter.count, 0
+	default:
+		counter.previous, counter.count = 0, 0
+	}
+	counter.windowStart = windowStart
+	counter.count++
+	counter.expiresAt = windowStart.Add(2 * window)
+	r.counters[key] = counter
+
+	result = models.RepoResRateLimitModel{
+		Key:           key,
+		WindowStart:   windowStart,
+		Count:         counter.count,
+		PreviousCount: counter.previous,
+	}
+	return result, nil
+}
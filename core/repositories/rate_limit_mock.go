@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type rateLimitRepoMock struct {
+	mock.Mock
+}
+
+func NewRateLimitRepositoryMock() *rateLimitRepoMock {
+	return &rateLimitRepoMock{}
+}
+
+func (m *rateLimitRepoMock) IncrementRateLimit(ctx context.Context, key string, windowStart time.Time, window time.Duration) (result models.RepoResRateLimitModel, err error) {
+	args := m.Called(ctx, key, windowStart, window)
+	return args.Get(0).(models.RepoResRateLimitModel), args.Error(1)
+}
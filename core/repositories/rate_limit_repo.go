@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type rateLimitRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewRateLimitRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) RateLimitRepository {
+	return &rateLimitRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *rateLimitRepo) IncrementRateLimit(ctx context.Context, key string, windowStart time.Time, window time.Duration) (result models.RepoResRateLimitModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "rate_limit", "IncrementRateLimit")
+	defer cancel()
+
+	after := options.After
+	upsert := true
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+		Upsert:         &upsert,
+	}
+
+	// NOTE 1 document ต่อ key ต่อหน้าต่าง TTL index ลบเมื่อพ้นหน้าต่างถัดไป
+	filter := bson.M{"key": key, "windowStart": windowStart}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"expiresAt": windowStart.Add(2 * window)},
+	}
+	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, filter, update, &opt)
+	if mongo.IsDuplicateKeyError(res.Err()) {
+		// NOTE upsert พร้อมกันสองที่ ตัวที่แพ้ลองใหม่ครั้งเดียวจะเจอ document ที่มีแล้ว
+		res = r.db.Collection(r.collection).FindOneAndUpdate(ctx, filter, update, &opt)
+	}
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), nil)
+	}
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	var previous models.RepoResRateLimitModel
+	err = r.db.Collection(r.collection).FindOne(ctx, bson.M{"key": key, "windowStart": windowStart.Add(-window)}).Decode(&previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return result, mongoError(err, nil)
+	}
+	result.PreviousCount = previous.Count
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type rateLimitSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// ลบตัวนับที่หมดอายุทั้งตารางไม่เกินช่วงนี้ต่อ instance
+const rateLimitSweepInterval = time.Minute
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewRateLimitSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) RateLimitRepository {
+	return &rateLimitSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+func (r *rateLimitSQLRepo) IncrementRateLimit(ctx context.Context, key string, windowStart time.Time, window time.Duration) (result models.RepoResRateLimitModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "rate_limit", "IncrementRateLimit")
+	defer cancel()
+
+	if err := r.sweep(ctx); err != nil {
+		return result, err
+	}
+
+	query := `INSERT INTO rate_limits (limit_key, window_start, count, expires_at) VALUES (?, ?, 1, ?)
+		ON CONFLICT (limit_key, window_start) DO UPDATE SET count = rate_limits.count + 1`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), key, r.dialect.timeValue(windowStart), r.dialect.timeValue(windowStart.Add(2*window)))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query = `SELECT window_start, count FROM rate_limits WHERE limit_key = ? AND window_start IN (?, ?)`
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), key, r.dialect.timeValue(windowStart), r.dialect.timeValue(windowStart.Add(-window)))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	defer rows.Close()
+
+	result = models.RepoResRateLimitModel{Key: key, WindowStart: windowStart}
+	for rows.Next() {
+		var start time.Time
+		var count int64
+		if err := rows.Scan(sqlTime{dst: &start}, &count); err != nil {
+			return result, sqlError(err, nil)
+		}
+		if start.Equal(windowStart.UTC().Truncate(time.Microsecond)) {
+			result.Count = count
+		} else {
+			result.PreviousCount = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return result, nil
+}
+
+// NOTE ไม่มี TTL index เหมือน mongo จึงลบตัวนับที่หมดอายุเป็นระยะ (ไม่ทำทุก request)
+func (r *rateLimitSQLRepo) sweep(ctx context.Context) error {
+	now := time.Now()
+	r.mu.Lock()
+	if now.Sub(r.lastSweep) < rateLimitSweepInterval {
+		r.mu.Unlock()
+		return nil
+	}
+	r.lastSweep = now
+	r.mu.Unlock()
+
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM rate_limits WHERE expires_at < ?`), r.dialect.timeValue(now))
+	return sqlError(err, nil)
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, payload models.RepoCreateRefreshTokenModel) (result models.RepoResRefreshTokenModel, err error)
+
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (result models.RepoResRefreshTokenModel, err error)
+
+	// ทำเครื่องหมายว่าใช้แล้วแบบ atomic คืน ErrRefreshTokenUsed ถ้ามีคนใช้ไปก่อน
+	UseRefreshToken(ctx context.Context, id string, usedAt time.Time) (result models.RepoResRefreshTokenModel, err error)
+
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+
+	RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type refreshTokenMemory struct {
+	mu     sync.Mutex
+	tokens map[string]models.RepoResRefreshTokenModel
+}
+
+func NewRefreshTokenMemoryRepository() RefreshTokenRepository {
+	return &refreshTokenMemory{
+		tokens: map[string]models.RepoResRefreshTokenModel{},
+	}
+}
+
+func (r *refreshTokenMemory) CreateRefreshToken(_ context.Context, payload models.RepoCreateRefreshTokenModel) (result models.RepoResRefreshTokenModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// NOTE ลบ token ที่หมดอายุแทน TTL index
+	now := time.Now()
+	for id, token := range r.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(r.tokens, id)
+		}
+	}
+
+	result = models.RepoResRefreshTokenModel(payload)
+	r.tokens[result.ID] = result
+	return result, nil
+}
+
+func (r *refreshTokenMemory) GetRefreshTokenByHash(_ context.Context, tokenHash string) (result models.RepoResRefreshTokenModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return result, ErrRefreshTokenNotFound
+}
+
+func (r *refreshTokenMemory) UseRefreshToken(_ context.Context, id string, usedAt time.Time) (result models.RepoResRefreshTokenModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.tokens[id]
+	if !ok || result.UsedAt != nil || result.RevokedAt != nil {
+		return result, ErrRefreshTokenUsed
+	}
+	result.UsedAt = &usedAt
+	r.tokens[id] = result
+	return result, nil
+}
+
+func (r *refreshTokenMemory) RevokeRefreshTokenFamily(_ context.Context, familyID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *refreshTokenMemory) RevokeUserRefreshTokens(_ context.Context, userID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
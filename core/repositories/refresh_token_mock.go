@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type refreshTokenRepoMock struct {
+	mock.Mock
+}
+
+func NewRefreshTokenRepositoryMock() *refreshTokenRepoMock {
+	return &refreshTokenRepoMock{}
+}
+
+func (m *refreshTokenRepoMock) CreateRefreshToken(ctx context.Context, payload models.RepoCreateRefreshTokenModel) (result models.RepoResRefreshTokenModel, err error) {
+	args := m.Called(ctx, payload)
+	return args.Get(0).(models.RepoResRefreshTokenModel), args.Error(1)
+}
+
+func (m *refreshTokenRepoMock) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (result models.RepoResRefreshTokenModel, err error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(models.RepoResRefreshTokenModel), args.Error(1)
+}
+
+func (m *refreshTokenRepoMock) UseRefreshToken(ctx context.Context, id string, usedAt time.Time) (result models.RepoResRefreshTokenModel, err error) {
+	args := m.Called(ctx, id, usedAt)
+	return args.Get(0).(models.RepoResRefreshTokenModel), args.Error(1)
+}
+
+func (m *refreshTokenRepoMock) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	args := m.Called(ctx, familyID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *refreshTokenRepoMock) RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error {
+	args := m.Called(ctx, userID, revokedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type refreshTokenRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewRefreshTokenRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) RefreshTokenRepository {
+	return &refreshTokenRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *refreshTokenRepo) CreateRefreshToken(ctx context.Context, payload models.RepoCreateRefreshTokenModel) (result models.RepoResRefreshTokenModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "CreateRefreshToken")
+	defer cancel()
+
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return models.RepoResRefreshTokenModel(payload), nil
+}
+
+func (r *refreshTokenRepo) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (result models.RepoResRefreshTokenModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "GetRefreshTokenByHash")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"tokenHash": tokenHash})
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrRefreshTokenNotFound)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *refreshTokenRepo) UseRefreshToken(ctx context.Context, id string, usedAt time.Time) (result models.RepoResRefreshTokenModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "UseRefreshToken")
+	defer cancel()
+
+	after := options.After
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+	}
+
+	filter := bson.M{"id": id, "usedAt": nil, "revokedAt": nil}
+	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"usedAt": usedAt}}, &opt)
+	if res.Err() != nil {
+		return result, mongoError(res.Err(), ErrRefreshTokenUsed)
+	}
+
+	if err := res.Decode(&result); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	return result, nil
+}
+
+func (r *refreshTokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "RevokeRefreshTokenFamily")
+	defer cancel()
+
+	filter := bson.M{"familyId": familyID, "revokedAt": nil}
+	_, err := r.db.Collection(r.collection).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revokedAt": revokedAt}})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "RevokeUserRefreshTokens")
+	defer cancel()
+
+	filter := bson.M{"userId": userID, "revokedAt": nil}
+	_, err := r.db.Collection(r.collection).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revokedAt": revokedAt}})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
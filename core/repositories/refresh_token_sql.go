@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+type refreshTokenSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewRefreshTokenSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) RefreshTokenRepository {
+	return &refreshTokenSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const refreshTokenSQLColumns = `id, family_id, user_id, token_hash, expires_at, created_at, used_at, revoked_at`
+
+func scanRefreshToken(row *sql.Row) (result models.RepoResRefreshTokenModel, err error) {
+	err = row.Scan(&result.ID, &result.FamilyID, &result.UserID, &result.TokenHash,
+		sqlTime{dst: &result.ExpiresAt}, sqlTime{dst: &result.CreateAt}, sqlNullTime{dst: &result.UsedAt}, sqlNullTime{dst: &result.RevokedAt})
+	return result, err
+}
+
+func (r *refreshTokenSQLRepo) CreateRefreshToken(ctx context.Context, payload models.RepoCreateRefreshTokenModel) (result models.RepoResRefreshTokenModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "CreateRefreshToken")
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบ token ที่หมดอายุตอนสร้างใหม่
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM refresh_tokens WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	query := `INSERT INTO refresh_tokens (` + refreshTokenSQLColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.FamilyID, payload.UserID, payload.TokenHash,
+		r.dialect.timeValue(payload.ExpiresAt), r.dialect.timeValue(payload.CreateAt), r.dialect.nullTimeValue(payload.UsedAt), r.dialect.nullTimeValue(payload.RevokedAt))
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	return models.RepoResRefreshTokenModel(payload), nil
+}
+
+func (r *refreshTokenSQLRepo) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (result models.RepoResRefreshTokenModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "GetRefreshTokenByHash")
+	defer cancel()
+
+	query := `SELECT ` + refreshTokenSQLColumns + ` FROM refresh_tokens WHERE token_hash = ?`
+	result, err = scanRefreshToken(r.db.QueryRowContext(ctx, r.dialect.rebind(query), tokenHash))
+	if err != nil {
+		return result, sqlError(err, ErrRefreshTokenNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *refreshTokenSQLRepo) UseRefreshToken(ctx context.Context, id string, usedAt time.Time) (result models.RepoResRefreshTokenModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "UseRefreshToken")
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(usedAt), id)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	if updated == 0 {
+		return result, ErrRefreshTokenUsed
+	}
+
+	query = `SELECT ` + refreshTokenSQLColumns + ` FROM refresh_tokens WHERE id = ?`
+	result, err = scanRefreshToken(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err != nil {
+		return result, sqlError(err, ErrRefreshTokenNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *refreshTokenSQLRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "RevokeRefreshTokenFamily")
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(revokedAt), familyID)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	return nil
+}
+
+func (r *refreshTokenSQLRepo) RevokeUserRefreshTokens(ctx context.Context, userID string, revokedAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "refresh_token", "RevokeUserRefreshTokens")
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(revokedAt), userID)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import "context"
+
+import "time"
+
+type RevokedTokenRepository interface {
+	// เพิกถอน token ตาม jti จนถึงเวลาหมดอายุของ token
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// เพิกถอนทุก token ของ user ที่ออกก่อน revokedAt เก็บไว้จนถึง expiresAt
+	RevokeUserTokens(ctx context.Context, userID string, revokedAt time.Time, expiresAt time.Time) error
+
+	IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (result bool, err error)
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรันเครื่องเดียว
+type revokedTokenMemory struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+	users  map[string][]revokedUser
+}
+
+type revokedUser struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+func NewRevokedTokenMemoryRepository() RevokedTokenRepository {
+	return &revokedTokenMemory{
+		tokens: map[string]time.Time{},
+		users:  map[string][]revokedUser{},
+	}
+}
+
+func (r *revokedTokenMemory) RevokeToken(_ context.Context, tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (r *revokedTokenMemory) RevokeUserTokens(_ context.Context, userID string, revokedAt time.Time, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[userID] = append(r.users[userID], revokedUser{revokedAt: revokedAt, expiresAt: expiresAt})
+	return nil
+}
+
+func (r *revokedTokenMemory) IsTokenRevoked(_ context.Context, tokenID string, userID string, issuedAt time.Time) (result bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := r.tokens[tokenID]; ok {
+		if now.Before(expiresAt) {
+			return true, nil
+		}
+		delete(r.tokens, tokenID)
+	}
+
+	users, ok := r.users[userID]
+	if !ok {
+		return false, nil
+	}
+	active := users[:0]
+	for _, user := range users {
+		if now.After(user.expiresAt) {
+			continue
+		}
+		active = append(active, user)
+		if !user.revokedAt.Before(issuedAt) {
+			result = true
+		}
+	}
+	if len(active) == 0 {
+		delete(r.users, userID)
+	} else {
+		r.users[userID] = active
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type revokedTokenRepoMock struct {
+	mock.Mock
+}
+
+func NewRevokedTokenRepositoryMock() *revokedTokenRepoMock {
+	return &revokedTokenRepoMock{}
+}
+
+func (m *revokedTokenRepoMock) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := m.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *revokedTokenRepoMock) RevokeUserTokens(ctx context.Context, userID string, revokedAt time.Time, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, revokedAt, expiresAt)
+	return args.Error(0)
+}
+
+func (m *revokedTokenRepoMock) IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (result bool, err error) {
+	args := m.Called(ctx, tokenID, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type revokedTokenRepo struct {
+	db         *mongo.Database
+	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
+}
+
+func NewRevokedTokenRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) RevokedTokenRepository {
+	return &revokedTokenRepo{
+		db:         db,
+		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+func (r *revokedTokenRepo) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "revoked_token", "RevokeToken")
+	defer cancel()
+
+	_, err := r.db.Collection(r.collection).InsertOne(ctx, models.RepoRevokedTokenModel{
+		TokenID:   tokenID,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
+
+func (r *revokedTokenRepo) RevokeUserTokens(ctx context.Context, userID string, revokedAt time.Time, expiresAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "revoked_token", "RevokeUserTokens")
+	defer cancel()
+
+	_, err := r.db.Collection(r.collection).InsertOne(ctx, models.RepoRevokedTokenModel{
+		UserID:    userID,
+		RevokedAt: revokedAt,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return mongoError(err, nil)
+	}
+
+	return nil
+}
+
+func (r *revokedTokenRepo) IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (result bool, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "revoked_token", "IsTokenRevoked")
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"jti": tokenID},
+		bson.M{"userId": userID, "revokedAt": bson.M{"$gte": issuedAt}},
+	}}
+	count, err := r.db.Collection(r.collection).CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, mongoError(err, nil)
+	}
+
+	return count > 0, nil
+}
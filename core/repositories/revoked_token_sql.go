@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+type revokedTokenSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewRevokedTokenSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) RevokedTokenRepository {
+	return &revokedTokenSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+func (r *revokedTokenSQLRepo) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return r.insert(ctx, "RevokeToken", sql.NullString{String: tokenID, Valid: true}, sql.NullString{}, time.Now(), expiresAt)
+}
+
+func (r *revokedTokenSQLRepo) RevokeUserTokens(ctx context.Context, userID string, revokedAt time.Time, expiresAt time.Time) error {
+	return r.insert(ctx, "RevokeUserTokens", sql.NullString{}, sql.NullString{String: userID, Valid: true}, revokedAt, expiresAt)
+}
+
+func (r *revokedTokenSQLRepo) insert(ctx context.Context, method string, tokenID sql.NullString, userID sql.NullString, revokedAt time.Time, expiresAt time.Time) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "revoked_token", method)
+	defer cancel()
+
+	// NOTE ไม่มี TTL index เหมือน mongo จึงลบรายการที่หมดอายุตอนเพิ่มใหม่
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM revoked_tokens WHERE expires_at < ?`), r.dialect.timeValue(time.Now()))
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	query := `INSERT INTO revoked_tokens (jti, user_id, revoked_at, expires_at) VALUES (?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), tokenID, userID, r.dialect.timeValue(revokedAt), r.dialect.timeValue(expiresAt))
+	if err != nil {
+		return sqlError(err, nil)
+	}
+
+	return nil
+}
+
+func (r *revokedTokenSQLRepo) IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (result bool, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "revoked_token", "IsTokenRevoked")
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM revoked_tokens WHERE expires_at >= ? AND (jti = ? OR (user_id = ? AND revoked_at >= ?))`
+	var count int64
+	err = r.db.QueryRowContext(ctx, r.dialect.rebind(query), r.dialect.timeValue(time.Now()), tokenID, userID, r.dialect.timeValue(issuedAt)).Scan(&count)
+	if err != nil {
+		return false, sqlError(err, nil)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"7solutions/backend/common/apperror"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQL dialect ที่รองรับ (ค่าเดียวกับ DB_DRIVER)
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+type sqlDialect string
+
+// NOTE query เขียนด้วย ? แล้วแปลงเป็น $1, $2, ... สำหรับ postgres
+func (d sqlDialect) rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// เวลาเก็บเป็น UTC ละเอียดระดับ microsecond (sqlite เก็บเป็นตัวเลข unix micro)
+func (d sqlDialect) timeValue(t time.Time) interface{} {
+	t = t.UTC().Truncate(time.Microsecond)
+	if d == DialectSQLite {
+		return t.UnixMicro()
+	}
+	return t
+}
+
+func (d sqlDialect) nullTimeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return d.timeValue(*t)
+}
+
+// เรียง/เทียบข้อความแบบ byte ให้ผลเหมือนกันทุก backend
+func (d sqlDialect) binary(column string) string {
+	if d == DialectPostgres {
+		return column + ` COLLATE "C"`
+	}
+	return column
+}
+
+// NOTE sqlite ต้องมี LIMIT ก่อน OFFSET เสมอ
+func (d sqlDialect) noLimit() string {
+	if d == DialectPostgres {
+		return "ALL"
+	}
+	return "-1"
+}
+
+func (d sqlDialect) isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		return liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+// แปลง error ของ database/sql เป็น domain error (ไม่พบข้อมูล -> notFound, อื่นๆ -> internal)
+func sqlError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	if notFound != nil && errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	if isContextError(err) {
+		return apperror.Timeout(err)
+	}
+	return apperror.Internal(err)
+}
+
+// ใช้ scan คอลัมน์เวลาได้ทั้ง TIMESTAMPTZ (postgres) และ INTEGER (sqlite)
+type sqlTime struct {
+	dst *time.Time
+}
+
+func (t sqlTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*t.dst = v.UTC()
+	case int64:
+		*t.dst = time.UnixMicro(v).UTC()
+	default:
+		return fmt.Errorf("unsupported time value %T", value)
+	}
+	return nil
+}
+
+type sqlNullTime struct {
+	dst **time.Time
+}
+
+func (t sqlNullTime) Scan(value interface{}) error {
+	if value == nil {
+		*t.dst = nil
+		return nil
+	}
+	var v time.Time
+	if err := (sqlTime{dst: &v}).Scan(value); err != nil {
+		return err
+	}
+	*t.dst = &v
+	return nil
+}
+
+// รันไฟล์ใน migrations/<dialect> ที่ยังไม่เคยรัน ตามลำดับชื่อไฟล์
+func MigrateSQL(db *sql.DB, dialect string, logger *slog.Logger) error {
+	if dialect != DialectPostgres && dialect != DialectSQLite {
+		return fmt.Errorf("unsupported sql dialect %q", dialect)
+	}
+	d := sqlDialect(dialect)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)`); err != nil {
+		return err
+	}
+
+	files, err := fs.Glob(migrations, "migrations/"+dialect+"/*.sql")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		version := strings.TrimSuffix(path.Base(file), ".sql")
+
+		var count int
+		if err := db.QueryRowContext(ctx, d.rebind(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`), version).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		script, err := migrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, d.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		logger.Info("applied migration", "dialect", dialect, "version", version)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// operation ที่ไม่ได้ระบุใน Timeouts ใช้ค่านี้ (ถ้ามี)
+const TimeoutDefault = "*"
+
+// เวลาสูงสุดของแต่ละ operation key คือ "<repository>.<Method>" (เช่น "user.CountUser"), "<repository>" หรือ TimeoutDefault
+// NOTE ไม่มีค่าที่ตรงเลย (หรือเป็น 0) = ไม่จำกัดเวลา แต่ยังถูกยกเลิกตาม ctx ของ request
+type Timeouts map[string]time.Duration
+
+func (t Timeouts) timeout(repository string, method string) time.Duration {
+	if timeout, ok := t[repository+"."+method]; ok {
+		return timeout
+	}
+	if timeout, ok := t[repository]; ok {
+		return timeout
+	}
+	return t[TimeoutDefault]
+}
+
+// context ของคำสั่งฐานข้อมูลหนึ่ง operation ต่อจาก ctx ของ request
+// (ติดชื่อ repository/method ไว้ให้ mongo monitor ด้วย)
+// NOTE cancel log warn เมื่อ operation เกินเวลาของตัวเอง (ไม่ใช่ request ถูกยกเลิก) เพื่อรู้ว่าคำสั่งไหนช้า
+func (t Timeouts) operation(ctx context.Context, logger *slog.Logger, repository string, method string) (context.Context, context.CancelFunc) {
+	parent := ctx
+	ctx = mongoOperation(ctx, repository, method)
+	timeout := t.timeout(repository, method)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+			logger.Warn("database operation timed out", "repository", repository, "method", method, "timeout", timeout.String())
+		}
+		cancel()
+	}
+}
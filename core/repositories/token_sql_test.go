@@ -0,0 +1,409 @@
+package repositories_test
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTimeouts = repositories.Timeouts{repositories.TimeoutDefault: 5 * time.Second}
+
+var testLogger = logger.Discard()
+
+func newSQLiteDatabase(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "tokens.db"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, repositories.MigrateSQL(db, repositories.DialectSQLite, logger.Discard()))
+	// NOTE รันซ้ำต้องไม่ error
+	require.NoError(t, repositories.MigrateSQL(db, repositories.DialectSQLite, logger.Discard()))
+	return db
+}
+
+func Test_RefreshTokenSQLRepository(t *testing.T) {
+	repo := repositories.NewRefreshTokenSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.CreateRefreshToken(context.Background(), models.RepoCreateRefreshTokenModel{
+		ID: "t1", FamilyID: "f1", UserID: "u1", TokenHash: "h1", ExpiresAt: now.Add(time.Hour), CreateAt: now,
+	})
+	require.NoError(t, err)
+
+	token, err := repo.GetRefreshTokenByHash(context.Background(), "h1")
+	require.NoError(t, err)
+	assert.Equal(t, "f1", token.FamilyID)
+	assert.Nil(t, token.UsedAt)
+
+	used, err := repo.UseRefreshToken(context.Background(), "t1", now)
+	require.NoError(t, err)
+	assert.NotNil(t, used.UsedAt)
+
+	_, err = repo.UseRefreshToken(context.Background(), "t1", now)
+	assert.ErrorIs(t, err, repositories.ErrRefreshTokenUsed)
+
+	require.NoError(t, repo.RevokeRefreshTokenFamily(context.Background(), "f1", now))
+	token, err = repo.GetRefreshTokenByHash(context.Background(), "h1")
+	require.NoError(t, err)
+	assert.NotNil(t, token.RevokedAt)
+
+	_, err = repo.GetRefreshTokenByHash(context.Background(), "missing")
+	assert.ErrorIs(t, err, repositories.ErrRefreshTokenNotFound)
+}
+
+func Test_RevokedTokenSQLRepository(t *testing.T) {
+	repo := repositories.NewRevokedTokenSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	require.NoError(t, repo.RevokeToken(context.Background(), "jti-1", now.Add(time.Hour)))
+	revoked, err := repo.IsTokenRevoked(context.Background(), "jti-1", "u1", now)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	require.NoError(t, repo.RevokeUserTokens(context.Background(), "u2", now, now.Add(time.Hour)))
+	revoked, err = repo.IsTokenRevoked(context.Background(), "jti-2", "u2", now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// NOTE token ที่ออกหลังเวลาที่เพิกถอนยังใช้ได้
+	revoked, err = repo.IsTokenRevoked(context.Background(), "jti-3", "u2", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func Test_PasswordResetSQLRepository(t *testing.T) {
+	repo := repositories.NewPasswordResetSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	for _, id := range []string{"r1", "r2"} {
+		_, err := repo.CreatePasswordReset(context.Background(), models.RepoCreatePasswordResetModel{
+			ID: id, UserID: "u1", TokenHash: "h" + id, ExpiresAt: now.Add(time.Hour), CreateAt: now,
+		})
+		require.NoError(t, err)
+	}
+
+	token, err := repo.GetPasswordResetByHash(context.Background(), "hr1")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", token.UserID)
+	assert.Nil(t, token.UsedAt)
+
+	used, err := repo.UsePasswordReset(context.Background(), "r1", now)
+	require.NoError(t, err)
+	assert.NotNil(t, used.UsedAt)
+
+	_, err = repo.UsePasswordReset(context.Background(), "r1", now)
+	assert.ErrorIs(t, err, repositories.ErrPasswordResetUsed)
+
+	// NOTE token อื่นของ user ใช้ไม่ได้อีก
+	require.NoError(t, repo.UseUserPasswordResets(context.Background(), "u1", now))
+	_, err = repo.UsePasswordReset(context.Background(), "r2", now)
+	assert.ErrorIs(t, err, repositories.ErrPasswordResetUsed)
+
+	_, err = repo.GetPasswordResetByHash(context.Background(), "missing")
+	assert.ErrorIs(t, err, repositories.ErrPasswordResetNotFound)
+}
+
+func Test_EmailVerificationSQLRepository(t *testing.T) {
+	repo := repositories.NewEmailVerificationSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.CreateEmailVerification(context.Background(), models.RepoCreateEmailVerificationModel{
+		ID: "v1", UserID: "u1", Email: "bank@test.com", TokenHash: "h1", ExpiresAt: now.Add(time.Hour), CreateAt: now,
+	})
+	require.NoError(t, err)
+
+	token, err := repo.GetEmailVerificationByHash(context.Background(), "h1")
+	require.NoError(t, err)
+	assert.Equal(t, "bank@test.com", token.Email)
+	assert.Nil(t, token.UsedAt)
+
+	require.NoError(t, repo.UseUserEmailVerifications(context.Background(), "u1", now))
+	_, err = repo.UseEmailVerification(context.Background(), "v1", now)
+	assert.ErrorIs(t, err, repositories.ErrEmailVerificationUsed)
+
+	_, err = repo.GetEmailVerificationByHash(context.Background(), "missing")
+	assert.ErrorIs(t, err, repositories.ErrEmailVerificationNotFound)
+}
+
+func Test_LoginAttemptSQLRepository(t *testing.T) {
+	repo := repositories.NewLoginAttemptSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.GetLoginAttempt(context.Background(), "email:bank@test.com")
+	assert.ErrorIs(t, err, repositories.ErrLoginAttemptNotFound)
+	assert.ErrorIs(t, repo.LockLoginAttempt(context.Background(), "email:bank@test.com", now.Add(time.Hour)), repositories.ErrLoginAttemptNotFound)
+
+	for i := 1; i <= 3; i++ {
+		attempt, err := repo.AddLoginFailure(context.Background(), "email:bank@test.com", now, now.Add(-time.Minute), now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, i, attempt.Failures)
+	}
+
+	require.NoError(t, repo.LockLoginAttempt(context.Background(), "email:bank@test.com", now.Add(time.Hour)))
+	attempt, err := repo.GetLoginAttempt(context.Background(), "email:bank@test.com")
+	require.NoError(t, err)
+	require.NotNil(t, attempt.LockedUntil)
+	assert.WithinDuration(t, now.Add(time.Hour), *attempt.LockedUntil, time.Millisecond)
+	// NOTE เก็บไว้อย่างน้อยจนปลดล็อก
+	assert.WithinDuration(t, now.Add(time.Hour), attempt.ExpiresAt, time.Millisecond)
+
+	// NOTE ครั้งล่าสุดเก่ากว่า window เริ่มนับใหม่
+	attempt, err = repo.AddLoginFailure(context.Background(), "email:bank@test.com", now.Add(time.Minute), now.Add(time.Second), now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+
+	require.NoError(t, repo.ResetLoginAttempt(context.Background(), "email:bank@test.com"))
+	_, err = repo.GetLoginAttempt(context.Background(), "email:bank@test.com")
+	assert.ErrorIs(t, err, repositories.ErrLoginAttemptNotFound)
+}
+
+func Test_RateLimitSQLRepository(t *testing.T) {
+	repo := repositories.NewRateLimitSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	window := time.Minute
+	start := time.Now().Truncate(window)
+
+	for i := int64(1); i <= 3; i++ {
+		counter, err := repo.IncrementRateLimit(context.Background(), "ip:1", start, window)
+		require.NoError(t, err)
+		assert.Equal(t, i, counter.Count)
+		assert.Zero(t, counter.PreviousCount)
+	}
+
+	// NOTE หน้าต่างถัดไปเห็นจำนวนของหน้าต่างก่อนหน้า
+	counter, err := repo.IncrementRateLimit(context.Background(), "ip:1", start.Add(window), window)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), counter.Count)
+	assert.Equal(t, int64(3), counter.PreviousCount)
+
+	counter, err = repo.IncrementRateLimit(context.Background(), "ip:2", start, window)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), counter.Count)
+}
+
+func Test_MFASQLRepository(t *testing.T) {
+	repo := repositories.NewMFASQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.GetMFAByUserID(context.Background(), "u1")
+	assert.ErrorIs(t, err, repositories.ErrMFANotFound)
+
+	// NOTE ลงทะเบียนซ้ำก่อนยืนยันแทนที่ secret เดิม
+	for _, secret := range []string{"s1", "s2"} {
+		mfa, err := repo.CreateMFA(context.Background(), models.RepoCreateMFAModel{UserID: "u1", Secret: secret, CreateAt: now})
+		require.NoError(t, err)
+		assert.Equal(t, secret, mfa.Secret)
+		assert.False(t, mfa.Enabled)
+	}
+
+	mfa, err := repo.EnableMFA(context.Background(), "u1", []string{"c1", "c2"}, now)
+	require.NoError(t, err)
+	assert.True(t, mfa.Enabled)
+	assert.Equal(t, []string{"c1", "c2"}, mfa.RecoveryCodes)
+	require.NotNil(t, mfa.EnabledAt)
+
+	_, err = repo.CreateMFA(context.Background(), models.RepoCreateMFAModel{UserID: "u1", Secret: "s3", CreateAt: now})
+	assert.ErrorIs(t, err, repositories.ErrMFAAlreadyEnabled)
+
+	require.NoError(t, repo.UseMFAStep(context.Background(), "u1", 10))
+	assert.ErrorIs(t, repo.UseMFAStep(context.Background(), "u1", 10), repositories.ErrMFACodeUsed)
+	require.NoError(t, repo.UseMFAStep(context.Background(), "u1", 11))
+
+	require.NoError(t, repo.UseMFARecoveryCode(context.Background(), "u1", "c1"))
+	assert.ErrorIs(t, repo.UseMFARecoveryCode(context.Background(), "u1", "c1"), repositories.ErrMFARecoveryCodeNotFound)
+
+	require.NoError(t, repo.SetMFAChallenge(context.Background(), "u1", "h1", now.Add(time.Minute)))
+	mfa, err = repo.GetMFAByChallengeHash(context.Background(), "h1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c2"}, mfa.RecoveryCodes)
+	assert.Equal(t, int64(11), mfa.LastStep)
+	require.NoError(t, repo.UseMFAChallenge(context.Background(), "u1", "h1"))
+	assert.ErrorIs(t, repo.UseMFAChallenge(context.Background(), "u1", "h1"), repositories.ErrMFAChallengeNotFound)
+	_, err = repo.GetMFAByChallengeHash(context.Background(), "h1")
+	assert.ErrorIs(t, err, repositories.ErrMFAChallengeNotFound)
+
+	require.NoError(t, repo.DeleteMFA(context.Background(), "u1"))
+	assert.ErrorIs(t, repo.DeleteMFA(context.Background(), "u1"), repositories.ErrMFANotFound)
+}
+
+func Test_APIKeySQLRepository(t *testing.T) {
+	repo := repositories.NewAPIKeySQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	for i, id := range []string{"k1", "k2"} {
+		_, err := repo.CreateAPIKey(context.Background(), models.RepoCreateAPIKeyModel{
+			ID: id, UserID: "u1", Name: "batch", Prefix: "p" + id, KeyHash: "h" + id,
+			Permissions: []string{"user:list", "user:read"}, ExpiresAt: &expiresAt, CreateAt: now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	key, err := repo.GetAPIKeyByPrefix(context.Background(), "pk1")
+	require.NoError(t, err)
+	assert.Equal(t, "hk1", key.KeyHash)
+	assert.Equal(t, []string{"user:list", "user:read"}, key.Permissions)
+	require.NotNil(t, key.ExpiresAt)
+	assert.Nil(t, key.LastUsedAt)
+
+	keys, err := repo.GetAPIKeysByUserID(context.Background(), "u1")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "k2", keys[0].ID)
+
+	require.NoError(t, repo.UpdateAPIKeyLastUsed(context.Background(), "k1", now))
+	assert.ErrorIs(t, repo.RevokeAPIKey(context.Background(), "u2", "k1", now), repositories.ErrAPIKeyNotFound)
+	require.NoError(t, repo.RevokeAPIKey(context.Background(), "u1", "k1", now))
+	assert.ErrorIs(t, repo.RevokeAPIKey(context.Background(), "u1", "k1", now), repositories.ErrAPIKeyNotFound)
+
+	key, err = repo.GetAPIKeyByPrefix(context.Background(), "pk1")
+	require.NoError(t, err)
+	assert.NotNil(t, key.LastUsedAt)
+	assert.NotNil(t, key.RevokedAt)
+
+	_, err = repo.GetAPIKeyByPrefix(context.Background(), "missing")
+	assert.ErrorIs(t, err, repositories.ErrAPIKeyNotFound)
+}
+
+func Test_OIDCStateSQLRepository(t *testing.T) {
+	repo := repositories.NewOIDCStateSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.CreateOIDCState(context.Background(), models.RepoCreateOIDCStateModel{
+		StateHash: "h1", Provider: "google", Nonce: "n1", CodeVerifier: "v1", ExpiresAt: now.Add(time.Minute), CreateAt: now,
+	})
+	require.NoError(t, err)
+
+	state, err := repo.UseOIDCState(context.Background(), "h1")
+	require.NoError(t, err)
+	assert.Equal(t, "google", state.Provider)
+	assert.Equal(t, "v1", state.CodeVerifier)
+	assert.WithinDuration(t, now.Add(time.Minute), state.ExpiresAt, time.Millisecond)
+
+	// NOTE ใช้ได้ครั้งเดียว
+	_, err = repo.UseOIDCState(context.Background(), "h1")
+	assert.ErrorIs(t, err, repositories.ErrOIDCStateNotFound)
+}
+
+func Test_IdentitySQLRepository(t *testing.T) {
+	repo := repositories.NewIdentitySQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.CreateIdentity(context.Background(), models.RepoCreateIdentityModel{ID: "i1", UserID: "u1", Provider: "google", Subject: "s1", Email: "a@example.com", CreateAt: now})
+	require.NoError(t, err)
+	_, err = repo.CreateIdentity(context.Background(), models.RepoCreateIdentityModel{ID: "i2", UserID: "u2", Provider: "google", Subject: "s1", CreateAt: now})
+	assert.ErrorIs(t, err, repositories.ErrIdentityExists)
+	_, err = repo.CreateIdentity(context.Background(), models.RepoCreateIdentityModel{ID: "i3", UserID: "u2", Provider: "keycloak", Subject: "s1", CreateAt: now})
+	require.NoError(t, err)
+
+	identity, err := repo.GetIdentity(context.Background(), "google", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", identity.UserID)
+	assert.Equal(t, "a@example.com", identity.Email)
+
+	require.NoError(t, repo.DeleteIdentity(context.Background(), "i1"))
+	assert.ErrorIs(t, repo.DeleteIdentity(context.Background(), "i1"), repositories.ErrIdentityNotFound)
+	_, err = repo.GetIdentity(context.Background(), "google", "s1")
+	assert.ErrorIs(t, err, repositories.ErrIdentityNotFound)
+}
+
+func Test_OAuthClientSQLRepository(t *testing.T) {
+	repo := repositories.NewOAuthClientSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	for i, id := range []string{"c1", "c2"} {
+		_, err := repo.CreateOAuthClient(context.Background(), models.RepoCreateOAuthClientModel{
+			ID: id, Name: "app", SecretHash: "h" + id, RedirectURIs: []string{"https://app.test/cb?a=1,2", "com.app:/cb"},
+			Scopes: []string{"user:read"}, GrantTypes: []string{"authorization_code", "client_credentials"}, CreateAt: now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	client, err := repo.GetOAuthClient(context.Background(), "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "hc1", client.SecretHash)
+	assert.Equal(t, []string{"https://app.test/cb?a=1,2", "com.app:/cb"}, client.RedirectURIs)
+	assert.Equal(t, []string{"user:read"}, client.Scopes)
+	assert.Equal(t, []string{"authorization_code", "client_credentials"}, client.GrantTypes)
+
+	clients, err := repo.GetOAuthClients(context.Background())
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+	assert.Equal(t, "c2", clients[0].ID)
+
+	require.NoError(t, repo.DeleteOAuthClient(context.Background(), "c1"))
+	assert.ErrorIs(t, repo.DeleteOAuthClient(context.Background(), "c1"), repositories.ErrOAuthClientNotFound)
+	_, err = repo.GetOAuthClient(context.Background(), "c1")
+	assert.ErrorIs(t, err, repositories.ErrOAuthClientNotFound)
+}
+
+func Test_OAuthCodeSQLRepository(t *testing.T) {
+	repo := repositories.NewOAuthCodeSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, testTimeouts, testLogger)
+	now := time.Now()
+
+	_, err := repo.CreateOAuthCode(context.Background(), models.RepoCreateOAuthCodeModel{
+		CodeHash: "h1", ClientID: "c1", UserID: "u1", RedirectURI: "https://app.test/cb", Scopes: []string{"user:read"},
+		CodeChallenge: "cc", ExpiresAt: now.Add(time.Minute), CreateAt: now,
+	})
+	require.NoError(t, err)
+
+	code, err := repo.UseOAuthCode(context.Background(), "h1")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", code.UserID)
+	assert.Equal(t, []string{"user:read"}, code.Scopes)
+	assert.Equal(t, "cc", code.CodeChallenge)
+	assert.WithinDuration(t, now.Add(time.Minute), code.ExpiresAt, time.Millisecond)
+
+	// NOTE ใช้ได้ครั้งเดียว
+	_, err = repo.UseOAuthCode(context.Background(), "h1")
+	assert.ErrorIs(t, err, repositories.ErrOAuthCodeNotFound)
+}
+
+func Test_SQLRepositoryTimeouts(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		Name     string
+		Ctx      context.Context
+		Timeouts repositories.Timeouts
+		Timeout  bool
+		Logged   bool
+	}{
+		{Name: "in time", Ctx: context.Background(), Timeouts: testTimeouts},
+		{Name: "request cancelled", Ctx: cancelled, Timeouts: testTimeouts, Timeout: true},
+		{Name: "method timeout", Ctx: context.Background(), Timeouts: repositories.Timeouts{"rate_limit.IncrementRateLimit": time.Nanosecond}, Timeout: true, Logged: true},
+		{Name: "repository timeout", Ctx: context.Background(), Timeouts: repositories.Timeouts{"rate_limit": time.Nanosecond, "*": time.Minute}, Timeout: true, Logged: true},
+		{Name: "method overrides repository", Ctx: context.Background(), Timeouts: repositories.Timeouts{"rate_limit.IncrementRateLimit": time.Minute, "rate_limit": time.Nanosecond}},
+		{Name: "zero is unlimited", Ctx: context.Background(), Timeouts: repositories.Timeouts{"rate_limit": 0, "*": time.Nanosecond}},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			repo := repositories.NewRateLimitSQLRepository(newSQLiteDatabase(t), repositories.DialectSQLite, c.Timeouts, logger.New(buf, "production", slog.LevelInfo))
+			_, err := repo.IncrementRateLimit(c.Ctx, "ip:1", time.Now().Truncate(time.Minute), time.Minute)
+			// NOTE log เฉพาะเมื่อเกินเวลาของ operation เอง
+			if c.Logged {
+				assert.Contains(t, buf.String(), `"method":"IncrementRateLimit"`)
+			} else {
+				assert.Empty(t, buf.String())
+			}
+			if c.Timeout {
+				assert.True(t, apperror.Is(err, apperror.KindTimeout), err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
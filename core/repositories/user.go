@@ -1,19 +1,70 @@
 package repositories
 
-import "7solutions/backend/core/models"
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// field ที่ GetUsers เรียงได้
+const (
+	UserSortCreateAt = "createAt"
+	UserSortName     = "name"
+	UserSortEmail    = "email"
+)
 
 type UserRepository interface {
-	CreateUser(payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error)
+	CreateUser(ctx context.Context, payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error)
+
+	GetUserByID(ctx context.Context, id string) (result models.RepoResUserModel, err error)
 
-	GetUserByID(id string) (result models.RepoResUserModel, err error)
+	GetUserByEmail(ctx context.Context, email string) (result models.RepoResUserModel, err error)
 
-	GetUserByEmail(email string) (result models.RepoResUserModel, err error)
+	GetUsers(ctx context.Context, query models.RepoUserQueryModel) (result models.RepoResUserPageModel, err error)
 
-	GetUsers() (result []models.RepoResUserModel, err error)
+	UpdateUser(ctx context.Context, id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error)
 
-	UpdateUser(id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error)
+	DeleteUser(ctx context.Context, id string) error
 
-	DeleteUser(id string) error
+	CountUser(ctx context.Context) (result int64, err error)
+}
+
+// user ที่เพิ่งสร้างจาก payload ของ CreateUser
+func newCreatedUser(payload models.RepoCreateUserModel) models.RepoResUserModel {
+	return models.RepoResUserModel{
+		ID:       payload.ID,
+		Name:     payload.Name,
+		Email:    payload.Email,
+		Password: payload.Password,
+		Role:     payload.Role,
+		CreateAt: payload.CreateAt,
+	}
+}
+
+func ValidUserSort(sortBy string) bool {
+	return sortBy == UserSortCreateAt || sortBy == UserSortName || sortBy == UserSortEmail
+}
+
+// cursor ของ user ตาม field ที่ใช้เรียง
+func NewUserCursor(sortBy string, user models.RepoResUserModel) *models.RepoUserCursorModel {
+	value := user.CreateAt.UTC().Format(time.RFC3339Nano)
+	switch sortBy {
+	case UserSortName:
+		value = user.Name
+	case UserSortEmail:
+		value = user.Email
+	}
+	return &models.RepoUserCursorModel{Value: value, ID: user.ID}
+}
 
-	CountUser() (result int64, err error)
+func userCursorValue(sortBy string, value string) (interface{}, error) {
+	if sortBy != UserSortCreateAt {
+		return value, nil
+	}
+	createAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return createAt, nil
 }
@@ -0,0 +1,297 @@
+package repositories_test
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	_ "modernc.org/sqlite"
+)
+
+// ทุก implementation ของ UserRepository ต้องผ่านชุดนี้เหมือนกัน
+// NOTE postgres / mongo รันเมื่อตั้ง TEST_POSTGRES_DSN / TEST_MONGO_URI
+func Test_UserMemoryRepository(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) repositories.UserRepository {
+		return repositories.NewUserMemoryRepository()
+	})
+}
+
+func Test_UserSQLiteRepository(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) repositories.UserRepository {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "users.db"))
+		require.NoError(t, err)
+		db.SetMaxOpenConns(1)
+		t.Cleanup(func() { db.Close() })
+
+		require.NoError(t, repositories.MigrateSQL(db, repositories.DialectSQLite, logger.Discard()))
+		return repositories.NewUserSQLRepository(db, repositories.DialectSQLite, testTimeouts, testLogger)
+	})
+}
+
+func Test_UserPostgresRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	testUserRepository(t, func(t *testing.T) repositories.UserRepository {
+		db, err := sql.Open("pgx", dsn)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		require.NoError(t, repositories.MigrateSQL(db, repositories.DialectPostgres, logger.Discard()))
+		_, err = db.Exec(`DELETE FROM users`)
+		require.NoError(t, err)
+		return repositories.NewUserSQLRepository(db, repositories.DialectPostgres, testTimeouts, testLogger)
+	})
+}
+
+func Test_UserMongoRepository(t *testing.T) {
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI is not set")
+	}
+	testUserRepository(t, func(t *testing.T) repositories.UserRepository {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+		db := client.Database("conformance_" + uuid.New().String()[:8])
+		t.Cleanup(func() {
+			db.Drop(context.Background())
+			client.Disconnect(context.Background())
+		})
+
+		_, err = db.Collection("users").Indexes().CreateMany(ctx, repositories.UserIndexes)
+		require.NoError(t, err)
+		return repositories.NewUserRepository(db, "users", testTimeouts, testLogger)
+	})
+}
+
+func testUserRepository(t *testing.T, newRepo func(t *testing.T) repositories.UserRepository) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newUser := func(name string, email string, minutes int) models.RepoCreateUserModel {
+		return models.RepoCreateUserModel{
+			ID:       uuid.New().String(),
+			Name:     name,
+			Email:    email,
+			Password: "hash",
+			Role:     models.RoleUser,
+			CreateAt: base.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+	seed := func(t *testing.T, repo repositories.UserRepository, users ...models.RepoCreateUserModel) {
+		for _, user := range users {
+			_, err := repo.CreateUser(ctx, user)
+			require.NoError(t, err)
+		}
+	}
+	ids := func(users []models.RepoResUserModel) []string {
+		result := []string{}
+		for _, user := range users {
+			result = append(result, user.ID)
+		}
+		return result
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("bank", "bank@test.com", 0)
+
+		created, err := repo.CreateUser(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, created.ID)
+		assert.True(t, user.CreateAt.Equal(created.CreateAt))
+
+		byID, err := repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.Name, byID.Name)
+		assert.Equal(t, user.Email, byID.Email)
+		assert.Equal(t, user.Password, byID.Password)
+		assert.Equal(t, user.Role, byID.Role)
+		assert.True(t, user.CreateAt.Equal(byID.CreateAt))
+
+		byEmail, err := repo.GetUserByEmail(ctx, "BANK@test.com")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, byEmail.ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.GetUserByID(ctx, "missing")
+		assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+		_, err = repo.GetUserByEmail(ctx, "missing@test.com")
+		assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+		_, err = repo.UpdateUser(ctx, "missing", models.RepoUpdateUserModel{Name: "x"})
+		assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+		_, err = repo.UpdateUser(ctx, "missing", models.RepoUpdateUserModel{})
+		assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+		assert.ErrorIs(t, repo.DeleteUser(ctx, "missing"), repositories.ErrUserNotFound)
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("bank", "bank@test.com", 0), newUser("other", "other@test.com", 1))
+
+		_, err := repo.CreateUser(ctx, newUser("bank", "bank@test.com", 2))
+		assert.ErrorIs(t, err, repositories.ErrUserEmailExists)
+		_, err = repo.CreateUser(ctx, newUser("bank", "Bank@Test.com", 2))
+		assert.ErrorIs(t, err, repositories.ErrUserEmailExists)
+
+		other, err := repo.GetUserByEmail(ctx, "other@test.com")
+		require.NoError(t, err)
+		_, err = repo.UpdateUser(ctx, other.ID, models.RepoUpdateUserModel{Email: "bank@test.com"})
+		assert.ErrorIs(t, err, repositories.ErrUserEmailExists)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("bank", "bank@test.com", 0)
+		seed(t, repo, user)
+
+		// NOTE ไม่มี field ให้แก้ ต้องคืนข้อมูลเดิมเหมือนกันทุก backend
+		updated, err := repo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{})
+		require.NoError(t, err)
+		assert.Equal(t, "bank", updated.Name)
+		assert.Equal(t, "bank@test.com", updated.Email)
+
+		updated, err = repo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{Name: "new name"})
+		require.NoError(t, err)
+		assert.Equal(t, "new name", updated.Name)
+		assert.Equal(t, "bank@test.com", updated.Email)
+		assert.Equal(t, models.RoleUser, updated.Role)
+
+		updated, err = repo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{Email: "new@test.com", Role: models.RoleAdmin})
+		require.NoError(t, err)
+		assert.Equal(t, "new name", updated.Name)
+		assert.Equal(t, "new@test.com", updated.Email)
+		assert.Equal(t, models.RoleAdmin, updated.Role)
+		assert.Nil(t, updated.LastLoginAt)
+
+		lastLoginAt := base.Add(time.Hour)
+		_, err = repo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{LastLoginAt: &lastLoginAt})
+		require.NoError(t, err)
+		updated, err = repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updated.LastLoginAt)
+		assert.True(t, lastLoginAt.Equal(*updated.LastLoginAt))
+		assert.Equal(t, "new name", updated.Name)
+
+		updated, err = repo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{Password: "new hash"})
+		require.NoError(t, err)
+		assert.Equal(t, "new hash", updated.Password)
+		assert.Equal(t, "new@test.com", updated.Email)
+		assert.False(t, updated.EmailVerified)
+
+		for _, verified := range []bool{true, false} {
+			_, err = repo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{EmailVerified: &verified})
+			require.NoError(t, err)
+			updated, err = repo.GetUserByID(ctx, user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, verified, updated.EmailVerified)
+		}
+	})
+
+	t.Run("delete and count", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("bank", "bank@test.com", 0)
+		seed(t, repo, user, newUser("other", "other@test.com", 1))
+
+		count, err := repo.CountUser(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		require.NoError(t, repo.DeleteUser(ctx, user.ID))
+		_, err = repo.GetUserByID(ctx, user.ID)
+		assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+
+		count, err = repo.CountUser(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("filter sort and offset", func(t *testing.T) {
+		repo := newRepo(t)
+		alice := newUser("Alice", "alice@example.com", 0)
+		bob := newUser("bob", "bob@test.com", 10)
+		carol := newUser("Carol 100%", "carol@example.com", 20)
+		dave := newUser("dave_a", "dave@test.com", 30)
+		seed(t, repo, alice, bob, carol, dave)
+
+		from, to := base.Add(10*time.Minute), base.Add(20*time.Minute)
+		cases := []struct {
+			Name   string
+			Query  models.RepoUserQueryModel
+			Output []string
+			Total  int64
+		}{
+			{Name: "default sort", Query: models.RepoUserQueryModel{}, Output: []string{alice.ID, bob.ID, carol.ID, dave.ID}, Total: 4},
+			{Name: "name contains ignore case", Query: models.RepoUserQueryModel{Name: "A"}, Output: []string{alice.ID, carol.ID, dave.ID}, Total: 3},
+			{Name: "name with like wildcard", Query: models.RepoUserQueryModel{Name: "0%"}, Output: []string{carol.ID}, Total: 1},
+			{Name: "name with underscore", Query: models.RepoUserQueryModel{Name: "_"}, Output: []string{dave.ID}, Total: 1},
+			{Name: "email contains", Query: models.RepoUserQueryModel{Email: "@TEST"}, Output: []string{bob.ID, dave.ID}, Total: 2},
+			{Name: "created range", Query: models.RepoUserQueryModel{CreatedFrom: &from, CreatedTo: &to}, Output: []string{bob.ID, carol.ID}, Total: 2},
+			{Name: "sort name binary", Query: models.RepoUserQueryModel{SortBy: repositories.UserSortName}, Output: []string{alice.ID, carol.ID, bob.ID, dave.ID}, Total: 4},
+			{Name: "sort created desc", Query: models.RepoUserQueryModel{SortDesc: true}, Output: []string{dave.ID, carol.ID, bob.ID, alice.ID}, Total: 4},
+			{Name: "limit", Query: models.RepoUserQueryModel{Limit: 2}, Output: []string{alice.ID, bob.ID}, Total: 4},
+			{Name: "offset", Query: models.RepoUserQueryModel{Limit: 2, Offset: 3}, Output: []string{dave.ID}, Total: 4},
+			{Name: "offset without limit", Query: models.RepoUserQueryModel{Offset: 2}, Output: []string{carol.ID, dave.ID}, Total: 4},
+		}
+		for _, c := range cases {
+			t.Run(c.Name, func(t *testing.T) {
+				result, err := repo.GetUsers(ctx, c.Query)
+				require.NoError(t, err)
+				assert.Equal(t, c.Output, ids(result.Users))
+				assert.Equal(t, c.Total, result.Total)
+			})
+		}
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		repo := newRepo(t)
+		users := []models.RepoCreateUserModel{
+			newUser("a", "a@test.com", 0),
+			newUser("b", "b@test.com", 5),
+			newUser("b", "b2@test.com", 5),
+			newUser("c", "c@test.com", 5),
+			newUser("d", "d@test.com", 10),
+		}
+		seed(t, repo, users...)
+
+		for _, sortBy := range []string{repositories.UserSortCreateAt, repositories.UserSortName} {
+			for _, desc := range []bool{false, true} {
+				all, err := repo.GetUsers(ctx, models.RepoUserQueryModel{SortBy: sortBy, SortDesc: desc})
+				require.NoError(t, err)
+				assert.Nil(t, all.Next)
+
+				paged := []string{}
+				query := models.RepoUserQueryModel{SortBy: sortBy, SortDesc: desc, Limit: 2}
+				for page := 0; page < len(users); page++ {
+					result, err := repo.GetUsers(ctx, query)
+					require.NoError(t, err)
+					assert.Equal(t, int64(len(users)), result.Total)
+					paged = append(paged, ids(result.Users)...)
+					if result.Next == nil {
+						break
+					}
+					query.After = result.Next
+				}
+				assert.Equal(t, ids(all.Users), paged, "sort %s desc %v", sortBy, desc)
+			}
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/core/models"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// เก็บใน memory ของ process ใช้สำหรับ test หรือรัน demo (DB_DRIVER=memory)
+type userMemory struct {
+	mu    sync.RWMutex
+	users map[string]models.RepoResUserModel
+}
+
+func NewUserMemoryRepository() UserRepository {
+	return &userMemory{
+		users: map[string]models.RepoResUserModel{},
+	}
+}
+
+func (r *userMemory) CreateUser(_ context.Context, payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[payload.ID]; ok || r.emailTaken(payload.Email, "") {
+		return result, ErrUserEmailExists
+	}
+	result = newCreatedUser(payload)
+	r.users[result.ID] = result
+	return result, nil
+}
+
+func (r *userMemory) GetUserByID(_ context.Context, id string) (result models.RepoResUserModel, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result, ok := r.users[id]
+	if !ok {
+		return result, ErrUserNotFound
+	}
+	return result, nil
+}
+
+func (r *userMemory) GetUserByEmail(_ context.Context, email string) (result models.RepoResUserModel, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return result, ErrUserNotFound
+}
+
+func (r *userMemory) GetUsers(_ context.Context, query models.RepoUserQueryModel) (result models.RepoResUserPageModel, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = UserSortCreateAt
+	}
+
+	users := []models.RepoResUserModel{}
+	for _, user := range r.users {
+		if query.Name != "" && !strings.Contains(strings.ToLower(user.Name), strings.ToLower(query.Name)) {
+			continue
+		}
+		if query.Email != "" && !strings.Contains(strings.ToLower(user.Email), strings.ToLower(query.Email)) {
+			continue
+		}
+		if query.CreatedFrom != nil && user.CreateAt.Before(*query.CreatedFrom) {
+			continue
+		}
+		if query.CreatedTo != nil && user.CreateAt.After(*query.CreatedTo) {
+			continue
+		}
+		users = append(users, user)
+	}
+	result.Total = int64(len(users))
+
+	// NOTE เรียงตาม field แล้วตาม id (เหมือน backend อื่น)
+	compare := func(a models.RepoResUserModel, b models.RepoUserCursorModel) int {
+		var c int
+		if sortBy == UserSortCreateAt {
+			createAt, _ := time.Parse(time.RFC3339Nano, b.Value)
+			c = a.CreateAt.Compare(createAt)
+		} else {
+			c = strings.Compare(NewUserCursor(sortBy, a).Value, b.Value)
+		}
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if query.SortDesc {
+			c = -c
+		}
+		return c
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return compare(users[i], *NewUserCursor(sortBy, users[j])) < 0
+	})
+
+	// NOTE cursor: ต่อจากรายการสุดท้ายของหน้าก่อน
+	if query.After != nil {
+		if _, err := userCursorValue(sortBy, query.After.Value); err != nil {
+			return result, apperror.Validation("CURSOR_INVALID", "cursor invalid")
+		}
+		start := sort.Search(len(users), func(i int) bool {
+			return compare(users[i], *query.After) > 0
+		})
+		users = users[start:]
+	} else if query.Offset > 0 {
+		if query.Offset >= int64(len(users)) {
+			users = users[:0]
+		} else {
+			users = users[query.Offset:]
+		}
+	}
+
+	if query.Limit > 0 && int64(len(users)) > query.Limit {
+		users = users[:query.Limit]
+		result.Next = NewUserCursor(sortBy, users[len(users)-1])
+	}
+	if len(users) > 0 {
+		result.Users = users
+	}
+	return result, nil
+}
+
+func (r *userMemory) UpdateUser(_ context.Context, id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.users[id]
+	if !ok {
+		return result, ErrUserNotFound
+	}
+	if payload.Email != "" && r.emailTaken(payload.Email, id) {
+		return result, ErrUserEmailExists
+	}
+
+	// NOTE อัปเดตเฉพาะ field ที่ส่งมา (เหมือน omitempty ของ mongo)
+	if payload.Name != "" {
+		result.Name = payload.Name
+	}
+	if payload.Email != "" {
+		result.Email = payload.Email
+	}
+	if payload.Role != "" {
+		result.Role = payload.Role
+	}
+	if payload.Password != "" {
+		result.Password = payload.Password
+	}
+	if payload.LastLoginAt != nil {
+		result.LastLoginAt = payload.LastLoginAt
+	}
+	if payload.EmailVerified != nil {
+		result.EmailVerified = *payload.EmailVerified
+	}
+	r.users[id] = result
+	return result, nil
+}
+
+func (r *userMemory) DeleteUser(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *userMemory) CountUser(_ context.Context) (result int64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.users)), nil
+}
+
+// email ซ้ำ (ไม่สนตัวพิมพ์) กับ user อื่นที่ไม่ใช่ exceptID
+func (r *userMemory) emailTaken(email string, exceptID string) bool {
+	for _, user := range r.users {
+		if user.ID != exceptID && strings.EqualFold(user.Email, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"7solutions/backend/core/models"
+	"context"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -14,37 +15,37 @@ func NewUserRepositoryMock() *userRepoMock {
 	return &userRepoMock{}
 }
 
-func (m *userRepoMock) CreateUser(payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error) {
-	args := m.Called(payload)
+func (m *userRepoMock) CreateUser(ctx context.Context, payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error) {
+	args := m.Called(ctx, payload)
 	return args.Get(0).(models.RepoResUserModel), args.Error(1)
 }
 
-func (m *userRepoMock) GetUserByID(id string) (result models.RepoResUserModel, err error) {
-	args := m.Called(id)
+func (m *userRepoMock) GetUserByID(ctx context.Context, id string) (result models.RepoResUserModel, err error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(models.RepoResUserModel), args.Error(1)
 }
 
-func (m *userRepoMock) GetUserByEmail(email string) (result models.RepoResUserModel, err error) {
-	args := m.Called(email)
+func (m *userRepoMock) GetUserByEmail(ctx context.Context, email string) (result models.RepoResUserModel, err error) {
+	args := m.Called(ctx, email)
 	return args.Get(0).(models.RepoResUserModel), args.Error(1)
 }
 
-func (m *userRepoMock) GetUsers() (result []models.RepoResUserModel, err error) {
-	args := m.Called()
-	return args.Get(0).([]models.RepoResUserModel), args.Error(1)
+func (m *userRepoMock) GetUsers(ctx context.Context, query models.RepoUserQueryModel) (result models.RepoResUserPageModel, err error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(models.RepoResUserPageModel), args.Error(1)
 }
 
-func (m *userRepoMock) UpdateUser(id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error) {
-	args := m.Called(id, payload)
+func (m *userRepoMock) UpdateUser(ctx context.Context, id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error) {
+	args := m.Called(ctx, id, payload)
 	return args.Get(0).(models.RepoResUserModel), args.Error(1)
 }
 
-func (m *userRepoMock) DeleteUser(id string) error {
-	args := m.Called(id)
+func (m *userRepoMock) DeleteUser(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *userRepoMock) CountUser() (result int64, err error) {
-	args := m.Called()
+func (m *userRepoMock) CountUser(ctx context.Context) (result int64, err error) {
+	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
@@ -1,9 +1,11 @@
 package repositories
 
 import (
+	"7solutions/backend/common/apperror"
 	"7solutions/backend/core/models"
 	"context"
-	"time"
+	"log/slog"
+	"regexp"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,128 +15,200 @@ import (
 type userRepo struct {
 	db         *mongo.Database
 	collection string
+	timeouts   Timeouts
+	logger     *slog.Logger
 }
 
-func NewUserRepository(db *mongo.Database, collection string) UserRepository {
+func NewUserRepository(db *mongo.Database, collection string, timeouts Timeouts, logger *slog.Logger) UserRepository {
 	return &userRepo{
 		db:         db,
 		collection: collection,
+		timeouts:   timeouts,
+		logger:     logger,
 	}
 }
 
-func (r *userRepo) CreateUser(payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepo) CreateUser(ctx context.Context, payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "CreateUser")
 	defer cancel()
 
-	after := options.After
-	statusTrue := true
-	opt := options.FindOneAndUpdateOptions{
-		ReturnDocument: &after,
-		Upsert:         &statusTrue,
+	_, err = r.db.Collection(r.collection).InsertOne(ctx, payload)
+	if err != nil {
+		return result, userError(err)
 	}
 
-	res := r.db.Collection(r.collection).FindOneAndUpdate(ctx, bson.M{"user_id": payload.ID}, bson.M{"$set": payload}, &opt)
+	return newCreatedUser(payload), nil
+}
+
+func (r *userRepo) GetUserByID(ctx context.Context, id string) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "GetUserByID")
+	defer cancel()
+
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"id": id})
 	if res.Err() != nil {
-		return result, res.Err()
+		return result, mongoError(res.Err(), ErrUserNotFound)
 	}
 
 	if err := res.Decode(&result); err != nil {
-		return result, err
+		return result, mongoError(err, nil)
 	}
 
 	return result, nil
 }
 
-func (r *userRepo) GetUserByID(id string) (result models.RepoResUserModel, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "GetUserByEmail")
 	defer cancel()
 
-	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"id": id})
+	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"email": email}, options.FindOne().SetCollation(emailCollation))
 	if res.Err() != nil {
-		return result, res.Err()
+		return result, mongoError(res.Err(), ErrUserNotFound)
 	}
 
 	if err := res.Decode(&result); err != nil {
-		return result, err
+		return result, mongoError(err, nil)
 	}
 
 	return result, nil
 }
 
-func (r *userRepo) GetUserByEmail(email string) (result models.RepoResUserModel, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepo) GetUsers(ctx context.Context, query models.RepoUserQueryModel) (result models.RepoResUserPageModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "GetUsers")
 	defer cancel()
 
-	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"email": email})
-	if res.Err() != nil {
-		return result, res.Err()
+	filter := bson.M{}
+	if query.Name != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(query.Name), "$options": "i"}
+	}
+	if query.Email != "" {
+		filter["email"] = bson.M{"$regex": regexp.QuoteMeta(query.Email), "$options": "i"}
+	}
+	if query.CreatedFrom != nil || query.CreatedTo != nil {
+		createAt := bson.M{}
+		if query.CreatedFrom != nil {
+			createAt["$gte"] = *query.CreatedFrom
+		}
+		if query.CreatedTo != nil {
+			createAt["$lte"] = *query.CreatedTo
+		}
+		filter["createAt"] = createAt
 	}
 
-	if err := res.Decode(&result); err != nil {
-		return result, err
+	result.Total, err = r.db.Collection(r.collection).CountDocuments(ctx, filter)
+	if err != nil {
+		return result, mongoError(err, nil)
 	}
 
-	return result, nil
-}
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = UserSortCreateAt
+	}
+	direction, operator := 1, "$gt"
+	if query.SortDesc {
+		direction, operator = -1, "$lt"
+	}
 
-func (r *userRepo) GetUsers() (result []models.RepoResUserModel, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	opt := options.Find().SetSort(bson.D{{Key: sortBy, Value: direction}, {Key: "id", Value: direction}})
+	if query.Limit > 0 {
+		opt.SetLimit(query.Limit + 1)
+	}
+
+	// NOTE cursor: ต่อจากรายการสุดท้ายของหน้าก่อน (ค่าเท่ากันให้เทียบ id)
+	if query.After != nil {
+		value, err := userCursorValue(sortBy, query.After.Value)
+		if err != nil {
+			return result, apperror.Validation("CURSOR_INVALID", "cursor invalid")
+		}
+		filter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{sortBy: bson.M{operator: value}},
+			bson.M{sortBy: value, "id": bson.M{operator: query.After.ID}},
+		}}}}
+	} else if query.Offset > 0 {
+		opt.SetSkip(query.Offset)
+	}
 
-	cursor, err := r.db.Collection(r.collection).Find(ctx, bson.M{})
+	cursor, err := r.db.Collection(r.collection).Find(ctx, filter, opt)
 	if err != nil {
-		return nil, err
+		return result, mongoError(err, nil)
 	}
+	defer cursor.Close(ctx)
+
 	for cursor.Next(ctx) {
 		var user models.RepoResUserModel
 		if err := cursor.Decode(&user); err != nil {
-			return nil, err
+			return result, mongoError(err, nil)
 		}
-		result = append(result, user)
+		result.Users = append(result.Users, user)
+	}
+	if err := cursor.Err(); err != nil {
+		return result, mongoError(err, nil)
+	}
+
+	if query.Limit > 0 && int64(len(result.Users)) > query.Limit {
+		result.Users = result.Users[:query.Limit]
+		result.Next = NewUserCursor(sortBy, result.Users[len(result.Users)-1])
 	}
 	return result, nil
 }
 
-func (r *userRepo) UpdateUser(id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepo) UpdateUser(ctx context.Context, id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "UpdateUser")
 	defer cancel()
 
-	_, err = r.db.Collection(r.collection).UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": payload})
+	set, err := bson.Marshal(payload)
 	if err != nil {
-		return result, err
+		return result, mongoError(err, nil)
+	}
+	// NOTE ทุก field เป็น omitempty ถ้าไม่มีอะไรให้แก้ mongo ไม่รับ $set ว่าง จึงคืนข้อมูลเดิม (เหมือน SQL)
+	if fields, _ := bson.Raw(set).Elements(); len(fields) > 0 {
+		_, err = r.db.Collection(r.collection).UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": bson.Raw(set)})
+		if err != nil {
+			return result, userError(err)
+		}
 	}
 
 	res := r.db.Collection(r.collection).FindOne(ctx, bson.M{"id": id})
 	if res.Err() != nil {
-		return result, res.Err()
+		return result, mongoError(res.Err(), ErrUserNotFound)
 	}
 
 	if err := res.Decode(&result); err != nil {
-		return result, err
+		return result, mongoError(err, nil)
 	}
 	return result, nil
 }
 
-func (r *userRepo) DeleteUser(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepo) DeleteUser(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "DeleteUser")
 	defer cancel()
 
-	_, err := r.db.Collection(r.collection).DeleteOne(ctx, bson.M{"id": id})
+	res, err := r.db.Collection(r.collection).DeleteOne(ctx, bson.M{"id": id})
 	if err != nil {
-		return err
+		return mongoError(err, nil)
+	}
+	if res.DeletedCount == 0 {
+		return ErrUserNotFound
 	}
 
 	return nil
 }
 
-func (r *userRepo) CountUser() (result int64, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *userRepo) CountUser(ctx context.Context) (result int64, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "CountUser")
 	defer cancel()
 
 	res, err := r.db.Collection(r.collection).CountDocuments(ctx, bson.M{})
 	if err != nil {
-		return result, err
+		return result, mongoError(err, nil)
 	}
 	result = res
 	return result, nil
 }
+
+// email ซ้ำชน unique index -> conflict
+func userError(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserEmailExists
+	}
+	return mongoError(err, ErrUserNotFound)
+}
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/core/models"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type userSQLRepo struct {
+	db       *sql.DB
+	dialect  sqlDialect
+	timeouts Timeouts
+	logger   *slog.Logger
+}
+
+// ต้องรัน MigrateSQL ก่อนใช้งาน
+func NewUserSQLRepository(db *sql.DB, dialect string, timeouts Timeouts, logger *slog.Logger) UserRepository {
+	return &userSQLRepo{
+		db:       db,
+		dialect:  sqlDialect(dialect),
+		timeouts: timeouts,
+		logger:   logger,
+	}
+}
+
+const userSQLColumns = `id, name, email, password, role, created_at, last_login_at, email_verified`
+
+// column ของ field ที่ GetUsers เรียงได้
+var userSQLSort = map[string]string{
+	UserSortCreateAt: "created_at",
+	UserSortName:     "name",
+	UserSortEmail:    "email",
+}
+
+func scanUser(row interface{ Scan(...interface{}) error }) (result models.RepoResUserModel, err error) {
+	err = row.Scan(&result.ID, &result.Name, &result.Email, &result.Password, &result.Role, sqlTime{dst: &result.CreateAt}, sqlNullTime{dst: &result.LastLoginAt}, &result.EmailVerified)
+	return result, err
+}
+
+func (r *userSQLRepo) CreateUser(ctx context.Context, payload models.RepoCreateUserModel) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "CreateUser")
+	defer cancel()
+
+	query := `INSERT INTO users (id, name, email, password, role, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), payload.ID, payload.Name, payload.Email, payload.Password, payload.Role, r.dialect.timeValue(payload.CreateAt))
+	if err != nil {
+		return result, r.userError(err)
+	}
+
+	result = newCreatedUser(payload)
+	result.CreateAt = payload.CreateAt.UTC().Truncate(time.Microsecond)
+	return result, nil
+}
+
+func (r *userSQLRepo) GetUserByID(ctx context.Context, id string) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "GetUserByID")
+	defer cancel()
+
+	query := `SELECT ` + userSQLColumns + ` FROM users WHERE id = ?`
+	result, err = scanUser(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err != nil {
+		return result, sqlError(err, ErrUserNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *userSQLRepo) GetUserByEmail(ctx context.Context, email string) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "GetUserByEmail")
+	defer cancel()
+
+	query := `SELECT ` + userSQLColumns + ` FROM users WHERE lower(email) = lower(?)`
+	result, err = scanUser(r.db.QueryRowContext(ctx, r.dialect.rebind(query), email))
+	if err != nil {
+		return result, sqlError(err, ErrUserNotFound)
+	}
+
+	return result, nil
+}
+
+func (r *userSQLRepo) GetUsers(ctx context.Context, query models.RepoUserQueryModel) (result models.RepoResUserPageModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "GetUsers")
+	defer cancel()
+
+	where := []string{}
+	args := []interface{}{}
+	if query.Name != "" {
+		where = append(where, `lower(name) LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(query.Name))
+	}
+	if query.Email != "" {
+		where = append(where, `lower(email) LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(query.Email))
+	}
+	if query.CreatedFrom != nil {
+		where = append(where, `created_at >= ?`)
+		args = append(args, r.dialect.timeValue(*query.CreatedFrom))
+	}
+	if query.CreatedTo != nil {
+		where = append(where, `created_at <= ?`)
+		args = append(args, r.dialect.timeValue(*query.CreatedTo))
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = ` WHERE ` + strings.Join(where, " AND ")
+	}
+	err = r.db.QueryRowContext(ctx, r.dialect.rebind(`SELECT COUNT(*) FROM users`+filter), args...).Scan(&result.Total)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = UserSortCreateAt
+	}
+	column, direction, operator := userSQLSort[sortBy], "ASC", ">"
+	if query.SortDesc {
+		direction, operator = "DESC", "<"
+	}
+	sortColumn, idColumn := column, r.dialect.binary("id")
+	if sortBy != UserSortCreateAt {
+		sortColumn = r.dialect.binary(column)
+	}
+
+	// NOTE cursor: ต่อจากรายการสุดท้ายของหน้าก่อน (ค่าเท่ากันให้เทียบ id)
+	if query.After != nil {
+		value, err := userCursorValue(sortBy, query.After.Value)
+		if err != nil {
+			return result, apperror.Validation("CURSOR_INVALID", "cursor invalid")
+		}
+		if t, ok := value.(time.Time); ok {
+			value = r.dialect.timeValue(t)
+		}
+		where = append(where, `(`+sortColumn+` `+operator+` ? OR (`+column+` = ? AND `+idColumn+` `+operator+` ?))`)
+		args = append(args, value, value, query.After.ID)
+	}
+	filter = ""
+	if len(where) > 0 {
+		filter = ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	sqlQuery := `SELECT ` + userSQLColumns + ` FROM users` + filter + ` ORDER BY ` + sortColumn + ` ` + direction + `, ` + idColumn + ` ` + direction
+	if query.Limit > 0 {
+		sqlQuery += ` LIMIT ?`
+		args = append(args, query.Limit+1)
+	}
+	if query.After == nil && query.Offset > 0 {
+		if query.Limit <= 0 {
+			sqlQuery += ` LIMIT ` + r.dialect.noLimit()
+		}
+		sqlQuery += ` OFFSET ?`
+		args = append(args, query.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(sqlQuery), args...)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return result, sqlError(err, nil)
+		}
+		result.Users = append(result.Users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return result, sqlError(err, nil)
+	}
+
+	if query.Limit > 0 && int64(len(result.Users)) > query.Limit {
+		result.Users = result.Users[:query.Limit]
+		result.Next = NewUserCursor(sortBy, result.Users[len(result.Users)-1])
+	}
+	return result, nil
+}
+
+func (r *userSQLRepo) UpdateUser(ctx context.Context, id string, payload models.RepoUpdateUserModel) (result models.RepoResUserModel, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "UpdateUser")
+	defer cancel()
+
+	// NOTE อัปเดตเฉพาะ field ที่ส่งมา (เหมือน omitempty ของ mongo)
+	set := []string{}
+	args := []interface{}{}
+	if payload.Name != "" {
+		set = append(set, `name = ?`)
+		args = append(args, payload.Name)
+	}
+	if payload.Email != "" {
+		set = append(set, `email = ?`)
+		args = append(args, payload.Email)
+	}
+	if payload.Role != "" {
+		set = append(set, `role = ?`)
+		args = append(args, payload.Role)
+	}
+	if payload.Password != "" {
+		set = append(set, `password = ?`)
+		args = append(args, payload.Password)
+	}
+	if payload.LastLoginAt != nil {
+		set = append(set, `last_login_at = ?`)
+		args = append(args, r.dialect.timeValue(*payload.LastLoginAt))
+	}
+	if payload.EmailVerified != nil {
+		set = append(set, `email_verified = ?`)
+		args = append(args, *payload.EmailVerified)
+	}
+
+	if len(set) > 0 {
+		query := `UPDATE users SET ` + strings.Join(set, ", ") + ` WHERE id = ?`
+		_, err = r.db.ExecContext(ctx, r.dialect.rebind(query), append(args, id)...)
+		if err != nil {
+			return result, r.userError(err)
+		}
+	}
+
+	query := `SELECT ` + userSQLColumns + ` FROM users WHERE id = ?`
+	result, err = scanUser(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err != nil {
+		return result, sqlError(err, ErrUserNotFound)
+	}
+	return result, nil
+}
+
+func (r *userSQLRepo) DeleteUser(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "DeleteUser")
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, r.dialect.rebind(`DELETE FROM users WHERE id = ?`), id)
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return sqlError(err, nil)
+	}
+	if deleted == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userSQLRepo) CountUser(ctx context.Context) (result int64, err error) {
+	ctx, cancel := r.timeouts.operation(ctx, r.logger, "user", "CountUser")
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&result)
+	if err != nil {
+		return result, sqlError(err, nil)
+	}
+	return result, nil
+}
+
+// email ซ้ำชน unique index -> conflict
+func (r *userSQLRepo) userError(err error) error {
+	if r.dialect.isUniqueViolation(err) {
+		return ErrUserEmailExists
+	}
+	return sqlError(err, nil)
+}
+
+// ค้นหาแบบมีคำนี้อยู่ (ไม่สนตัวพิมพ์) escape อักขระพิเศษของ LIKE
+func likePattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(strings.ToLower(value)) + "%"
+}
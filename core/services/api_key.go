@@ -0,0 +1,15 @@
+package services
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+)
+
+type APIKeyService interface {
+	// สร้าง API key ให้ user (ตัวเอง หรือผู้มีสิทธิ์ apikey:write) key แสดงครั้งเดียว
+	CreateAPIKey(ctx context.Context, caller models.SrvCallerModel, userID string, payload models.SrvCreateAPIKeyModel) (result models.Response)
+
+	GetAPIKeys(ctx context.Context, caller models.SrvCallerModel, userID string) (result models.Response)
+
+	RevokeAPIKey(ctx context.Context, caller models.SrvCallerModel, userID string, id string) (result models.Response)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/utils"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type apiKeySrv struct {
+	userRepo   repositories.UserRepository
+	apiKeyRepo repositories.APIKeyRepository
+	serviceLogger
+}
+
+func NewAPIKeyService(userRepo repositories.UserRepository, apiKeyRepo repositories.APIKeyRepository, logger *slog.Logger) APIKeyService {
+	return &apiKeySrv{
+		userRepo:      userRepo,
+		apiKeyRepo:    apiKeyRepo,
+		serviceLogger: serviceLogger{logger: logger},
+	}
+}
+
+func (s *apiKeySrv) CreateAPIKey(ctx context.Context, caller models.SrvCallerModel, userID string, payload models.SrvCreateAPIKeyModel) (result models.Response) {
+	if userID == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	// NOTE key หรือ OAuth token ที่หลุดต้องสร้าง key ใหม่ต่อไม่ได้ จึงต้องใช้ session ที่ sign in เอง
+	if caller.Delegated() || !caller.CanAccess(userID, models.PermissionAPIKeyWrite) {
+		return s.failure(errForbidden)
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if payload.Name == "" {
+		return s.failure(apperror.Validation("NAME_REQUIRED", "name is required"))
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		return s.failure(apperror.Validation("EXPIRES_AT_INVALID", "expiresAt must be in the future"))
+	}
+
+	// NOTE ให้ได้เฉพาะสิทธิ์ที่เจ้าของ key มี
+	owner, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return s.failure(err)
+	}
+	permissions := []string{}
+	for _, p := range payload.Permissions {
+		if !models.HasPermission(userRole(owner.Role), p) {
+			return s.failure(apperror.Validation("PERMISSION_INVALID", "permission invalid: "+p))
+		}
+		if !slices.Contains(permissions, p) {
+			permissions = append(permissions, p)
+		}
+	}
+
+	key, prefix, err := utils.APIKey_Generate()
+	if err != nil {
+		return s.failure(err)
+	}
+	res, err := s.apiKeyRepo.CreateAPIKey(ctx, models.RepoCreateAPIKeyModel{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        payload.Name,
+		Prefix:      prefix,
+		KeyHash:     utils.Token_Hash(key),
+		Permissions: permissions,
+		ExpiresAt:   payload.ExpiresAt,
+		CreateAt:    time.Now(),
+	})
+	if err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "create api key success",
+		Code:    201,
+		Data: models.SrvCreateAPIKeyResModel{
+			SrvResAPIKeyModel: apiKeyResponse(res),
+			Key:               key,
+		},
+	}
+	return result
+}
+
+func (s *apiKeySrv) GetAPIKeys(ctx context.Context, caller models.SrvCallerModel, userID string) (result models.Response) {
+	if userID == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.CanAccess(userID, models.PermissionAPIKeyWrite) {
+		return s.failure(errForbidden)
+	}
+
+	res, err := s.apiKeyRepo.GetAPIKeysByUserID(ctx, userID)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	data := []models.SrvResAPIKeyModel{}
+	for _, key := range res {
+		data = append(data, apiKeyResponse(key))
+	}
+	result = models.Response{
+		Status:  true,
+		Message: "get api keys success",
+		Code:    200,
+		Data:    data,
+	}
+	return result
+}
+
+func (s *apiKeySrv) RevokeAPIKey(ctx context.Context, caller models.SrvCallerModel, userID string, id string) (result models.Response) {
+	if userID == "" || id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.CanAccess(userID, models.PermissionAPIKeyWrite) {
+		return s.failure(errForbidden)
+	}
+
+	if err := s.apiKeyRepo.RevokeAPIKey(ctx, userID, id, time.Now()); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "revoke api key success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+// ข้อมูล key สำหรับส่งออก (ไม่มี hash)
+func apiKeyResponse(key models.RepoResAPIKeyModel) models.SrvResAPIKeyModel {
+	return models.SrvResAPIKeyModel{
+		ID:          key.ID,
+		UserID:      key.UserID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Permissions: key.Permissions,
+		ExpiresAt:   key.ExpiresAt,
+		CreateAt:    key.CreateAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+	}
+}
@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"7solutions/backend/common/logger"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/core/services"
+	"7solutions/backend/utils"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateAPIKey(t *testing.T) {
+	type test struct {
+		Name   string
+		Caller models.SrvCallerModel
+		UserID string
+		Input  models.SrvCreateAPIKeyModel
+		Output models.Response
+	}
+	user := models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser}
+	past := time.Now().Add(-time.Hour)
+	cases := []test{
+		{
+			Name:   "other user",
+			Caller: user,
+			UserID: "admin-id",
+			Input:  models.SrvCreateAPIKeyModel{Name: "batch"},
+			Output: models.Response{Code: 403, ErrorCode: "FORBIDDEN", Message: "forbidden"},
+		},
+		{
+			Name:   "api key cannot create api key",
+			Caller: models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser, APIKeyID: "key-id"},
+			UserID: "user-id",
+			Input:  models.SrvCreateAPIKeyModel{Name: "batch"},
+			Output: models.Response{Code: 403, ErrorCode: "FORBIDDEN", Message: "forbidden"},
+		},
+		{
+			Name:   "name required",
+			Caller: user,
+			UserID: "user-id",
+			Input:  models.SrvCreateAPIKeyModel{Name: " "},
+			Output: models.Response{Code: 422, ErrorCode: "NAME_REQUIRED", Message: "name is required"},
+		},
+		{
+			Name:   "expired",
+			Caller: user,
+			UserID: "user-id",
+			Input:  models.SrvCreateAPIKeyModel{Name: "batch", ExpiresAt: &past},
+			Output: models.Response{Code: 422, ErrorCode: "EXPIRES_AT_INVALID", Message: "expiresAt must be in the future"},
+		},
+		{
+			Name:   "permission owner does not have",
+			Caller: user,
+			UserID: "user-id",
+			Input:  models.SrvCreateAPIKeyModel{Name: "batch", Permissions: []string{models.PermissionUserList}},
+			Output: models.Response{Code: 422, ErrorCode: "PERMISSION_INVALID", Message: "permission invalid: user:list"},
+		},
+		{
+			Name:   "admin creates for user without permission",
+			Caller: admin,
+			UserID: "user-id",
+			Input:  models.SrvCreateAPIKeyModel{Name: "batch", Permissions: []string{models.PermissionUserList}},
+			Output: models.Response{Code: 422, ErrorCode: "PERMISSION_INVALID", Message: "permission invalid: user:list"},
+		},
+	}
+
+	userRepo := repositories.NewUserRepositoryMock()
+	userRepo.On("GetUserByID", mock.Anything, "user-id").Return(models.RepoResUserModel{ID: "user-id", Role: models.RoleUser}, nil)
+	apiKeySrv := services.NewAPIKeyService(userRepo, repositories.NewAPIKeyRepositoryMock(), logger.Discard())
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			result := apiKeySrv.CreateAPIKey(context.Background(), c.Caller, c.UserID, c.Input)
+			assert.Equal(t, c.Output, result)
+		})
+	}
+}
+
+func Test_APIKeyLifecycle(t *testing.T) {
+	userRepo := repositories.NewUserRepositoryMock()
+	userRepo.On("GetUserByID", mock.Anything, "admin-id").Return(models.RepoResUserModel{ID: "admin-id", Role: models.RoleAdmin}, nil)
+	apiKeyRepo := repositories.NewAPIKeyMemoryRepository()
+	apiKeySrv := services.NewAPIKeyService(userRepo, apiKeyRepo, logger.Discard())
+
+	result := apiKeySrv.CreateAPIKey(context.Background(), admin, "admin-id", models.SrvCreateAPIKeyModel{
+		Name:        "batch",
+		Permissions: []string{models.PermissionUserList, models.PermissionUserList},
+	})
+	require.Equal(t, 201, result.Code, result.Message)
+	created := result.Data.(models.SrvCreateAPIKeyResModel)
+	assert.True(t, strings.HasPrefix(created.Key, "sk_"+created.Prefix+"_"))
+	assert.Equal(t, []string{models.PermissionUserList}, created.Permissions)
+
+	// NOTE เก็บเฉพาะ hash
+	stored, err := apiKeyRepo.GetAPIKeyByPrefix(context.Background(), created.Prefix)
+	require.NoError(t, err)
+	assert.Equal(t, utils.Token_Hash(created.Key), stored.KeyHash)
+
+	result = apiKeySrv.GetAPIKeys(context.Background(), models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser}, "admin-id")
+	assert.Equal(t, 403, result.Code)
+	result = apiKeySrv.GetAPIKeys(context.Background(), admin, "admin-id")
+	require.Equal(t, 200, result.Code, result.Message)
+	keys := result.Data.([]models.SrvResAPIKeyModel)
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.ID, keys[0].ID)
+
+	result = apiKeySrv.RevokeAPIKey(context.Background(), admin, "admin-id", created.ID)
+	require.Equal(t, 200, result.Code, result.Message)
+	result = apiKeySrv.RevokeAPIKey(context.Background(), admin, "admin-id", created.ID)
+	assert.Equal(t, 404, result.Code)
+	assert.Equal(t, "API_KEY_NOT_FOUND", result.ErrorCode)
+}
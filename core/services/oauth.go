@@ -0,0 +1,30 @@
+package services
+
+import (
+	"7solutions/backend/core/models"
+	"context"
+)
+
+type OAuthService interface {
+	// ลงทะเบียน client (เฉพาะผู้มีสิทธิ์ oauth:client) secret แสดงครั้งเดียว
+	CreateClient(ctx context.Context, caller models.SrvCallerModel, payload models.SrvCreateOAuthClientModel) (result models.Response)
+
+	GetClients(ctx context.Context, caller models.SrvCallerModel) (result models.Response)
+
+	DeleteClient(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
+
+	// ตรวจคำขอจาก client แล้วคืนข้อมูลที่หน้าขอความยินยอมต้องแสดง
+	Authorize(ctx context.Context, caller models.SrvCallerModel, query models.SrvOAuthAuthorizeModel) (result models.Response)
+
+	// user อนุญาตหรือปฏิเสธ คืน URL ของ client พร้อม code หรือ error
+	Consent(ctx context.Context, caller models.SrvCallerModel, payload models.SrvOAuthConsentModel) (result models.Response)
+
+	// grant authorization_code และ client_credentials
+	Token(ctx context.Context, payload models.SrvOAuthTokenModel) (result models.Response)
+
+	// RFC 7662 เฉพาะ confidential client
+	Introspect(ctx context.Context, payload models.SrvOAuthTokenActionModel) (result models.Response)
+
+	// RFC 7009 เพิกถอนได้เฉพาะ token ที่ออกให้ client นั้น
+	Revoke(ctx context.Context, payload models.SrvOAuthTokenActionModel) (result models.Response)
+}
@@ -0,0 +1,532 @@
+package services
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/utils"
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NOTE error code ตรงกับ RFC 6749 5.2 handler ของ /api/oauth/token แปลงเป็นตัวพิมพ์เล็ก
+var (
+	errOAuthInvalidClient   = apperror.Unauthorized("INVALID_CLIENT", "client authentication failed")
+	errOAuthInvalidGrant    = apperror.BadRequest("INVALID_GRANT", "authorization code is invalid or expired")
+	errOAuthInvalidScope    = apperror.BadRequest("INVALID_SCOPE", "scope is not allowed for this client")
+	errOAuthUnauthorized    = apperror.BadRequest("UNAUTHORIZED_CLIENT", "client is not allowed to use this grant type")
+	errOAuthUnsupported     = apperror.BadRequest("UNSUPPORTED_GRANT_TYPE", "grant type is not supported")
+	errOAuthRedirectInvalid = apperror.BadRequest("INVALID_REDIRECT_URI", "redirect_uri is not registered for this client")
+)
+
+type oauthSrv struct {
+	auth             authorization.AppAuthorization
+	userRepo         repositories.UserRepository
+	clientRepo       repositories.OAuthClientRepository
+	codeRepo         repositories.OAuthCodeRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	serviceLogger
+}
+
+func NewOAuthService(auth authorization.AppAuthorization, userRepo repositories.UserRepository, clientRepo repositories.OAuthClientRepository, codeRepo repositories.OAuthCodeRepository, revokedTokenRepo repositories.RevokedTokenRepository, logger *slog.Logger) OAuthService {
+	return &oauthSrv{
+		auth:             auth,
+		userRepo:         userRepo,
+		clientRepo:       clientRepo,
+		codeRepo:         codeRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		serviceLogger:    serviceLogger{logger: logger},
+	}
+}
+
+func (s *oauthSrv) CreateClient(ctx context.Context, caller models.SrvCallerModel, payload models.SrvCreateOAuthClientModel) (result models.Response) {
+	// NOTE client secret เป็น credential จึงต้องใช้ session ที่ sign in เอง เหมือนการสร้าง API key
+	if caller.Delegated() || !caller.Can(models.PermissionOAuthClient) {
+		return s.failure(errForbidden)
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if payload.Name == "" {
+		return s.failure(apperror.Validation("NAME_REQUIRED", "name is required"))
+	}
+	if len(payload.GrantTypes) == 0 {
+		payload.GrantTypes = []string{models.GrantAuthorizationCode}
+	}
+	for _, grant := range payload.GrantTypes {
+		if grant != models.GrantAuthorizationCode && grant != models.GrantClientCredentials {
+			return s.failure(apperror.Validation("GRANT_TYPE_INVALID", "grant type invalid: "+grant))
+		}
+	}
+	// NOTE public client เก็บ secret ไม่ได้ จึงขอ token ในนามตัวเองไม่ได้
+	if slices.Contains(payload.GrantTypes, models.GrantClientCredentials) && !payload.Confidential {
+		return s.failure(apperror.Validation("GRANT_TYPE_INVALID", "client_credentials requires a confidential client"))
+	}
+	if slices.Contains(payload.GrantTypes, models.GrantAuthorizationCode) && len(payload.RedirectURIs) == 0 {
+		return s.failure(apperror.Validation("REDIRECT_URI_REQUIRED", "redirectUris is required for authorization_code"))
+	}
+	for _, uri := range payload.RedirectURIs {
+		if !validRedirectURI(uri) {
+			return s.failure(apperror.Validation("REDIRECT_URI_INVALID", "redirect uri invalid: "+uri))
+		}
+	}
+	for _, scope := range payload.Scopes {
+		if !models.ValidPermission(scope) {
+			return s.failure(apperror.Validation("SCOPE_INVALID", "scope invalid: "+scope))
+		}
+	}
+
+	var secret, secretHash string
+	if payload.Confidential {
+		var err error
+		secret, err = utils.Token_Random(32)
+		if err != nil {
+			return s.failure(err)
+		}
+		secretHash = utils.Token_Hash(secret)
+	}
+	res, err := s.clientRepo.CreateOAuthClient(ctx, models.RepoCreateOAuthClientModel{
+		ID:           uuid.New().String(),
+		Name:         payload.Name,
+		SecretHash:   secretHash,
+		RedirectURIs: uniqueStrings(payload.RedirectURIs),
+		Scopes:       uniqueStrings(payload.Scopes),
+		GrantTypes:   uniqueStrings(payload.GrantTypes),
+		CreateAt:     time.Now(),
+	})
+	if err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "create oauth client success",
+		Code:    201,
+		Data: models.SrvCreateOAuthClientResModel{
+			SrvResOAuthClientModel: oauthClientResponse(res),
+			Secret:                 secret,
+		},
+	}
+	return result
+}
+
+func (s *oauthSrv) GetClients(ctx context.Context, caller models.SrvCallerModel) (result models.Response) {
+	if !caller.Can(models.PermissionOAuthClient) {
+		return s.failure(errForbidden)
+	}
+
+	res, err := s.clientRepo.GetOAuthClients(ctx)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	data := []models.SrvResOAuthClientModel{}
+	for _, client := range res {
+		data = append(data, oauthClientResponse(client))
+	}
+	result = models.Response{
+		Status:  true,
+		Message: "get oauth clients success",
+		Code:    200,
+		Data:    data,
+	}
+	return result
+}
+
+func (s *oauthSrv) DeleteClient(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.Can(models.PermissionOAuthClient) {
+		return s.failure(errForbidden)
+	}
+
+	if err := s.clientRepo.DeleteOAuthClient(ctx, id); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "delete oauth client success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *oauthSrv) Authorize(ctx context.Context, caller models.SrvCallerModel, query models.SrvOAuthAuthorizeModel) (result models.Response) {
+	client, redirectURI, scopes, err := s.authorizeRequest(ctx, caller, query)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "authorize success",
+		Code:    200,
+		Data: models.SrvOAuthConsentResModel{
+			ClientID:    client.ID,
+			ClientName:  client.Name,
+			Scopes:      scopes,
+			RedirectURI: redirectURI,
+		},
+	}
+	return result
+}
+
+func (s *oauthSrv) Consent(ctx context.Context, caller models.SrvCallerModel, payload models.SrvOAuthConsentModel) (result models.Response) {
+	client, redirectURI, scopes, err := s.authorizeRequest(ctx, caller, payload.SrvOAuthAuthorizeModel)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	params := url.Values{}
+	if payload.Approve {
+		code, err := utils.Token_Random(32)
+		if err != nil {
+			return s.failure(err)
+		}
+		_, err = s.codeRepo.CreateOAuthCode(ctx, models.RepoCreateOAuthCodeModel{
+			CodeHash: utils.Token_Hash(code),
+			ClientID: client.ID,
+			UserID:   caller.UserID,
+			// NOTE เก็บค่าที่ client ส่งมาตามจริง (ว่างได้) เพื่อเทียบกับตอนแลก token ตาม RFC 6749 4.1.3
+			RedirectURI:   payload.RedirectURI,
+			Scopes:        scopes,
+			CodeChallenge: payload.CodeChallenge,
+			ExpiresAt:     time.Now().Add(config.Env.OAuthCodeExp),
+			CreateAt:      time.Now(),
+		})
+		if err != nil {
+			return s.failure(err)
+		}
+		params.Set("code", code)
+	} else {
+		params.Set("error", "access_denied")
+	}
+	if payload.State != "" {
+		params.Set("state", payload.State)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "consent success",
+		Code:    200,
+		Data:    models.SrvOAuthRedirectResModel{RedirectURI: appendQuery(redirectURI, params)},
+	}
+	return result
+}
+
+func (s *oauthSrv) Token(ctx context.Context, payload models.SrvOAuthTokenModel) (result models.Response) {
+	client, err := s.authenticateClient(ctx, payload.SrvOAuthClientAuthModel)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	var token models.SrvOAuthTokenResModel
+	switch payload.GrantType {
+	case models.GrantAuthorizationCode:
+		token, err = s.exchangeCode(ctx, client, payload)
+	case models.GrantClientCredentials:
+		token, err = s.clientCredentials(ctx, client, payload)
+	case "":
+		err = apperror.BadRequest("INVALID_REQUEST", "grant_type is required")
+	default:
+		err = errOAuthUnsupported
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "token success",
+		Code:    200,
+		Data:    token,
+	}
+	return result
+}
+
+func (s *oauthSrv) Introspect(ctx context.Context, payload models.SrvOAuthTokenActionModel) (result models.Response) {
+	client, err := s.authenticateClient(ctx, payload.SrvOAuthClientAuthModel)
+	if err != nil {
+		return s.failure(err)
+	}
+	// NOTE ข้อมูลใน token เป็นของ user จึงให้เฉพาะ client ที่ยืนยันตัวด้วย secret ได้
+	if client.SecretHash == "" {
+		return s.failure(errOAuthUnauthorized)
+	}
+
+	// NOTE token ของ client อื่นตอบ active = false เหมือน token ที่ใช้ไม่ได้ (RFC 7662 2.2)
+	data := models.SrvOAuthIntrospectResModel{Active: false}
+	claim, ok, err := s.activeToken(ctx, payload.Token)
+	if err != nil {
+		return s.failure(err)
+	}
+	if ok && claim.ClientId == client.ID {
+		data = models.SrvOAuthIntrospectResModel{
+			Active:    true,
+			Scope:     claim.Scope,
+			ClientID:  claim.ClientId,
+			Subject:   claim.UserId,
+			TokenType: "Bearer",
+			Expires:   claim.ExpiresAt,
+			IssuedAt:  claim.IssuedTime().Unix(),
+			Issuer:    claim.Issuer,
+			Audience:  claim.Audience,
+			TokenID:   claim.TokenId,
+			Role:      claim.Role,
+		}
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "introspect success",
+		Code:    200,
+		Data:    data,
+	}
+	return result
+}
+
+func (s *oauthSrv) Revoke(ctx context.Context, payload models.SrvOAuthTokenActionModel) (result models.Response) {
+	client, err := s.authenticateClient(ctx, payload.SrvOAuthClientAuthModel)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	// NOTE RFC 7009 2.2 token ที่ใช้ไม่ได้อยู่แล้วหรือไม่ใช่ของ client นี้ก็ตอบสำเร็จ (ไม่บอกว่า token มีอยู่จริงไหม)
+	claim, ok, err := s.activeToken(ctx, payload.Token)
+	if err != nil {
+		return s.failure(err)
+	}
+	if ok && claim.ClientId == client.ID {
+		if err := s.revokedTokenRepo.RevokeToken(ctx, claim.TokenId, claim.ExpiresTime()); err != nil {
+			return s.failure(err)
+		}
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "revoke success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+// ตรวจคำขอ authorize คืน client, redirect URI ที่จะส่งกลับ และ scope ที่จะให้
+func (s *oauthSrv) authorizeRequest(ctx context.Context, caller models.SrvCallerModel, query models.SrvOAuthAuthorizeModel) (client models.RepoResOAuthClientModel, redirectURI string, scopes []string, err error) {
+	// NOTE ต้องเป็น user ที่ sign in เอง client อื่นจะได้ขอความยินยอมแทน user ไม่ได้
+	if caller.UserID == "" || caller.Delegated() {
+		return client, "", nil, errForbidden
+	}
+	if query.ClientID == "" {
+		return client, "", nil, apperror.Validation("CLIENT_ID_REQUIRED", "client_id is required")
+	}
+	client, err = s.clientRepo.GetOAuthClient(ctx, query.ClientID)
+	if err != nil {
+		return client, "", nil, err
+	}
+	if !slices.Contains(client.GrantTypes, models.GrantAuthorizationCode) {
+		return client, "", nil, errOAuthUnauthorized
+	}
+
+	// NOTE ไม่ระบุ redirect_uri ได้เมื่อ client ลงทะเบียนไว้ค่าเดียว
+	redirectURI = query.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		return client, "", nil, errOAuthRedirectInvalid
+	}
+
+	if query.ResponseType != "code" {
+		return client, "", nil, apperror.BadRequest("UNSUPPORTED_RESPONSE_TYPE", "response_type must be code")
+	}
+	// NOTE บังคับ PKCE ทุก client (รวม confidential) กัน code ถูกขโมยไปแลก
+	if query.CodeChallenge == "" || query.CodeChallengeMethod != "S256" {
+		return client, "", nil, apperror.BadRequest("INVALID_REQUEST", "code_challenge with method S256 is required")
+	}
+	scopes, err = requestScopes(client, query.Scope)
+	if err != nil {
+		return client, "", nil, err
+	}
+
+	return client, redirectURI, scopes, nil
+}
+
+func (s *oauthSrv) exchangeCode(ctx context.Context, client models.RepoResOAuthClientModel, payload models.SrvOAuthTokenModel) (result models.SrvOAuthTokenResModel, err error) {
+	if !slices.Contains(client.GrantTypes, models.GrantAuthorizationCode) {
+		return result, errOAuthUnauthorized
+	}
+	if payload.Code == "" || payload.CodeVerifier == "" {
+		return result, apperror.BadRequest("INVALID_REQUEST", "code and code_verifier are required")
+	}
+
+	code, err := s.codeRepo.UseOAuthCode(ctx, utils.Token_Hash(payload.Code))
+	if apperror.Is(err, apperror.KindNotFound) {
+		return result, errOAuthInvalidGrant
+	}
+	if err != nil {
+		return result, err
+	}
+	if time.Now().After(code.ExpiresAt) || code.ClientID != client.ID || code.RedirectURI != payload.RedirectURI {
+		return result, errOAuthInvalidGrant
+	}
+	if subtle.ConstantTimeCompare([]byte(oidc.CodeChallenge(payload.CodeVerifier)), []byte(code.CodeChallenge)) != 1 {
+		return result, errOAuthInvalidGrant
+	}
+
+	// NOTE ใช้ role ปัจจุบันของ user ถูกลบไปแล้วระหว่างรอก็แลกไม่ได้
+	user, err := s.userRepo.GetUserByID(ctx, code.UserID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return result, errOAuthInvalidGrant
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return s.issueToken(ctx, authorization.AppAuthorizationClaim{
+		UserId: user.ID,
+		Role:   userRole(user.Role),
+	}, client, code.Scopes)
+}
+
+func (s *oauthSrv) clientCredentials(ctx context.Context, client models.RepoResOAuthClientModel, payload models.SrvOAuthTokenModel) (result models.SrvOAuthTokenResModel, err error) {
+	if client.SecretHash == "" || !slices.Contains(client.GrantTypes, models.GrantClientCredentials) {
+		return result, errOAuthUnauthorized
+	}
+	scopes, err := requestScopes(client, payload.Scope)
+	if err != nil {
+		return result, err
+	}
+
+	// NOTE token ของ client เองไม่มี sub (ไม่ได้เรียกแทน user คนไหน)
+	return s.issueToken(ctx, authorization.AppAuthorizationClaim{}, client, scopes)
+}
+
+func (s *oauthSrv) issueToken(ctx context.Context, claim authorization.AppAuthorizationClaim, client models.RepoResOAuthClientModel, scopes []string) (result models.SrvOAuthTokenResModel, err error) {
+	claim.Audience = "7solutions"
+	claim.Issuer = "7solutions"
+	claim.Scope = strings.Join(scopes, " ")
+	claim.ClientId = client.ID
+	accessToken, err := s.auth.GenerateToken(ctx, claim)
+	if err != nil {
+		return result, err
+	}
+
+	result = models.SrvOAuthTokenResModel{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(config.Env.SignatureExp.Seconds()),
+		Scope:       claim.Scope,
+	}
+	return result, nil
+}
+
+// ตรวจ client_id และ client_secret (public client ไม่มี secret)
+func (s *oauthSrv) authenticateClient(ctx context.Context, payload models.SrvOAuthClientAuthModel) (client models.RepoResOAuthClientModel, err error) {
+	if payload.ClientID == "" {
+		return client, errOAuthInvalidClient
+	}
+	client, err = s.clientRepo.GetOAuthClient(ctx, payload.ClientID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return client, errOAuthInvalidClient
+	}
+	if err != nil {
+		return client, err
+	}
+	if client.SecretHash == "" {
+		if payload.ClientSecret != "" {
+			return client, errOAuthInvalidClient
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.Token_Hash(payload.ClientSecret)), []byte(client.SecretHash)) != 1 {
+		return client, errOAuthInvalidClient
+	}
+	return client, nil
+}
+
+// token ที่ยังใช้ได้ (ลายเซ็นถูก ไม่หมดอายุ และไม่ถูกเพิกถอน) token ที่ใช้ไม่ได้คืน ok = false
+func (s *oauthSrv) activeToken(ctx context.Context, token string) (claim authorization.AppAuthorizationClaim, ok bool, err error) {
+	if token == "" {
+		return claim, false, nil
+	}
+	if err := s.auth.ValidateToken(ctx, token, &claim); err != nil {
+		return claim, false, nil
+	}
+	revoked, err := s.revokedTokenRepo.IsTokenRevoked(ctx, claim.TokenId, claim.UserId, claim.IssuedTime())
+	if err != nil {
+		return claim, false, err
+	}
+	return claim, !revoked, nil
+}
+
+// scope ที่ขอต้องอยู่ใน scope ของ client ไม่ระบุ = ทั้งหมดที่ client มี
+func requestScopes(client models.RepoResOAuthClientModel, scope string) ([]string, error) {
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		return client.Scopes, nil
+	}
+	for _, s := range requested {
+		if !slices.Contains(client.Scopes, s) {
+			return nil, errOAuthInvalidScope
+		}
+	}
+	return uniqueStrings(requested), nil
+}
+
+// URI แบบ absolute ไม่มี fragment (RFC 6749 3.1.2) และไม่มีช่องว่าง
+func validRedirectURI(uri string) bool {
+	if strings.ContainsAny(uri, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(uri)
+	return err == nil && u.Scheme != "" && u.Fragment == ""
+}
+
+// ต่อ query เข้ากับ redirect URI ที่อาจมี query อยู่แล้ว
+func appendQuery(uri string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func uniqueStrings(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		if !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// ข้อมูล client สำหรับส่งออก (ไม่มี hash ของ secret)
+func oauthClientResponse(client models.RepoResOAuthClientModel) models.SrvResOAuthClientModel {
+	return models.SrvResOAuthClientModel{
+		ID:           client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		GrantTypes:   client.GrantTypes,
+		Confidential: client.SecretHash != "",
+		CreateAt:     client.CreateAt,
+	}
+}
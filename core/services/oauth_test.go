@@ -0,0 +1,413 @@
+package services_test
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/core/services"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type oauthTestService struct {
+	services.OAuthService
+	auth         authorization.AppAuthorization
+	userRepo     repositories.UserRepository
+	confidential models.SrvCreateOAuthClientResModel
+	public       models.SrvCreateOAuthClientResModel
+}
+
+func newOAuthTestService(t *testing.T) oauthTestService {
+	// NOTE test อื่นคาดว่า SIGNATURE_EXP เป็นค่าเริ่มต้น จึงคืนค่าเดิมเมื่อจบ
+	signatureExp, codeExp := config.Env.SignatureExp, config.Env.OAuthCodeExp
+	t.Cleanup(func() { config.Env.SignatureExp, config.Env.OAuthCodeExp = signatureExp, codeExp })
+	config.Env.SignatureExp = time.Hour
+	config.Env.OAuthCodeExp = time.Minute
+	keyRing, err := authorization.NewKeyRing(jwt.SigningMethodHS256, authorization.StaticKeyLoader("test",
+		authorization.SigningKey{Kid: "test", PrivateKey: []byte("secret"), PublicKey: []byte("secret")},
+	))
+	require.NoError(t, err)
+	auth := authorization.NewAppAuthorization(keyRing)
+
+	userRepo := repositories.NewUserMemoryRepository()
+	_, err = userRepo.CreateUser(context.Background(), models.RepoCreateUserModel{ID: "user-id", Name: "bank", Email: "bank@test.com", Role: models.RoleUser, CreateAt: time.Now()})
+	require.NoError(t, err)
+
+	oauthSrv := services.NewOAuthService(auth, userRepo, repositories.NewOAuthClientMemoryRepository(), repositories.NewOAuthCodeMemoryRepository(), repositories.NewRevokedTokenMemoryRepository(), logger.Discard())
+	result := oauthSrv.CreateClient(context.Background(), admin, models.SrvCreateOAuthClientModel{
+		Name:         "reports",
+		RedirectURIs: []string{"https://reports.test/callback"},
+		Scopes:       []string{models.PermissionUserRead, models.PermissionUserList},
+		GrantTypes:   []string{models.GrantAuthorizationCode, models.GrantClientCredentials},
+		Confidential: true,
+	})
+	require.Equal(t, 201, result.Code, result.Message)
+	confidential := result.Data.(models.SrvCreateOAuthClientResModel)
+
+	result = oauthSrv.CreateClient(context.Background(), admin, models.SrvCreateOAuthClientModel{
+		Name:         "mobile",
+		RedirectURIs: []string{"com.mobile:/callback", "https://mobile.test/callback"},
+		Scopes:       []string{models.PermissionUserRead},
+	})
+	require.Equal(t, 201, result.Code, result.Message)
+	public := result.Data.(models.SrvCreateOAuthClientResModel)
+
+	return oauthTestService{OAuthService: oauthSrv, auth: auth, userRepo: userRepo, confidential: confidential, public: public}
+}
+
+// คำขอ authorize ที่ถูกต้องของ client
+func authorizeRequest(client models.SrvCreateOAuthClientResModel, verifier string) models.SrvOAuthAuthorizeModel {
+	return models.SrvOAuthAuthorizeModel{
+		ResponseType:        "code",
+		ClientID:            client.ID,
+		RedirectURI:         client.RedirectURIs[0],
+		State:               "xyz",
+		CodeChallenge:       oidc.CodeChallenge(verifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+// user อนุญาตแล้วคืน code จาก redirect URI
+func consentCode(t *testing.T, oauthSrv services.OAuthService, query models.SrvOAuthAuthorizeModel) string {
+	result := oauthSrv.Consent(context.Background(), models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser}, models.SrvOAuthConsentModel{SrvOAuthAuthorizeModel: query, Approve: true})
+	require.Equal(t, 200, result.Code, result.Message)
+	redirect, err := url.Parse(result.Data.(models.SrvOAuthRedirectResModel).RedirectURI)
+	require.NoError(t, err)
+	assert.Equal(t, query.State, redirect.Query().Get("state"))
+	return redirect.Query().Get("code")
+}
+
+func Test_CreateOAuthClient(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Caller models.SrvCallerModel
+		Input  models.SrvCreateOAuthClientModel
+		Output models.Response
+	}{
+		{
+			Name:   "user has no permission",
+			Caller: models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser},
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", RedirectURIs: []string{"https://app.test/cb"}},
+			Output: models.Response{Code: 403, ErrorCode: "FORBIDDEN", Message: "forbidden"},
+		},
+		{
+			Name:   "api key cannot create client",
+			Caller: models.SrvCallerModel{UserID: "admin-id", Role: models.RoleAdmin, APIKeyID: "key-id", Permissions: []string{models.PermissionOAuthClient}},
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", RedirectURIs: []string{"https://app.test/cb"}},
+			Output: models.Response{Code: 403, ErrorCode: "FORBIDDEN", Message: "forbidden"},
+		},
+		{
+			Name:   "name required",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: " "},
+			Output: models.Response{Code: 422, ErrorCode: "NAME_REQUIRED", Message: "name is required"},
+		},
+		{
+			Name:   "unknown grant type",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", GrantTypes: []string{"password"}},
+			Output: models.Response{Code: 422, ErrorCode: "GRANT_TYPE_INVALID", Message: "grant type invalid: password"},
+		},
+		{
+			Name:   "public client credentials",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", GrantTypes: []string{models.GrantClientCredentials}},
+			Output: models.Response{Code: 422, ErrorCode: "GRANT_TYPE_INVALID", Message: "client_credentials requires a confidential client"},
+		},
+		{
+			Name:   "redirect uri required",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: "app"},
+			Output: models.Response{Code: 422, ErrorCode: "REDIRECT_URI_REQUIRED", Message: "redirectUris is required for authorization_code"},
+		},
+		{
+			Name:   "redirect uri with fragment",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", RedirectURIs: []string{"https://app.test/cb#x"}},
+			Output: models.Response{Code: 422, ErrorCode: "REDIRECT_URI_INVALID", Message: "redirect uri invalid: https://app.test/cb#x"},
+		},
+		{
+			Name:   "relative redirect uri",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", RedirectURIs: []string{"/cb"}},
+			Output: models.Response{Code: 422, ErrorCode: "REDIRECT_URI_INVALID", Message: "redirect uri invalid: /cb"},
+		},
+		{
+			Name:   "unknown scope",
+			Caller: admin,
+			Input:  models.SrvCreateOAuthClientModel{Name: "app", RedirectURIs: []string{"https://app.test/cb"}, Scopes: []string{"admin"}},
+			Output: models.Response{Code: 422, ErrorCode: "SCOPE_INVALID", Message: "scope invalid: admin"},
+		},
+	}
+
+	oauthSrv := services.NewOAuthService(authorization.NewAuthorizationMock(), repositories.NewUserRepositoryMock(), repositories.NewOAuthClientRepositoryMock(), repositories.NewOAuthCodeRepositoryMock(), repositories.NewRevokedTokenRepositoryMock(), logger.Discard())
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			result := oauthSrv.CreateClient(context.Background(), c.Caller, c.Input)
+			assert.Equal(t, c.Output, result)
+		})
+	}
+
+	t.Run("secret only for confidential client", func(t *testing.T) {
+		srv := newOAuthTestService(t)
+		assert.NotEmpty(t, srv.confidential.Secret)
+		assert.True(t, srv.confidential.Confidential)
+		assert.Empty(t, srv.public.Secret)
+		assert.False(t, srv.public.Confidential)
+		assert.Equal(t, []string{models.GrantAuthorizationCode}, srv.public.GrantTypes)
+	})
+}
+
+func Test_OAuthAuthorize(t *testing.T) {
+	srv := newOAuthTestService(t)
+	user := models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser}
+	valid := authorizeRequest(srv.public, "verifier")
+
+	cases := []struct {
+		Name   string
+		Caller models.SrvCallerModel
+		Input  func(query models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel
+		Output models.Response
+	}{
+		{
+			Name:   "delegated caller",
+			Caller: models.SrvCallerModel{UserID: "user-id", Role: models.RoleUser, ClientID: srv.public.ID},
+			Input:  func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel { return q },
+			Output: models.Response{Code: 403, ErrorCode: "FORBIDDEN", Message: "forbidden"},
+		},
+		{
+			Name:   "unknown client",
+			Caller: user,
+			Input:  func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel { q.ClientID = "missing"; return q },
+			Output: models.Response{Code: 404, ErrorCode: "OAUTH_CLIENT_NOT_FOUND", Message: "oauth client not found"},
+		},
+		{
+			Name:   "redirect uri not registered",
+			Caller: user,
+			Input: func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel {
+				q.RedirectURI = "https://evil.test/callback"
+				return q
+			},
+			Output: models.Response{Code: 400, ErrorCode: "INVALID_REDIRECT_URI", Message: "redirect_uri is not registered for this client"},
+		},
+		{
+			Name:   "redirect uri required with many registered",
+			Caller: user,
+			Input:  func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel { q.RedirectURI = ""; return q },
+			Output: models.Response{Code: 400, ErrorCode: "INVALID_REDIRECT_URI", Message: "redirect_uri is not registered for this client"},
+		},
+		{
+			Name:   "unsupported response type",
+			Caller: user,
+			Input: func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel {
+				q.ResponseType = "token"
+				return q
+			},
+			Output: models.Response{Code: 400, ErrorCode: "UNSUPPORTED_RESPONSE_TYPE", Message: "response_type must be code"},
+		},
+		{
+			Name:   "plain pkce",
+			Caller: user,
+			Input: func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel {
+				q.CodeChallengeMethod = "plain"
+				return q
+			},
+			Output: models.Response{Code: 400, ErrorCode: "INVALID_REQUEST", Message: "code_challenge with method S256 is required"},
+		},
+		{
+			Name:   "scope not allowed",
+			Caller: user,
+			Input:  func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel { q.Scope = "user:list"; return q },
+			Output: models.Response{Code: 400, ErrorCode: "INVALID_SCOPE", Message: "scope is not allowed for this client"},
+		},
+		{
+			Name:   "default scopes",
+			Caller: user,
+			Input:  func(q models.SrvOAuthAuthorizeModel) models.SrvOAuthAuthorizeModel { return q },
+			Output: models.Response{Status: true, Code: 200, Message: "authorize success", Data: models.SrvOAuthConsentResModel{
+				ClientID: srv.public.ID, ClientName: "mobile", Scopes: []string{models.PermissionUserRead}, RedirectURI: "com.mobile:/callback",
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			result := srv.Authorize(context.Background(), c.Caller, c.Input(valid))
+			assert.Equal(t, c.Output, result)
+		})
+	}
+
+	t.Run("denied", func(t *testing.T) {
+		result := srv.Consent(context.Background(), user, models.SrvOAuthConsentModel{SrvOAuthAuthorizeModel: valid})
+		require.Equal(t, 200, result.Code, result.Message)
+		assert.Equal(t, "com.mobile:/callback?error=access_denied&state=xyz", result.Data.(models.SrvOAuthRedirectResModel).RedirectURI)
+	})
+}
+
+func Test_OAuthToken(t *testing.T) {
+	srv := newOAuthTestService(t)
+	public := models.SrvOAuthClientAuthModel{ClientID: srv.public.ID}
+	confidential := models.SrvOAuthClientAuthModel{ClientID: srv.confidential.ID, ClientSecret: srv.confidential.Secret}
+
+	cases := []struct {
+		Name      string
+		Input     func(code string) models.SrvOAuthTokenModel
+		ErrorCode string
+	}{
+		{
+			Name: "wrong secret",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: models.SrvOAuthClientAuthModel{ClientID: srv.confidential.ID, ClientSecret: "wrong"}, GrantType: "client_credentials"}
+			},
+			ErrorCode: "INVALID_CLIENT",
+		},
+		{
+			Name: "unsupported grant",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: public, GrantType: "password"}
+			},
+			ErrorCode: "UNSUPPORTED_GRANT_TYPE",
+		},
+		{
+			Name: "public client credentials",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: public, GrantType: "client_credentials"}
+			},
+			ErrorCode: "UNAUTHORIZED_CLIENT",
+		},
+		{
+			Name: "wrong verifier",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: public, GrantType: "authorization_code", Code: code, RedirectURI: "com.mobile:/callback", CodeVerifier: "other"}
+			},
+			ErrorCode: "INVALID_GRANT",
+		},
+		{
+			Name: "wrong redirect uri",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: public, GrantType: "authorization_code", Code: code, RedirectURI: "https://mobile.test/callback", CodeVerifier: "verifier"}
+			},
+			ErrorCode: "INVALID_GRANT",
+		},
+		{
+			Name: "code of another client",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: confidential, GrantType: "authorization_code", Code: code, RedirectURI: "com.mobile:/callback", CodeVerifier: "verifier"}
+			},
+			ErrorCode: "INVALID_GRANT",
+		},
+		{
+			Name: "missing verifier",
+			Input: func(code string) models.SrvOAuthTokenModel {
+				return models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: public, GrantType: "authorization_code", Code: code, RedirectURI: "com.mobile:/callback"}
+			},
+			ErrorCode: "INVALID_REQUEST",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			code := consentCode(t, srv, authorizeRequest(srv.public, "verifier"))
+			result := srv.Token(context.Background(), c.Input(code))
+			assert.False(t, result.Status)
+			assert.Equal(t, c.ErrorCode, result.ErrorCode, result.Message)
+		})
+	}
+
+	t.Run("authorization code", func(t *testing.T) {
+		code := consentCode(t, srv, authorizeRequest(srv.public, "verifier"))
+		input := models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: public, GrantType: "authorization_code", Code: code, RedirectURI: "com.mobile:/callback", CodeVerifier: "verifier"}
+		result := srv.Token(context.Background(), input)
+		require.Equal(t, 200, result.Code, result.Message)
+		token := result.Data.(models.SrvOAuthTokenResModel)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.Equal(t, "user:read", token.Scope)
+
+		claim := authorization.AppAuthorizationClaim{}
+		require.NoError(t, srv.auth.ValidateToken(context.Background(), token.AccessToken, &claim))
+		assert.Equal(t, "user-id", claim.UserId)
+		assert.Equal(t, models.RoleUser, claim.Role)
+		assert.Equal(t, srv.public.ID, claim.ClientId)
+		assert.Equal(t, "7solutions", claim.Issuer)
+
+		// NOTE code ใช้ได้ครั้งเดียว
+		result = srv.Token(context.Background(), input)
+		assert.Equal(t, "INVALID_GRANT", result.ErrorCode)
+	})
+
+	t.Run("client credentials", func(t *testing.T) {
+		result := srv.Token(context.Background(), models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: confidential, GrantType: "client_credentials", Scope: "user:list"})
+		require.Equal(t, 200, result.Code, result.Message)
+		token := result.Data.(models.SrvOAuthTokenResModel)
+		assert.Equal(t, "user:list", token.Scope)
+
+		claim := authorization.AppAuthorizationClaim{}
+		require.NoError(t, srv.auth.ValidateToken(context.Background(), token.AccessToken, &claim))
+		assert.Empty(t, claim.UserId)
+		assert.Equal(t, srv.confidential.ID, claim.ClientId)
+
+		result = srv.Token(context.Background(), models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: confidential, GrantType: "client_credentials", Scope: "user:delete"})
+		assert.Equal(t, "INVALID_SCOPE", result.ErrorCode)
+	})
+}
+
+func Test_OAuthIntrospectRevoke(t *testing.T) {
+	srv := newOAuthTestService(t)
+	confidential := models.SrvOAuthClientAuthModel{ClientID: srv.confidential.ID, ClientSecret: srv.confidential.Secret}
+	public := models.SrvOAuthClientAuthModel{ClientID: srv.public.ID}
+
+	result := srv.Token(context.Background(), models.SrvOAuthTokenModel{SrvOAuthClientAuthModel: confidential, GrantType: "client_credentials"})
+	require.Equal(t, 200, result.Code, result.Message)
+	token := result.Data.(models.SrvOAuthTokenResModel).AccessToken
+
+	introspect := func(auth models.SrvOAuthClientAuthModel, token string) models.Response {
+		return srv.Introspect(context.Background(), models.SrvOAuthTokenActionModel{SrvOAuthClientAuthModel: auth, Token: token})
+	}
+
+	result = introspect(public, token)
+	assert.Equal(t, "UNAUTHORIZED_CLIENT", result.ErrorCode)
+
+	result = introspect(confidential, token)
+	require.Equal(t, 200, result.Code, result.Message)
+	active := result.Data.(models.SrvOAuthIntrospectResModel)
+	assert.True(t, active.Active)
+	assert.Equal(t, "user:read user:list", active.Scope)
+	assert.Equal(t, srv.confidential.ID, active.ClientID)
+	assert.Equal(t, "7solutions", active.Issuer)
+	assert.NotEmpty(t, active.TokenID)
+
+	result = introspect(confidential, "not-a-token")
+	assert.Equal(t, models.SrvOAuthIntrospectResModel{Active: false}, result.Data)
+
+	// NOTE client อื่น (และ session ของ user ที่ไม่ได้ออกให้ client ไหน) ดูข้อมูลใน token ไม่ได้
+	result = srv.CreateClient(context.Background(), admin, models.SrvCreateOAuthClientModel{
+		Name:         "billing",
+		Scopes:       []string{models.PermissionUserRead},
+		GrantTypes:   []string{models.GrantClientCredentials},
+		Confidential: true,
+	})
+	require.Equal(t, 201, result.Code, result.Message)
+	other := result.Data.(models.SrvCreateOAuthClientResModel)
+	assert.Equal(t, models.SrvOAuthIntrospectResModel{Active: false}, introspect(models.SrvOAuthClientAuthModel{ClientID: other.ID, ClientSecret: other.Secret}, token).Data)
+	session, err := srv.auth.GenerateToken(context.Background(), authorization.AppAuthorizationClaim{UserId: "user-id", Role: models.RoleUser})
+	require.NoError(t, err)
+	assert.Equal(t, models.SrvOAuthIntrospectResModel{Active: false}, introspect(confidential, session).Data)
+
+	// NOTE client อื่นเพิกถอน token ที่ไม่ใช่ของตัวเองไม่ได้ แต่ตอบสำเร็จเหมือนกัน
+	result = srv.Revoke(context.Background(), models.SrvOAuthTokenActionModel{SrvOAuthClientAuthModel: public, Token: token})
+	assert.Equal(t, 200, result.Code)
+	assert.True(t, introspect(confidential, token).Data.(models.SrvOAuthIntrospectResModel).Active)
+
+	result = srv.Revoke(context.Background(), models.SrvOAuthTokenActionModel{SrvOAuthClientAuthModel: confidential, Token: token})
+	assert.Equal(t, 200, result.Code)
+	assert.Equal(t, models.SrvOAuthIntrospectResModel{Active: false}, introspect(confidential, token).Data)
+}
@@ -1,17 +1,61 @@
 package services
 
-import "7solutions/backend/core/models"
+import (
+	"7solutions/backend/core/models"
+	"context"
+	"time"
+)
 
 type UserService interface {
-	CreateUser(payload models.SrvCreateUserModel) (result models.Response)
+	CreateUser(ctx context.Context, payload models.SrvCreateUserModel) (result models.Response)
 
-	GetUserByID(id string) (result models.Response)
+	GetUserByID(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
 
-	SignIn(payload models.SrvSignInModel) (result models.Response)
+	SignIn(ctx context.Context, payload models.SrvSignInModel) (result models.Response)
 
-	Gets() (result models.Response)
+	// แลก mfa token จาก SignIn กับรหัส TOTP หรือ recovery code เป็น access token
+	SignInMFA(ctx context.Context, payload models.SrvMFASignInModel) (result models.Response)
 
-	UpdateUser(id string, payload models.SrvUpdateUserModel) (result models.Response)
+	// เริ่ม login ผ่าน OIDC provider คืน URL หน้า login ของ provider และ state ที่ต้องผูกกับ browser
+	OIDCLogin(ctx context.Context, provider string) (result models.Response)
 
-	DeleteUser(id string) (result models.Response)
+	// แลก code ที่ provider ส่งกลับมาเป็น token ของเรา (ผูกบัญชีกับ user ตาม email ที่ provider ยืนยันแล้ว)
+	OIDCCallback(ctx context.Context, payload models.SrvOIDCCallbackModel) (result models.Response)
+
+	RefreshToken(ctx context.Context, payload models.SrvRefreshTokenModel) (result models.Response)
+
+	SignOut(ctx context.Context, userID string, tokenID string, expiresAt time.Time, payload models.SrvSignOutModel) (result models.Response)
+
+	RevokeUserTokens(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
+
+	// ปลดล็อก user ที่ sign in ผิดเกินกำหนด (เฉพาะผู้มีสิทธิ์ user:unlock)
+	UnlockUser(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
+
+	// สร้าง TOTP secret ใหม่ (ยังไม่เปิดใช้จนกว่าจะ ConfirmMFA)
+	EnrollMFA(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
+
+	// ยืนยันรหัสจาก authenticator แล้วเปิด MFA คืน recovery code (แสดงครั้งเดียว)
+	ConfirmMFA(ctx context.Context, caller models.SrvCallerModel, id string, payload models.SrvMFACodeModel) (result models.Response)
+
+	// ปิด MFA ของ user (เฉพาะผู้มีสิทธิ์ mfa:reset)
+	ResetMFA(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
+
+	Gets(ctx context.Context, caller models.SrvCallerModel, query models.SrvUserQueryModel) (result models.Response)
+
+	UpdateUser(ctx context.Context, caller models.SrvCallerModel, id string, payload models.SrvUpdateUserModel) (result models.Response)
+
+	DeleteUser(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response)
+
+	// เปลี่ยนรหัสผ่านของตัวเอง (ต้องยืนยันรหัสผ่านเดิม) แล้วเพิกถอน session ทั้งหมด
+	ChangePassword(ctx context.Context, caller models.SrvCallerModel, id string, payload models.SrvChangePasswordModel) (result models.Response)
+
+	// ส่ง token สำหรับตั้งรหัสผ่านใหม่ทาง notifier (ตอบเหมือนกันไม่ว่าจะมี email หรือไม่)
+	ForgotPassword(ctx context.Context, payload models.SrvForgotPasswordModel) (result models.Response)
+
+	ResetPassword(ctx context.Context, payload models.SrvResetPasswordModel) (result models.Response)
+
+	VerifyEmail(ctx context.Context, token string) (result models.Response)
+
+	// ส่ง token ยืนยัน email ใหม่ (ตอบเหมือนกันไม่ว่าจะมี email หรือไม่)
+	ResendVerification(ctx context.Context, payload models.SrvResendVerificationModel) (result models.Response)
 }
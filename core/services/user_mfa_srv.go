@@ -0,0 +1,298 @@
+package services
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/totp"
+	"7solutions/backend/common/tracing"
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	errInvalidMFAToken = apperror.Unauthorized("MFA_TOKEN_INVALID", "invalid or expired mfa token")
+	errInvalidMFACode  = apperror.Unauthorized("MFA_CODE_INVALID", "invalid mfa code")
+)
+
+// key สำหรับเข้ารหัส TOTP secret ก่อนเก็บลงฐานข้อมูล
+func mfaKey() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(config.Env.MFAEncryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, apperror.Internal(errors.New("MFA_ENCRYPTION_KEY must be base64 of 32 bytes"))
+	}
+	return key, nil
+}
+
+func (s *userSrv) EnrollMFA(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.EnrollMFA")
+	defer endSpan(span, &result)
+
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	// NOTE ต้องสแกนด้วยอุปกรณ์ของตัวเอง จึงทำได้เฉพาะของตัวเองและต้อง sign in (ไม่ใช่ API key หรือ OAuth client)
+	if caller.UserID != id || caller.Delegated() {
+		return s.failure(errForbidden)
+	}
+	key, err := mfaKey()
+	if err != nil {
+		return s.failure(err)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return s.failure(err)
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return s.failure(err)
+	}
+	encrypted, err := utils.AES_Encrypt(key, secret)
+	if err != nil {
+		return s.failure(err)
+	}
+	_, err = s.mfaRepo.CreateMFA(ctx, models.RepoCreateMFAModel{
+		UserID:   id,
+		Secret:   encrypted,
+		CreateAt: time.Now(),
+	})
+	if err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "enroll mfa success",
+		Code:    200,
+		Data: models.SrvMFAEnrollResModel{
+			Secret: secret,
+			URI:    totp.URI(config.Env.MFAIssuer, user.Email, secret),
+		},
+	}
+	return result
+}
+
+func (s *userSrv) ConfirmMFA(ctx context.Context, caller models.SrvCallerModel, id string, payload models.SrvMFACodeModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.ConfirmMFA")
+	defer endSpan(span, &result)
+
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if caller.UserID != id || caller.Delegated() {
+		return s.failure(errForbidden)
+	}
+	if payload.Code == "" {
+		return s.failure(apperror.Validation("MFA_CODE_REQUIRED", "mfa code is required"))
+	}
+
+	mfa, err := s.mfaRepo.GetMFAByUserID(ctx, id)
+	if err != nil {
+		return s.failure(err)
+	}
+	if mfa.Enabled {
+		return s.failure(repositories.ErrMFAAlreadyEnabled)
+	}
+	ok, err := s.verifyTOTP(ctx, mfa, payload.Code)
+	if err != nil {
+		return s.failure(err)
+	}
+	if !ok {
+		return s.failure(apperror.Validation("MFA_CODE_INVALID", "invalid mfa code"))
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return s.failure(err)
+	}
+	if _, err := s.mfaRepo.EnableMFA(ctx, id, hashes, time.Now()); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "confirm mfa success",
+		Code:    200,
+		Data:    models.SrvMFARecoveryCodesResModel{RecoveryCodes: codes},
+	}
+	return result
+}
+
+func (s *userSrv) ResetMFA(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.ResetMFA")
+	defer endSpan(span, &result)
+
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.Can(models.PermissionMFAReset) {
+		return s.failure(errForbidden)
+	}
+
+	if err := s.mfaRepo.DeleteMFA(ctx, id); err != nil {
+		return s.failure(err)
+	}
+	if err := s.loginAttemptRepo.ResetLoginAttempt(ctx, loginMFAKey(id)); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "reset mfa success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *userSrv) SignInMFA(ctx context.Context, payload models.SrvMFASignInModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.SignInMFA")
+	defer endSpan(span, &result)
+	defer s.countSignIn("mfa", &result)
+
+	if payload.MFAToken == "" {
+		return s.failure(apperror.Validation("MFA_TOKEN_REQUIRED", "mfa token is required"))
+	}
+	if payload.Code == "" {
+		return s.failure(apperror.Validation("MFA_CODE_REQUIRED", "mfa code is required"))
+	}
+
+	hash := utils.Token_Hash(payload.MFAToken)
+	mfa, err := s.mfaRepo.GetMFAByChallengeHash(ctx, hash)
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(err)
+	}
+	if err != nil || !mfa.Enabled || mfa.ChallengeExpiresAt == nil || time.Now().After(*mfa.ChallengeExpiresAt) {
+		return s.failure(errInvalidMFAToken)
+	}
+
+	// NOTE รหัสมีแค่ 6 หลัก ต้องจำกัดจำนวนครั้งที่เดาได้ต่อ user
+	key := loginMFAKey(mfa.UserID)
+	if err := s.checkLoginLock(ctx, key); err != nil {
+		return s.failure(err)
+	}
+	ok, err := s.verifyMFACode(ctx, mfa, payload.Code)
+	if err != nil {
+		return s.failure(err)
+	}
+	if !ok {
+		if err := s.addFailure(ctx, key, config.Env.LoginMaxAttempts, time.Now()); err != nil {
+			return s.failure(err)
+		}
+		return s.failure(errInvalidMFACode)
+	}
+
+	// NOTE mfa token ใช้ได้ครั้งเดียว
+	err = s.mfaRepo.UseMFAChallenge(ctx, mfa.UserID, hash)
+	if errors.Is(err, repositories.ErrMFAChallengeNotFound) {
+		return s.failure(errInvalidMFAToken)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+	if err := s.loginAttemptRepo.ResetLoginAttempt(ctx, key); err != nil {
+		return s.failure(err)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, mfa.UserID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(errInvalidMFAToken)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+	return s.completeSignIn(ctx, user)
+}
+
+// ออก mfa token ให้นำไปแลก access token ที่ /api/signin/mfa
+func (s *userSrv) startMFAChallenge(ctx context.Context, userID string) (result models.SrvMFAChallengeResModel, err error) {
+	token, err := utils.Token_Random(32)
+	if err != nil {
+		return result, err
+	}
+	err = s.mfaRepo.SetMFAChallenge(ctx, userID, utils.Token_Hash(token), time.Now().Add(config.Env.MFAChallengeExp))
+	if err != nil {
+		return result, err
+	}
+
+	result = models.SrvMFAChallengeResModel{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int64(config.Env.MFAChallengeExp.Seconds()),
+	}
+	return result, nil
+}
+
+func loginMFAKey(userID string) string {
+	return "mfa:" + userID
+}
+
+// รับได้ทั้งรหัส TOTP และ recovery code (ใช้ได้ครั้งเดียวทั้งคู่)
+func (s *userSrv) verifyMFACode(ctx context.Context, mfa models.RepoResMFAModel, code string) (bool, error) {
+	code = normalizeMFACode(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(ctx, mfa, code)
+	}
+
+	err := s.mfaRepo.UseMFARecoveryCode(ctx, mfa.UserID, utils.Token_Hash(code))
+	if errors.Is(err, repositories.ErrMFARecoveryCodeNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ตรวจรหัส TOTP (ยอมให้นาฬิกาคลาด 1 ช่วง) รหัสที่ใช้ไปแล้วใช้ซ้ำไม่ได้
+func (s *userSrv) verifyTOTP(ctx context.Context, mfa models.RepoResMFAModel, code string) (bool, error) {
+	key, err := mfaKey()
+	if err != nil {
+		return false, err
+	}
+	secret, err := utils.AES_Decrypt(key, mfa.Secret)
+	if err != nil {
+		return false, apperror.Internal(err)
+	}
+
+	step, ok := totp.Validate(secret, normalizeMFACode(code), time.Now(), 1)
+	if !ok {
+		return false, nil
+	}
+	err = s.mfaRepo.UseMFAStep(ctx, mfa.UserID, step)
+	if errors.Is(err, repositories.ErrMFACodeUsed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NOTE ผู้ใช้อาจพิมพ์ช่องว่าง ขีด หรือตัวพิมพ์ใหญ่มาด้วย
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+const recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// สุ่ม recovery code รูปแบบ xxxxx-xxxxx คืนทั้งรหัสสำหรับแสดงครั้งเดียวและ hash สำหรับเก็บ
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	for range recoveryCodeCount {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		// NOTE 256 หารด้วย 32 ลงตัว จึงสุ่มได้เท่ากันทุกตัวอักษร
+		for i := range buf {
+			buf[i] = recoveryCodeAlphabet[int(buf[i])%len(recoveryCodeAlphabet)]
+		}
+		code := string(buf[:5]) + "-" + string(buf[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, utils.Token_Hash(normalizeMFACode(code)))
+	}
+	return codes, hashes, nil
+}
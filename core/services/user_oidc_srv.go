@@ -0,0 +1,224 @@
+package services
+
+import (
+	"7solutions/backend/common/apperror"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/common/tracing"
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/utils"
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errOIDCProviderNotFound = apperror.NotFound("OIDC_PROVIDER_NOT_FOUND", "oidc provider not found")
+	errInvalidOIDCState     = apperror.Unauthorized("OIDC_STATE_INVALID", "invalid or expired oidc state")
+	errOIDCLoginFailed      = apperror.Unauthorized("OIDC_LOGIN_FAILED", "oidc login failed")
+	errOIDCEmailNotVerified = apperror.Forbidden("OIDC_EMAIL_NOT_VERIFIED", "email not verified by oidc provider")
+
+	// NOTE บัญชีที่ยังไม่ยืนยัน email อาจถูกคนอื่นสมัครดักไว้ ผูกแล้วเขายังเข้าด้วยรหัสผ่านเดิมได้
+	errOIDCAccountNotVerified = apperror.Forbidden("OIDC_ACCOUNT_NOT_VERIFIED", "verify the email of the existing account before signing in with this provider")
+)
+
+func (s *userSrv) OIDCLogin(ctx context.Context, providerName string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.OIDCLogin")
+	defer endSpan(span, &result)
+
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return s.failure(errOIDCProviderNotFound)
+	}
+
+	state, err := utils.Token_Random(32)
+	if err != nil {
+		return s.failure(err)
+	}
+	nonce, err := utils.Token_Random(32)
+	if err != nil {
+		return s.failure(err)
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return s.failure(err)
+	}
+	url, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return s.failure(err)
+	}
+
+	now := time.Now()
+	_, err = s.oidcStateRepo.CreateOIDCState(ctx, models.RepoCreateOIDCStateModel{
+		StateHash:    utils.Token_Hash(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(config.Env.OIDCStateExp),
+		CreateAt:     now,
+	})
+	if err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "oidc login",
+		Code:    200,
+		Data:    models.SrvOIDCLoginResModel{URL: url, State: state},
+	}
+	return result
+}
+
+func (s *userSrv) OIDCCallback(ctx context.Context, payload models.SrvOIDCCallbackModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.OIDCCallback")
+	defer endSpan(span, &result)
+	defer s.countSignIn("oidc", &result)
+
+	provider, ok := s.oidcProviders[payload.Provider]
+	if !ok {
+		return s.failure(errOIDCProviderNotFound)
+	}
+	if payload.Error != "" {
+		s.logger.Warn("oidc provider returned error", "provider", payload.Provider, "error", payload.Error, "description", payload.ErrorDescription)
+		return s.failure(errOIDCLoginFailed)
+	}
+	if payload.Code == "" || payload.State == "" {
+		return s.failure(apperror.Validation("CODE_REQUIRED", "code and state are required"))
+	}
+
+	// NOTE state ต้องตรงกับ cookie ของ browser ที่เริ่ม login (กัน login CSRF) และใช้ได้ครั้งเดียว
+	if subtle.ConstantTimeCompare([]byte(payload.State), []byte(payload.BrowserState)) != 1 {
+		return s.failure(errInvalidOIDCState)
+	}
+	state, err := s.oidcStateRepo.UseOIDCState(ctx, utils.Token_Hash(payload.State))
+	if apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(errInvalidOIDCState)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+	if state.Provider != payload.Provider || time.Now().After(state.ExpiresAt) {
+		return s.failure(errInvalidOIDCState)
+	}
+
+	exchangeCtx, exchangeSpan := tracing.Start(ctx, "oidc.Exchange")
+	idToken, err := provider.Exchange(exchangeCtx, payload.Code, state.CodeVerifier)
+	tracing.End(exchangeSpan, err)
+	if err != nil {
+		return s.failure(s.oidcFailure(ctx, payload.Provider, err))
+	}
+	verifyCtx, verifySpan := tracing.Start(ctx, "oidc.VerifyIDToken")
+	claims, err := provider.VerifyIDToken(verifyCtx, idToken, state.Nonce)
+	tracing.End(verifySpan, err)
+	if err != nil {
+		return s.failure(s.oidcFailure(ctx, payload.Provider, err))
+	}
+
+	user, err := s.oidcUser(ctx, payload.Provider, claims)
+	if err != nil {
+		return s.failure(err)
+	}
+	return s.signInUser(ctx, user)
+}
+
+// user ที่ผูกกับบัญชีนี้ของ provider ถ้ายังไม่ผูกจะผูกกับ user ที่มี email เดียวกัน (หรือสร้างใหม่)
+// เฉพาะ email ที่ provider ยืนยันแล้ว และ user เดิมต้องยืนยัน email แล้วเช่นกัน
+func (s *userSrv) oidcUser(ctx context.Context, provider string, claims oidc.Claims) (user models.RepoResUserModel, err error) {
+	identity, err := s.identityRepo.GetIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		user, err = s.userRepo.GetUserByID(ctx, identity.UserID)
+		if !apperror.Is(err, apperror.KindNotFound) {
+			return user, err
+		}
+		// NOTE user ถูกลบไปแล้ว ลบรายการที่ค้างแล้วผูกใหม่ตาม email
+		if err := s.identityRepo.DeleteIdentity(ctx, identity.ID); err != nil && !apperror.Is(err, apperror.KindNotFound) {
+			return user, err
+		}
+	} else if !apperror.Is(err, apperror.KindNotFound) {
+		return user, err
+	}
+
+	email := utils.Email_Normalize(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return user, errOIDCEmailNotVerified
+	}
+	if !utils.Email_Valid(email) {
+		return user, apperror.Validation("EMAIL_INVALID", "email invalid")
+	}
+
+	user, err = s.userRepo.GetUserByEmail(ctx, email)
+	switch {
+	case apperror.Is(err, apperror.KindNotFound):
+		if user, err = s.createOIDCUser(ctx, email, claims.Name); err != nil {
+			return user, err
+		}
+	case err != nil:
+		return user, err
+	case !user.EmailVerified:
+		return user, errOIDCAccountNotVerified
+	}
+
+	_, err = s.identityRepo.CreateIdentity(ctx, models.RepoCreateIdentityModel{
+		ID:       uuid.New().String(),
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    email,
+		CreateAt: time.Now(),
+	})
+	if err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+// user ใหม่จาก OIDC ยังไม่มีรหัสผ่าน (ตั้งได้ที่ /api/forgot-password)
+func (s *userSrv) createOIDCUser(ctx context.Context, email string, name string) (user models.RepoResUserModel, err error) {
+	if name == "" {
+		name = email[:strings.Index(email, "@")]
+	}
+	password, err := utils.Token_Random(32)
+	if err != nil {
+		return user, err
+	}
+	hashPassword, err := bcryptHash(ctx, password)
+	if err != nil {
+		return user, apperror.Internal(err)
+	}
+
+	user, err = s.userRepo.CreateUser(ctx, models.RepoCreateUserModel{
+		ID:       uuid.New().String(),
+		Name:     name,
+		Email:    email,
+		Password: hashPassword,
+		Role:     models.RoleUser,
+		CreateAt: time.Now(),
+	})
+	if err != nil {
+		return user, err
+	}
+	err = s.notify.Notify(notifier.Message{
+		To:       user.Email,
+		Template: notifier.TemplateWelcome,
+		Data:     map[string]string{"name": user.Name},
+	})
+	if err != nil {
+		s.logger.Warn("send welcome email", "user_id", user.ID, "error", err)
+	}
+	verified := true
+	return s.userRepo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{EmailVerified: &verified})
+}
+
+// NOTE request หมดเวลาหรือ client ตัดการเชื่อมต่อระหว่างคุยกับ provider ตอบ TIMEOUT ไม่ใช่ login ไม่ผ่าน
+func (s *userSrv) oidcFailure(ctx context.Context, provider string, err error) error {
+	if ctx.Err() != nil {
+		return apperror.Timeout(err)
+	}
+	s.logger.Warn("oidc login failed", "provider", provider, "error", err)
+	return errOIDCLoginFailed
+}
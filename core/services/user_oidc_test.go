@@ -0,0 +1,256 @@
+package services_test
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/repositories"
+	"7solutions/backend/core/services"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type oidcTestService struct {
+	services.UserService
+	userRepo     repositories.UserRepository
+	identityRepo repositories.IdentityRepository
+}
+
+func newOIDCTestService(t *testing.T, provider oidc.Provider) oidcTestService {
+	auth := authorization.NewAuthorizationMock()
+	auth.On("GenerateToken", mock.Anything, mock.AnythingOfType("authorization.AppAuthorizationClaim")).Return("token", nil)
+	notify := notifier.NewNotifierMock()
+	notify.On("Notify", mock.AnythingOfType("notifier.Message")).Return(nil)
+	userRepo := repositories.NewUserMemoryRepository()
+	identityRepo := repositories.NewIdentityMemoryRepository()
+
+	_, err := userRepo.CreateUser(context.Background(), models.RepoCreateUserModel{ID: "old-id", Name: "Old", Email: "old@test.com", Role: models.RoleUser, CreateAt: time.Now()})
+	require.NoError(t, err)
+	verified := true
+	_, err = userRepo.UpdateUser(context.Background(), "old-id", models.RepoUpdateUserModel{EmailVerified: &verified})
+	require.NoError(t, err)
+	// NOTE สมัครดักไว้ด้วย email ของคนอื่น ยังไม่เคยยืนยัน
+	_, err = userRepo.CreateUser(context.Background(), models.RepoCreateUserModel{ID: "squatter-id", Name: "Squatter", Email: "victim@test.com", Password: "attacker-hash", Role: models.RoleUser, CreateAt: time.Now()})
+	require.NoError(t, err)
+
+	userSrv := services.NewUserService(auth, userRepo, repositories.NewRefreshTokenMemoryRepository(), repositories.NewRevokedTokenRepositoryMock(), repositories.NewPasswordResetRepositoryMock(), repositories.NewEmailVerificationRepositoryMock(), repositories.NewLoginAttemptMemoryRepository(), repositories.NewMFAMemoryRepository(), identityRepo, repositories.NewOIDCStateMemoryRepository(), oidc.Providers{"stub": provider}, notify, logger.Discard(), metrics.New())
+	return oidcTestService{UserService: userSrv, userRepo: userRepo, identityRepo: identityRepo}
+}
+
+func newOIDCProviderMock(claims oidc.Claims, verifyErr error) *oidc.MockProvider {
+	provider := oidc.NewProviderMock()
+	provider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("https://idp.test/authorize", nil)
+	provider.On("Exchange", mock.Anything, "code", mock.Anything).Return("id-token", nil)
+	provider.On("VerifyIDToken", mock.Anything, "id-token", mock.Anything).Return(claims, verifyErr)
+	return provider
+}
+
+// เริ่ม login คืน state ที่ handler จะเก็บใน cookie ของ browser
+func oidcLogin(t *testing.T, userSrv services.UserService) string {
+	result := userSrv.OIDCLogin(context.Background(), "stub")
+	require.Equal(t, 200, result.Code, result.Message)
+	return result.Data.(models.SrvOIDCLoginResModel).State
+}
+
+func Test_OIDCCallback(t *testing.T) {
+	type test struct {
+		Name      string
+		Claims    oidc.Claims
+		VerifyErr error
+		Payload   func(state string) models.SrvOIDCCallbackModel
+		Expect    struct {
+			Code      int
+			ErrorCode string
+			UserID    string
+		}
+	}
+	callback := func(state string) models.SrvOIDCCallbackModel {
+		return models.SrvOIDCCallbackModel{Provider: "stub", Code: "code", State: state, BrowserState: state}
+	}
+
+	tests := []test{
+		{
+			Name:    "create user",
+			Claims:  oidc.Claims{Subject: "s1", Email: "new@test.com", EmailVerified: true, Name: "New"},
+			Payload: callback,
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 200},
+		},
+		{
+			Name:    "link existing user by email",
+			Claims:  oidc.Claims{Subject: "s1", Email: " Old@Test.com", EmailVerified: true},
+			Payload: callback,
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 200, UserID: "old-id"},
+		},
+		{
+			Name:    "existing user not verified",
+			Claims:  oidc.Claims{Subject: "s1", Email: "victim@test.com", EmailVerified: true},
+			Payload: callback,
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 403, ErrorCode: "OIDC_ACCOUNT_NOT_VERIFIED"},
+		},
+		{
+			Name:    "email not verified",
+			Claims:  oidc.Claims{Subject: "s1", Email: "old@test.com"},
+			Payload: callback,
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 403, ErrorCode: "OIDC_EMAIL_NOT_VERIFIED"},
+		},
+		{
+			Name:      "invalid id token",
+			VerifyErr: errors.New("oidc: invalid id token"),
+			Payload:   callback,
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 401, ErrorCode: "OIDC_LOGIN_FAILED"},
+		},
+		{
+			Name: "state from another browser",
+			Payload: func(state string) models.SrvOIDCCallbackModel {
+				return models.SrvOIDCCallbackModel{Provider: "stub", Code: "code", State: state, BrowserState: "other"}
+			},
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 401, ErrorCode: "OIDC_STATE_INVALID"},
+		},
+		{
+			Name: "unknown state",
+			Payload: func(state string) models.SrvOIDCCallbackModel {
+				return models.SrvOIDCCallbackModel{Provider: "stub", Code: "code", State: "other", BrowserState: "other"}
+			},
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 401, ErrorCode: "OIDC_STATE_INVALID"},
+		},
+		{
+			Name: "provider returned error",
+			Payload: func(state string) models.SrvOIDCCallbackModel {
+				return models.SrvOIDCCallbackModel{Provider: "stub", State: state, BrowserState: state, Error: "access_denied"}
+			},
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 401, ErrorCode: "OIDC_LOGIN_FAILED"},
+		},
+		{
+			Name: "unknown provider",
+			Payload: func(state string) models.SrvOIDCCallbackModel {
+				return models.SrvOIDCCallbackModel{Provider: "other", Code: "code", State: state, BrowserState: state}
+			},
+			Expect: struct {
+				Code      int
+				ErrorCode string
+				UserID    string
+			}{Code: 404, ErrorCode: "OIDC_PROVIDER_NOT_FOUND"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			userSrv := newOIDCTestService(t, newOIDCProviderMock(tt.Claims, tt.VerifyErr))
+			state := oidcLogin(t, userSrv)
+
+			result := userSrv.OIDCCallback(context.Background(), tt.Payload(state))
+			require.Equal(t, tt.Expect.Code, result.Code, result.Message)
+			assert.Equal(t, tt.Expect.ErrorCode, result.ErrorCode)
+			if result.Code != 200 {
+				// NOTE ล้มเหลวต้องไม่ผูกบัญชี
+				_, err := userSrv.identityRepo.GetIdentity(context.Background(), "stub", "s1")
+				assert.ErrorIs(t, err, repositories.ErrIdentityNotFound)
+				return
+			}
+			assert.IsType(t, models.SrvSignInResModel{}, result.Data)
+
+			identity, err := userSrv.identityRepo.GetIdentity(context.Background(), "stub", tt.Claims.Subject)
+			require.NoError(t, err)
+			if tt.Expect.UserID != "" {
+				assert.Equal(t, tt.Expect.UserID, identity.UserID)
+			}
+			user, err := userSrv.userRepo.GetUserByID(context.Background(), identity.UserID)
+			require.NoError(t, err)
+			assert.True(t, user.EmailVerified)
+			assert.NotNil(t, user.LastLoginAt)
+
+			// NOTE state ใช้ได้ครั้งเดียว
+			result = userSrv.OIDCCallback(context.Background(), tt.Payload(state))
+			assert.Equal(t, "OIDC_STATE_INVALID", result.ErrorCode)
+		})
+	}
+}
+
+func Test_OIDCIdentity(t *testing.T) {
+	claims := oidc.Claims{Subject: "s1", Email: "new@test.com", EmailVerified: true, Name: "New"}
+	provider := oidc.NewProviderMock()
+	provider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("https://idp.test/authorize", nil)
+	provider.On("Exchange", mock.Anything, "code", mock.Anything).Return("id-token", nil)
+	userSrv := newOIDCTestService(t, provider)
+
+	signIn := func() models.RepoResIdentityModel {
+		provider.On("VerifyIDToken", mock.Anything, "id-token", mock.Anything).Return(claims, nil).Once()
+		state := oidcLogin(t, userSrv)
+		result := userSrv.OIDCCallback(context.Background(), models.SrvOIDCCallbackModel{Provider: "stub", Code: "code", State: state, BrowserState: state})
+		require.Equal(t, 200, result.Code, result.Message)
+		identity, err := userSrv.identityRepo.GetIdentity(context.Background(), "stub", "s1")
+		require.NoError(t, err)
+		return identity
+	}
+
+	first := signIn()
+
+	// NOTE ผูกแล้วใช้ subject ไม่ใช่ email (เปลี่ยน email ที่ provider ก็ยังเป็น user เดิม)
+	claims.Email = "changed@test.com"
+	assert.Equal(t, first.UserID, signIn().UserID)
+	_, err := userSrv.userRepo.GetUserByEmail(context.Background(), "changed@test.com")
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+
+	// NOTE user ถูกลบ -> ผูกใหม่ตาม email
+	require.NoError(t, userSrv.userRepo.DeleteUser(context.Background(), first.UserID))
+	second := signIn()
+	assert.NotEqual(t, first.UserID, second.UserID)
+	user, err := userSrv.userRepo.GetUserByID(context.Background(), second.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, "changed@test.com", user.Email)
+}
+
+func Test_OIDCCallbackCanceled(t *testing.T) {
+	provider := oidc.NewProviderMock()
+	provider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("https://idp.test/authorize", nil)
+	userSrv := newOIDCTestService(t, provider)
+	state := oidcLogin(t, userSrv)
+
+	// NOTE client ตัดการเชื่อมต่อระหว่างรอ provider -> TIMEOUT ไม่ใช่ login ไม่ผ่าน
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	provider.On("Exchange", mock.Anything, "code", mock.Anything).Run(func(mock.Arguments) { cancel() }).Return("", context.Canceled)
+	result := userSrv.OIDCCallback(ctx, models.SrvOIDCCallbackModel{Provider: "stub", Code: "code", State: state, BrowserState: state})
+	assert.Equal(t, "TIMEOUT", result.ErrorCode)
+}
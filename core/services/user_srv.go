@@ -1,88 +1,116 @@
 package services
 
 import (
+	"7solutions/backend/common/apperror"
 	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/common/tracing"
+	"7solutions/backend/config"
 	"7solutions/backend/core/models"
 	"7solutions/backend/core/repositories"
 	"7solutions/backend/utils"
-	"net/mail"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type userSrv struct {
-	auth     authorization.AppAuthorization
-	userRepo repositories.UserRepository
+	auth              authorization.AppAuthorization
+	userRepo          repositories.UserRepository
+	refreshTokenRepo  repositories.RefreshTokenRepository
+	revokedTokenRepo  repositories.RevokedTokenRepository
+	passwordResetRepo repositories.PasswordResetRepository
+	verificationRepo  repositories.EmailVerificationRepository
+	loginAttemptRepo  repositories.LoginAttemptRepository
+	mfaRepo           repositories.MFARepository
+	identityRepo      repositories.IdentityRepository
+	oidcStateRepo     repositories.OIDCStateRepository
+	oidcProviders     oidc.Providers
+	notify            notifier.Notifier
+	metrics           *metrics.Metrics
+	serviceLogger
 }
 
-func NewUserService(auth authorization.AppAuthorization, userRepo repositories.UserRepository) UserService {
+func NewUserService(auth authorization.AppAuthorization, userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, revokedTokenRepo repositories.RevokedTokenRepository, passwordResetRepo repositories.PasswordResetRepository, verificationRepo repositories.EmailVerificationRepository, loginAttemptRepo repositories.LoginAttemptRepository, mfaRepo repositories.MFARepository, identityRepo repositories.IdentityRepository, oidcStateRepo repositories.OIDCStateRepository, oidcProviders oidc.Providers, notify notifier.Notifier, logger *slog.Logger, m *metrics.Metrics) UserService {
 	return &userSrv{
-		auth:     auth,
-		userRepo: userRepo,
+		auth:              auth,
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		revokedTokenRepo:  revokedTokenRepo,
+		passwordResetRepo: passwordResetRepo,
+		verificationRepo:  verificationRepo,
+		loginAttemptRepo:  loginAttemptRepo,
+		mfaRepo:           mfaRepo,
+		identityRepo:      identityRepo,
+		oidcStateRepo:     oidcStateRepo,
+		oidcProviders:     oidcProviders,
+		notify:            notify,
+		metrics:           m,
+		serviceLogger:     serviceLogger{logger: logger},
 	}
 }
 
-func (s *userSrv) CreateUser(payload models.SrvCreateUserModel) (result models.Response) {
+func (s *userSrv) CreateUser(ctx context.Context, payload models.SrvCreateUserModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.CreateUser")
+	defer endSpan(span, &result)
+
+	payload.Email = utils.Email_Normalize(payload.Email)
 	if payload.Name == "" {
-		return models.Response{
-			Status:  false,
-			Message: "name is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("NAME_REQUIRED", "name is required"))
 	}
 	if payload.Email == "" {
-		return models.Response{
-			Status:  false,
-			Message: "email is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("EMAIL_REQUIRED", "email is required"))
 	}
 	if payload.Password == "" {
-		return models.Response{
-			Status:  false,
-			Message: "password is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("PASSWORD_REQUIRED", "password is required"))
 	}
 
-	_, err := mail.ParseAddress(payload.Email)
+	if !utils.Email_Valid(payload.Email) {
+		return s.failure(apperror.Validation("EMAIL_INVALID", "email invalid"))
+	}
+	hashPassword, err := bcryptHash(ctx, payload.Password)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: "email invalid",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(err)
 	}
-	hashPassword, _ := utils.Bcryp_Encryption(payload.Password)
 
 	payloadCreate := models.RepoCreateUserModel{
 		ID:       uuid.New().String(),
 		Name:     payload.Name,
 		Email:    payload.Email,
 		Password: hashPassword,
+		Role:     models.RoleUser,
 		CreateAt: time.Now(),
 	}
-	res, err := s.userRepo.CreateUser(payloadCreate)
+	res, err := s.userRepo.CreateUser(ctx, payloadCreate)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(err)
 	}
-	data := models.SrvResUserModel{
-		ID:       res.ID,
-		Name:     res.Name,
-		Email:    res.Email,
-		Password: res.Password,
-		CreateAt: res.CreateAt.Format("2006-01-02 15:04:05"),
+	// NOTE user ถูกสร้างแล้ว ส่งไม่สำเร็จให้ขอส่งใหม่ที่ /api/verify-email/resend
+	if err := s.sendVerification(ctx, res); err != nil {
+		s.logger.Warn("send verification email", "user_id", res.ID, "error", err)
 	}
+	err = s.notify.Notify(notifier.Message{
+		To:       res.Email,
+		Template: notifier.TemplateWelcome,
+		Data:     map[string]string{"name": res.Name},
+	})
+	if err != nil {
+		s.logger.Warn("send welcome email", "user_id", res.ID, "error", err)
+	}
+	data := userResponse(models.SrvCallerModel{}, res)
 	result = models.Response{
 		Status:  true,
 		Message: "create user success",
@@ -92,32 +120,22 @@ func (s *userSrv) CreateUser(payload models.SrvCreateUserModel) (result models.R
 	return result
 }
 
-func (s *userSrv) GetUserByID(id string) (result models.Response) {
+func (s *userSrv) GetUserByID(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.GetUserByID")
+	defer endSpan(span, &result)
+
 	if id == "" {
-		return models.Response{
-			Status:  false,
-			Message: "id is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.CanAccess(id, models.PermissionUserRead) {
+		return s.failure(errForbidden)
 	}
-	res, err := s.userRepo.GetUserByID(id)
+	res, err := s.userRepo.GetUserByID(ctx, id)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(err)
 	}
 
-	data := models.SrvResUserModel{
-		ID:       res.ID,
-		Name:     res.Name,
-		Email:    res.Email,
-		Password: res.Password,
-		CreateAt: res.CreateAt.Format("2006-01-02 15:04:05"),
-	}
+	data := userResponse(caller, res)
 	result = models.Response{
 		Status:  true,
 		Message: "get user success",
@@ -127,152 +145,525 @@ func (s *userSrv) GetUserByID(id string) (result models.Response) {
 	return result
 }
 
-func (s *userSrv) SignIn(payload models.SrvSignInModel) (result models.Response) {
+func (s *userSrv) SignIn(ctx context.Context, payload models.SrvSignInModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.SignIn")
+	defer endSpan(span, &result)
+	defer s.countSignIn("password", &result)
+
+	payload.Email = utils.Email_Normalize(payload.Email)
 	if payload.Email == "" {
-		return models.Response{
-			Status:  false,
-			Message: "email is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("EMAIL_REQUIRED", "email is required"))
 	}
 	if payload.Password == "" {
-		return models.Response{
-			Status:  false,
-			Message: "password is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("PASSWORD_REQUIRED", "password is required"))
+	}
+	if !utils.Email_Valid(payload.Email) {
+		return s.failure(apperror.Validation("EMAIL_INVALID", "email invalid"))
+	}
+	if err := s.checkLoginAttempts(ctx, payload.Email, payload.IP); err != nil {
+		return s.failure(err)
+	}
+
+	// NOTE email ที่ไม่มีในระบบกับรหัสผ่านผิดต้องตอบเหมือนกันและใช้เวลาพอๆ กัน
+	user, err := s.userRepo.GetUserByEmail(ctx, payload.Email)
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(err)
 	}
-	_, err := mail.ParseAddress(payload.Email)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: "email invalid",
-			Code:    400,
-			Data:    nil,
+		bcryptCompare(ctx, dummyPasswordHash(), payload.Password)
+	}
+	if err != nil || !bcryptCompare(ctx, user.Password, payload.Password) {
+		if err := s.addLoginFailure(ctx, payload.Email, payload.IP); err != nil {
+			return s.failure(err)
 		}
+		return s.failure(errInvalidCredentials)
+	}
+	if err := s.loginAttemptRepo.ResetLoginAttempt(ctx, loginEmailKey(payload.Email)); err != nil {
+		return s.failure(err)
+	}
+	if config.Env.RequireEmailVerified && !user.EmailVerified {
+		return s.failure(apperror.Forbidden("EMAIL_NOT_VERIFIED", "email not verified"))
 	}
-	user, err := s.userRepo.GetUserByEmail(payload.Email)
+
+	return s.signInUser(ctx, user)
+}
+
+// user ยืนยันตัวตนแล้ว (รหัสผ่านหรือ OIDC) ถ้าเปิด MFA ไว้ให้ยืนยันรหัสก่อน ไม่งั้นออก token
+func (s *userSrv) signInUser(ctx context.Context, user models.RepoResUserModel) (result models.Response) {
+	// NOTE เปิด MFA ไว้ ยังไม่ออก token จนกว่าจะยืนยันรหัสที่ /api/signin/mfa
+	mfa, err := s.mfaRepo.GetMFAByUserID(ctx, user.ID)
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(err)
+	}
+	if err == nil && mfa.Enabled {
+		data, err := s.startMFAChallenge(ctx, user.ID)
+		if err != nil {
+			return s.failure(err)
+		}
+		result = models.Response{
+			Status:  true,
+			Message: "mfa required",
+			Code:    200,
+			Data:    data,
+		}
+		return result
+	}
+
+	return s.completeSignIn(ctx, user)
+}
+
+// นับผล sign in ลง metrics (เรียกด้วย defer ให้เห็น result สุดท้าย)
+func (s *userSrv) countSignIn(method string, result *models.Response) {
+	switch {
+	case !result.Status:
+		s.metrics.SignIn(method, metrics.SignInFailure, result.ErrorCode)
+	case isMFAChallenge(result.Data):
+		s.metrics.SignIn(method, metrics.SignInMFARequired, "")
+	default:
+		s.metrics.SignIn(method, metrics.SignInSuccess, "")
+	}
+}
+
+func isMFAChallenge(data interface{}) bool {
+	_, ok := data.(models.SrvMFAChallengeResModel)
+	return ok
+}
+
+// บันทึกเวลา login แล้วออก token ใน family ใหม่
+func (s *userSrv) completeSignIn(ctx context.Context, user models.RepoResUserModel) (result models.Response) {
+	now := time.Now()
+	if _, err := s.userRepo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{LastLoginAt: &now}); err != nil {
+		return s.failure(err)
+	}
+
+	data, err := s.issueToken(ctx, user, uuid.New().String())
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "sign in success",
+		Code:    200,
+		Data:    data,
+	}
+	return result
+}
+
+// NOTE นับแยกตาม email (รวม email ที่ไม่มีในระบบ จึงเดาไม่ได้ว่ามี email ไหนบ้าง) และตาม IP
+func loginEmailKey(email string) string {
+	return "email:" + email
+}
+
+func loginIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// hash ของรหัสผ่านที่ไม่มีใครใช้ ใช้เทียบแทนเมื่อไม่พบ email ให้ใช้เวลาเท่ากับกรณีรหัสผ่านผิด
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := utils.Bcryp_Encryption(uuid.New().String())
+	if err != nil {
+		slog.Error("dummy password hash", "error", err)
+	}
+	return hash
+})
+
+// NOTE bcrypt กินเวลาส่วนใหญ่ของ sign in จึงแยก span ไว้
+func bcryptHash(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.Hash")
+	defer span.End()
+	hash, err := utils.Bcryp_Encryption(password)
+	// NOTE bcrypt ใช้ได้ไม่เกิน 72 byte ยาวกว่านั้นเป็นความผิดของ input ไม่ใช่ของระบบ
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", apperror.Validation("PASSWORD_TOO_LONG", "password must be at most 72 bytes")
+	}
+	return hash, err
+}
+
+func bcryptCompare(ctx context.Context, hash string, password string) bool {
+	_, span := tracing.Start(ctx, "bcrypt.Compare")
+	defer span.End()
+	return utils.Bcryp_Compare(hash, password)
+}
+
+// ต้องรอ LOGIN_DELAY * 2^(ครั้งที่ผิด-1) หลังผิดครั้งล่าสุด
+func loginDelay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	if failures > 10 {
+		failures = 10
+	}
+	return config.Env.LoginDelay << (failures - 1)
+}
+
+// ปฏิเสธถ้า email หรือ IP ถูกล็อกอยู่ หรือยังไม่พ้นเวลารอหลังผิดครั้งล่าสุด
+func (s *userSrv) checkLoginAttempts(ctx context.Context, email string, ip string) error {
+	now := time.Now()
+
+	attempt, err := s.loginAttemptRepo.GetLoginAttempt(ctx, loginEmailKey(email))
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return err
+	}
+	if err == nil {
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			return errTooManyAttempts
+		}
+		if now.Before(attempt.LastFailureAt.Add(loginDelay(attempt.Failures))) {
+			return errTooManyAttempts
 		}
 	}
-	if !utils.Bcryp_Compare(user.Password, payload.Password) {
-		return models.Response{
-			Status:  false,
-			Message: "invalid password",
-			Code:    400,
-			Data:    nil,
+
+	if ip == "" {
+		return nil
+	}
+	return s.checkLoginLock(ctx, loginIPKey(ip))
+}
+
+// ปฏิเสธถ้า key ถูกล็อกอยู่
+func (s *userSrv) checkLoginLock(ctx context.Context, key string) error {
+	attempt, err := s.loginAttemptRepo.GetLoginAttempt(ctx, key)
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return err
+	}
+	if err == nil && attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+		return errTooManyAttempts
+	}
+	return nil
+}
+
+// นับ sign in ผิดและล็อกเมื่อครบ LOGIN_MAX_ATTEMPTS (ต่อ email) หรือ LOGIN_IP_MAX_ATTEMPTS (ต่อ IP)
+func (s *userSrv) addLoginFailure(ctx context.Context, email string, ip string) error {
+	keys := map[string]int{loginEmailKey(email): config.Env.LoginMaxAttempts}
+	if ip != "" {
+		keys[loginIPKey(ip)] = config.Env.LoginIPMaxAttempts
+	}
+
+	now := time.Now()
+	for key, max := range keys {
+		if err := s.addFailure(ctx, key, max, now); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	accessToken, err := s.auth.GenerateToken(authorization.AppAuthorizationClaim{
-		UserId:   user.ID,
-		Audience: "7solutions",
-		Issuer:   "7solutions",
-	})
+// นับครั้งที่ผิดของ key ภายใน LOGIN_ATTEMPT_WINDOW และล็อกเมื่อครบ max (0 = ไม่ล็อก)
+func (s *userSrv) addFailure(ctx context.Context, key string, max int, now time.Time) error {
+	attempt, err := s.loginAttemptRepo.AddLoginFailure(ctx, key, now, now.Add(-config.Env.LoginAttemptWindow), now.Add(config.Env.LoginAttemptWindow))
+	if err != nil {
+		return err
+	}
+	if max > 0 && attempt.Failures >= max {
+		return s.loginAttemptRepo.LockLoginAttempt(ctx, key, now.Add(config.Env.LoginLockout))
+	}
+	return nil
+}
+
+func (s *userSrv) UnlockUser(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.UnlockUser")
+	defer endSpan(span, &result)
+
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.Can(models.PermissionUserUnlock) {
+		return s.failure(errForbidden)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, id)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
+		return s.failure(err)
+	}
+	if err := s.loginAttemptRepo.ResetLoginAttempt(ctx, loginEmailKey(user.Email)); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "unlock user success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *userSrv) RefreshToken(ctx context.Context, payload models.SrvRefreshTokenModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.RefreshToken")
+	defer endSpan(span, &result)
+
+	if payload.RefreshToken == "" {
+		return s.failure(apperror.Validation("REFRESH_TOKEN_REQUIRED", "refresh token is required"))
+	}
+
+	token, err := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, utils.Token_Hash(payload.RefreshToken))
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(err)
+	}
+	if err != nil || token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return s.failure(errInvalidRefreshToken)
+	}
+
+	// NOTE token ที่ถูกใช้ไปแล้วถูกส่งมาอีก = อาจถูกขโมย เพิกถอนทั้ง family
+	if token.UsedAt == nil {
+		_, err = s.refreshTokenRepo.UseRefreshToken(ctx, token.ID, time.Now())
+	}
+	if token.UsedAt != nil || errors.Is(err, repositories.ErrRefreshTokenUsed) {
+		if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID, time.Now()); err != nil {
+			return s.failure(err)
 		}
+		return s.failure(apperror.Unauthorized("REFRESH_TOKEN_REUSED", "refresh token reuse detected"))
 	}
-	data := models.SrvSignInResModel{
-		Type:        "Bearer",
-		AccessToken: accessToken,
+	if err != nil {
+		return s.failure(err)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(errInvalidRefreshToken)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+
+	data, err := s.issueToken(ctx, user, token.FamilyID)
+	if err != nil {
+		return s.failure(err)
 	}
 
 	result = models.Response{
 		Status:  true,
-		Message: "sign in success",
+		Message: "refresh token success",
 		Code:    200,
 		Data:    data,
 	}
 	return result
 }
 
-func (s *userSrv) Gets() (result models.Response) {
-	res, err := s.userRepo.GetUsers()
-	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
+func (s *userSrv) SignOut(ctx context.Context, userID string, tokenID string, expiresAt time.Time, payload models.SrvSignOutModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.SignOut")
+	defer endSpan(span, &result)
+
+	if tokenID == "" {
+		return s.failure(apperror.Validation("TOKEN_ID_REQUIRED", "token id is required"))
+	}
+	if err := s.revokedTokenRepo.RevokeToken(ctx, tokenID, expiresAt); err != nil {
+		return s.failure(err)
+	}
+
+	// NOTE ถ้าส่ง refresh token มาด้วย เพิกถอนทั้ง family (เฉพาะของตัวเอง)
+	if payload.RefreshToken != "" {
+		token, err := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, utils.Token_Hash(payload.RefreshToken))
+		if err == nil && token.UserID == userID {
+			if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID, time.Now()); err != nil {
+				return s.failure(err)
+			}
 		}
 	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "sign out success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *userSrv) RevokeUserTokens(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.RevokeUserTokens")
+	defer endSpan(span, &result)
+
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.CanAccess(id, models.PermissionTokenRevoke) {
+		return s.failure(errForbidden)
+	}
+
+	if err := s.revokeSessions(ctx, id); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "revoke user tokens success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+// เพิกถอน access token และ refresh token ทั้งหมดของ user
+func (s *userSrv) revokeSessions(ctx context.Context, userID string) error {
+	// NOTE access token ที่ออกก่อนตอนนี้จะหมดอายุภายใน SIGNATURE_EXP จึงเก็บรายการไว้แค่นั้น
+	now := time.Now()
+	if err := s.revokedTokenRepo.RevokeUserTokens(ctx, userID, now, now.Add(config.Env.SignatureExp)); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID, now)
+}
+
+// ออก access token + refresh token ใหม่ใน family เดิม (sign in จะเริ่ม family ใหม่)
+func (s *userSrv) issueToken(ctx context.Context, user models.RepoResUserModel, familyID string) (result models.SrvSignInResModel, err error) {
+	accessToken, err := s.auth.GenerateToken(ctx, authorization.AppAuthorizationClaim{
+		UserId:   user.ID,
+		Audience: "7solutions",
+		Issuer:   "7solutions",
+		Role:     userRole(user.Role),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	refreshToken, err := utils.Token_Random(32)
+	if err != nil {
+		return result, err
+	}
+	_, err = s.refreshTokenRepo.CreateRefreshToken(ctx, models.RepoCreateRefreshTokenModel{
+		ID:        uuid.New().String(),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		TokenHash: utils.Token_Hash(refreshToken),
+		ExpiresAt: time.Now().Add(config.Env.RefreshTokenExp),
+		CreateAt:  time.Now(),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result = models.SrvSignInResModel{
+		Type:         "Bearer",
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.Env.SignatureExp.Seconds()),
+	}
+	return result, nil
+}
+
+func (s *userSrv) Gets(ctx context.Context, caller models.SrvCallerModel, query models.SrvUserQueryModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.Gets")
+	defer endSpan(span, &result)
+
+	if !caller.Can(models.PermissionUserList) {
+		return s.failure(errForbidden)
+	}
+
+	payloadQuery, err := newUserQuery(query)
+	if err != nil {
+		return s.failure(err)
+	}
+	res, err := s.userRepo.GetUsers(ctx, payloadQuery)
+	if err != nil {
+		return s.failure(err)
+	}
+
+	meta := models.ResponseMetaModel{
+		Total:  res.Total,
+		Limit:  payloadQuery.Limit,
+		Offset: payloadQuery.Offset,
+	}
+	if res.Next != nil {
+		meta.NextCursor = encodeUserCursor(query.Sort, *res.Next)
+	}
+	data := []models.SrvResUserModel{}
+	for _, user := range res.Users {
+		data = append(data, userResponse(caller, user))
+	}
 	result = models.Response{
 		Status:  true,
 		Message: "get users success",
 		Code:    200,
-		Data:    res,
+		Data:    data,
+		Meta:    &meta,
 	}
 	return result
 }
 
-func (s *userSrv) UpdateUser(id string, payload models.SrvUpdateUserModel) (result models.Response) {
+func (s *userSrv) UpdateUser(ctx context.Context, caller models.SrvCallerModel, id string, payload models.SrvUpdateUserModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.UpdateUser")
+	defer endSpan(span, &result)
+
 	if id == "" {
-		return models.Response{
-			Status:  false,
-			Message: "id is required",
-			Code:    400,
-			Data:    nil,
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.CanAccess(id, models.PermissionUserWrite) {
+		return s.failure(errForbidden)
+	}
+	if payload.Role != "" {
+		if !caller.Can(models.PermissionUserWrite) {
+			return s.failure(errForbidden)
+		}
+		if !models.ValidRole(payload.Role) {
+			return s.failure(apperror.Validation("ROLE_INVALID", "role invalid"))
 		}
 	}
+	payload.Email = utils.Email_Normalize(payload.Email)
 	if payload.Email != "" {
-		_, err := mail.ParseAddress(payload.Email)
+		if !utils.Email_Valid(payload.Email) {
+			return s.failure(apperror.Validation("EMAIL_INVALID", "email invalid"))
+		}
+	}
+	payloadUpdate := models.RepoUpdateUserModel{
+		Name:  payload.Name,
+		Email: payload.Email,
+		Role:  payload.Role,
+	}
+
+	// NOTE เปลี่ยน email ต้องยืนยัน email ใหม่อีกครั้ง
+	emailChanged := false
+	if payload.Email != "" || payload.Role != "" {
+		current, err := s.userRepo.GetUserByID(ctx, id)
 		if err != nil {
-			return models.Response{
-				Status:  false,
-				Message: "email invalid",
-				Code:    400,
-				Data:    nil,
+			return s.failure(err)
+		}
+		if payload.Email != "" && current.Email != payload.Email {
+			emailChanged = true
+			verified := false
+			payloadUpdate.EmailVerified = &verified
+		}
+		// NOTE role อยู่ใน access token ที่ออกไปแล้ว เปลี่ยน role ต้องเพิกถอนให้ sign in ใหม่
+		if payload.Role != "" && userRole(current.Role) != payload.Role {
+			if err := s.revokeSessions(ctx, id); err != nil {
+				return s.failure(err)
 			}
 		}
 	}
-	payloadUpdate := models.RepoUpdateUserModel(payload)
-	res, err := s.userRepo.UpdateUser(id, payloadUpdate)
+	res, err := s.userRepo.UpdateUser(ctx, id, payloadUpdate)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
+		return s.failure(err)
+	}
+	if emailChanged {
+		if err := s.sendVerification(ctx, res); err != nil {
+			s.logger.Warn("send verification email", "user_id", res.ID, "error", err)
 		}
 	}
 	result = models.Response{
 		Status:  true,
 		Message: "update user success",
 		Code:    200,
-		Data:    res,
+		Data:    userResponse(caller, res),
 	}
 	return result
 }
 
-func (s *userSrv) DeleteUser(id string) (result models.Response) {
+func (s *userSrv) DeleteUser(ctx context.Context, caller models.SrvCallerModel, id string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.DeleteUser")
+	defer endSpan(span, &result)
+
 	if id == "" {
-		return models.Response{
-			Status:  false,
-			Message: "id is required",
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	if !caller.CanAccess(id, models.PermissionUserDelete) {
+		return s.failure(errForbidden)
+	}
+	// NOTE middleware ไม่ได้ตรวจว่า user ของ access token ยังอยู่ ต้องเพิกถอนก่อนลบ
+	if err := s.revokeSessions(ctx, id); err != nil {
+		return s.failure(err)
 	}
-	err := s.userRepo.DeleteUser(id)
+	err := s.userRepo.DeleteUser(ctx, id)
 	if err != nil {
-		return models.Response{
-			Status:  false,
-			Message: err.Error(),
-			Code:    400,
-			Data:    nil,
-		}
+		return s.failure(err)
 	}
 	result = models.Response{
 		Status:  true,
@@ -282,3 +673,442 @@ func (s *userSrv) DeleteUser(id string) (result models.Response) {
 	}
 	return result
 }
+
+func (s *userSrv) ChangePassword(ctx context.Context, caller models.SrvCallerModel, id string, payload models.SrvChangePasswordModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.ChangePassword")
+	defer endSpan(span, &result)
+
+	if id == "" {
+		return s.failure(apperror.Validation("ID_REQUIRED", "id is required"))
+	}
+	// NOTE ต้องรู้รหัสผ่านเดิม จึงเปลี่ยนได้เฉพาะของตัวเองและต้อง sign in (ไม่ใช่ API key หรือ OAuth client)
+	if caller.UserID != id || caller.Delegated() {
+		return s.failure(errForbidden)
+	}
+	if payload.CurrentPassword == "" {
+		return s.failure(apperror.Validation("CURRENT_PASSWORD_REQUIRED", "current password is required"))
+	}
+	if payload.NewPassword == "" {
+		return s.failure(apperror.Validation("NEW_PASSWORD_REQUIRED", "new password is required"))
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return s.failure(err)
+	}
+	if !bcryptCompare(ctx, user.Password, payload.CurrentPassword) {
+		return s.failure(apperror.Unauthorized("INVALID_PASSWORD", "invalid password"))
+	}
+	if err := s.setPassword(ctx, id, payload.NewPassword); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "change password success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *userSrv) ForgotPassword(ctx context.Context, payload models.SrvForgotPasswordModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.ForgotPassword")
+	defer endSpan(span, &result)
+
+	payload.Email = utils.Email_Normalize(payload.Email)
+	if payload.Email == "" {
+		return s.failure(apperror.Validation("EMAIL_REQUIRED", "email is required"))
+	}
+	if !utils.Email_Valid(payload.Email) {
+		return s.failure(apperror.Validation("EMAIL_INVALID", "email invalid"))
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "if the email exists, a reset token has been sent",
+		Code:    200,
+		Data:    nil,
+	}
+
+	// NOTE ไม่บอกว่าไม่มี email นี้ ป้องกันการไล่เดา email ในระบบ
+	user, err := s.userRepo.GetUserByEmail(ctx, payload.Email)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return result
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+
+	token, err := utils.Token_Random(32)
+	if err != nil {
+		return s.failure(err)
+	}
+	_, err = s.passwordResetRepo.CreatePasswordReset(ctx, models.RepoCreatePasswordResetModel{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: utils.Token_Hash(token),
+		ExpiresAt: time.Now().Add(config.Env.PasswordResetExp),
+		CreateAt:  time.Now(),
+	})
+	if err != nil {
+		return s.failure(err)
+	}
+
+	err = s.notify.Notify(notifier.Message{
+		To:       user.Email,
+		Template: notifier.TemplatePasswordReset,
+		Data: map[string]string{
+			"name":      user.Name,
+			"token":     token,
+			"expiresIn": config.Env.PasswordResetExp.String(),
+		},
+	})
+	if err != nil {
+		// NOTE ตอบเหมือน email ที่ไม่มีในระบบ (เช่น คิวส่ง email เต็ม) ไม่ให้ใช้เดา email ได้
+		s.logger.Warn("send password reset email", "user_id", user.ID, "error", err)
+	}
+	return result
+}
+
+func (s *userSrv) ResetPassword(ctx context.Context, payload models.SrvResetPasswordModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.ResetPassword")
+	defer endSpan(span, &result)
+
+	if payload.Token == "" {
+		return s.failure(apperror.Validation("RESET_TOKEN_REQUIRED", "reset token is required"))
+	}
+	if payload.Password == "" {
+		return s.failure(apperror.Validation("PASSWORD_REQUIRED", "password is required"))
+	}
+
+	token, err := s.passwordResetRepo.GetPasswordResetByHash(ctx, utils.Token_Hash(payload.Token))
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(err)
+	}
+	if err != nil || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return s.failure(errInvalidResetToken)
+	}
+	_, err = s.passwordResetRepo.UsePasswordReset(ctx, token.ID, time.Now())
+	if errors.Is(err, repositories.ErrPasswordResetUsed) {
+		return s.failure(errInvalidResetToken)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+
+	if err := s.setPassword(ctx, token.UserID, payload.Password); err != nil {
+		if apperror.Is(err, apperror.KindNotFound) {
+			return s.failure(errInvalidResetToken)
+		}
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "reset password success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *userSrv) VerifyEmail(ctx context.Context, token string) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.VerifyEmail")
+	defer endSpan(span, &result)
+
+	if token == "" {
+		return s.failure(apperror.Validation("VERIFY_TOKEN_REQUIRED", "verification token is required"))
+	}
+
+	verification, err := s.verificationRepo.GetEmailVerificationByHash(ctx, utils.Token_Hash(token))
+	if err != nil && !apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(err)
+	}
+	if err != nil || verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		return s.failure(errInvalidVerifyToken)
+	}
+	_, err = s.verificationRepo.UseEmailVerification(ctx, verification.ID, time.Now())
+	if errors.Is(err, repositories.ErrEmailVerificationUsed) {
+		return s.failure(errInvalidVerifyToken)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+
+	// NOTE token ของ email เดิม (ก่อนเปลี่ยน) ยืนยัน email ใหม่ไม่ได้
+	user, err := s.userRepo.GetUserByID(ctx, verification.UserID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return s.failure(errInvalidVerifyToken)
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+	if user.Email != verification.Email {
+		return s.failure(errInvalidVerifyToken)
+	}
+	verified := true
+	if _, err := s.userRepo.UpdateUser(ctx, user.ID, models.RepoUpdateUserModel{EmailVerified: &verified}); err != nil {
+		return s.failure(err)
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "verify email success",
+		Code:    200,
+		Data:    nil,
+	}
+	return result
+}
+
+func (s *userSrv) ResendVerification(ctx context.Context, payload models.SrvResendVerificationModel) (result models.Response) {
+	ctx, span := tracing.Start(ctx, "userSrv.ResendVerification")
+	defer endSpan(span, &result)
+
+	payload.Email = utils.Email_Normalize(payload.Email)
+	if payload.Email == "" {
+		return s.failure(apperror.Validation("EMAIL_REQUIRED", "email is required"))
+	}
+	if !utils.Email_Valid(payload.Email) {
+		return s.failure(apperror.Validation("EMAIL_INVALID", "email invalid"))
+	}
+
+	result = models.Response{
+		Status:  true,
+		Message: "if the email exists and is not verified, a verification token has been sent",
+		Code:    200,
+		Data:    nil,
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, payload.Email)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return result
+	}
+	if err != nil {
+		return s.failure(err)
+	}
+	if user.EmailVerified {
+		return result
+	}
+	if err := s.sendVerification(ctx, user); err != nil {
+		// NOTE ตอบเหมือน email ที่ไม่มีในระบบ (เช่น คิวส่ง email เต็ม) ไม่ให้ใช้เดา email ได้
+		s.logger.Warn("send verification email", "user_id", user.ID, "error", err)
+	}
+	return result
+}
+
+// ออก token ยืนยัน email ปัจจุบันของ user (token เก่าที่ยังไม่ใช้จะใช้ไม่ได้อีก)
+func (s *userSrv) sendVerification(ctx context.Context, user models.RepoResUserModel) error {
+	token, err := utils.Token_Random(32)
+	if err != nil {
+		return err
+	}
+	if err := s.verificationRepo.UseUserEmailVerifications(ctx, user.ID, time.Now()); err != nil {
+		return err
+	}
+	_, err = s.verificationRepo.CreateEmailVerification(ctx, models.RepoCreateEmailVerificationModel{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: utils.Token_Hash(token),
+		ExpiresAt: time.Now().Add(config.Env.EmailVerificationExp),
+		CreateAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.notify.Notify(notifier.Message{
+		To:       user.Email,
+		Template: notifier.TemplateEmailVerification,
+		Data: map[string]string{
+			"name":      user.Name,
+			"token":     token,
+			"url":       config.Env.AppHost + "/api/verify-email?token=" + url.QueryEscape(token),
+			"expiresIn": config.Env.EmailVerificationExp.String(),
+		},
+	})
+}
+
+// เปลี่ยนรหัสผ่าน ยกเลิก reset token ที่ค้างอยู่ และเพิกถอน session ทั้งหมดของ user
+func (s *userSrv) setPassword(ctx context.Context, userID string, password string) error {
+	hashPassword, err := bcryptHash(ctx, password)
+	if err != nil {
+		return err
+	}
+	if _, err := s.userRepo.UpdateUser(ctx, userID, models.RepoUpdateUserModel{Password: hashPassword}); err != nil {
+		return err
+	}
+	if err := s.passwordResetRepo.UseUserPasswordResets(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, userID)
+}
+
+var (
+	errForbidden           = apperror.Forbidden("FORBIDDEN", "forbidden")
+	errInvalidCredentials  = apperror.Unauthorized("INVALID_CREDENTIALS", "invalid email or password")
+	errTooManyAttempts     = apperror.TooManyRequests("TOO_MANY_ATTEMPTS", "too many sign in attempts, try again later")
+	errInvalidRefreshToken = apperror.Unauthorized("REFRESH_TOKEN_INVALID", "invalid refresh token")
+	errInvalidResetToken   = apperror.Unauthorized("RESET_TOKEN_INVALID", "invalid or expired reset token")
+	errInvalidVerifyToken  = apperror.Unauthorized("VERIFY_TOKEN_INVALID", "invalid or expired verification token")
+)
+
+// logger ที่ทุก service ใช้ (embed ใน struct ของ service)
+type serviceLogger struct {
+	logger *slog.Logger
+}
+
+// แปลง error เป็น response ตามชนิดของ domain error
+// NOTE รายละเอียดของ internal error เก็บใน log เท่านั้น ไม่ส่งให้ client
+func (l serviceLogger) failure(err error) models.Response {
+	appErr := apperror.From(err)
+	switch appErr.Kind {
+	case apperror.KindInternal:
+		l.logger.Error("internal error", "error", appErr.Err)
+	case apperror.KindTimeout:
+		l.logger.Warn("request timed out", "error", appErr.Err)
+	}
+	return models.Response{
+		Status:    false,
+		Message:   appErr.Message,
+		Code:      appErr.Status(),
+		ErrorCode: appErr.Code,
+		Data:      nil,
+	}
+}
+
+// ปิด span ของ method ใน service พร้อม errorCode ของผลลัพธ์ (error ฝั่ง server ตั้ง status เป็น Error)
+func endSpan(span trace.Span, result *models.Response) {
+	if result.ErrorCode != "" {
+		span.SetAttributes(tracing.ErrorCodeKey.String(result.ErrorCode))
+	}
+	if result.Code >= 500 {
+		span.SetStatus(codes.Error, result.Message)
+	}
+	span.End()
+}
+
+// ข้อมูล user สำหรับส่งออก field ส่วนตัวแสดงเฉพาะผู้ที่มีสิทธิ์ PermissionUserReadPrivate
+func userResponse(caller models.SrvCallerModel, user models.RepoResUserModel) models.SrvResUserModel {
+	result := models.SrvResUserModel{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          userRole(user.Role),
+		CreateAt:      user.CreateAt.Format("2006-01-02 15:04:05"),
+		EmailVerified: user.EmailVerified,
+	}
+	if caller.Can(models.PermissionUserReadPrivate) && user.LastLoginAt != nil {
+		result.LastLoginAt = user.LastLoginAt.Format("2006-01-02 15:04:05")
+	}
+	return result
+}
+
+// user ที่สร้างก่อนมี role ถือเป็น user ธรรมดา
+func userRole(role string) string {
+	if role == "" {
+		return models.RoleUser
+	}
+	return role
+}
+
+const (
+	defaultUserLimit = 20
+	maxUserLimit     = 100
+)
+
+// แปลง query string เป็นเงื่อนไขของ repository
+func newUserQuery(query models.SrvUserQueryModel) (result models.RepoUserQueryModel, err error) {
+	result = models.RepoUserQueryModel{
+		Name:   strings.TrimSpace(query.Name),
+		Email:  strings.TrimSpace(query.Email),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+
+	if result.Limit <= 0 {
+		result.Limit = defaultUserLimit
+	}
+	if result.Limit > maxUserLimit {
+		result.Limit = maxUserLimit
+	}
+	if result.Offset < 0 {
+		return result, apperror.Validation("OFFSET_INVALID", "offset invalid")
+	}
+
+	result.SortBy = strings.TrimPrefix(query.Sort, "-")
+	result.SortDesc = strings.HasPrefix(query.Sort, "-")
+	if result.SortBy == "" {
+		result.SortBy = repositories.UserSortCreateAt
+	}
+	if !repositories.ValidUserSort(result.SortBy) {
+		return result, apperror.Validation("SORT_INVALID", "sort invalid")
+	}
+
+	if query.CreatedFrom != "" {
+		createdFrom, err := parseQueryTime(query.CreatedFrom, false)
+		if err != nil {
+			return result, apperror.Validation("CREATED_FROM_INVALID", "createdFrom invalid")
+		}
+		result.CreatedFrom = &createdFrom
+	}
+	if query.CreatedTo != "" {
+		createdTo, err := parseQueryTime(query.CreatedTo, true)
+		if err != nil {
+			return result, apperror.Validation("CREATED_TO_INVALID", "createdTo invalid")
+		}
+		result.CreatedTo = &createdTo
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeUserCursor(query.Sort, query.Cursor)
+		if err != nil {
+			return result, apperror.Validation("CURSOR_INVALID", "cursor invalid")
+		}
+		result.After = &after
+		result.Offset = 0
+	}
+	return result, nil
+}
+
+// รับได้ทั้ง RFC 3339 และวันที่อย่างเดียว (createdTo แบบวันที่จะนับถึงสิ้นวัน)
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return t, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// cursor ผูกกับ sort ที่ใช้สร้าง ถ้าเปลี่ยน sort ต้องเริ่มหน้าแรกใหม่
+type userCursor struct {
+	Sort string `json:"s"`
+	models.RepoUserCursorModel
+}
+
+func encodeUserCursor(sort string, cursor models.RepoUserCursorModel) string {
+	raw, _ := json.Marshal(userCursor{Sort: sort, RepoUserCursorModel: cursor})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUserCursor(sort string, value string) (result models.RepoUserCursorModel, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return result, err
+	}
+	cursor := userCursor{}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return result, err
+	}
+	if cursor.Sort != sort || cursor.ID == "" {
+		return result, errors.New("cursor does not match sort")
+	}
+	return cursor.RepoUserCursorModel, nil
+}
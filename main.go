@@ -2,57 +2,99 @@ package main
 
 import (
 	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/common/tracing"
 	"7solutions/backend/config"
-	"7solutions/backend/core/handlers"
-	"7solutions/backend/core/middlewares"
 	"7solutions/backend/core/repositories"
-	"7solutions/backend/core/services"
-	"fmt"
+	"7solutions/backend/server"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
+// เวลาที่รอ handler คืนค่าหลังยกเลิก request ที่ค้างตอนปิด server
+const shutdownDrain = 2 * time.Second
+
 func init() {
 	config.NewAppInitEnvironment()
 }
 
 func main() {
-	db := config.NewAppDatabase()
-	auth := authorization.NewJWT_HS256()
-	userRepo := repositories.NewUserRepository(db, "users")
+	log := logger.NewAppLogger()
+	shutdownTracer := tracing.NewAppTracer()
+	defer func() {
+		if err := shutdownTracer(context.Background()); err != nil {
+			log.Error("tracer shutdown failed", "error", err)
+		}
+	}()
+	keyRing := authorization.NewAppKeyRing()
+	m := metrics.New()
+	repos := server.NewAppRepositories(log, m)
 
-	userSrv := services.NewUserService(auth, userRepo)
+	notify := notifier.NewAppNotifier(log)
+	defer notify.Close()
 
-	userHand := handlers.NewUserHandler(userSrv)
+	// NOTE ยกเลิก ctx ของ request ที่ยังค้างเมื่อปิด server แล้วรอเกิน SHUTDOWN_TIMEOUT (ดูท้าย main)
+	requests, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
-	app := fiber.New()
-	app.Use(recover.New())
-	app.Use(cors.New(config.CorsConfig()))
+	app := server.New(requests, keyRing, repos, notify, oidc.NewAppProviders(), log, m)
 
+	// NOTE นับจำนวน user ทุก 10 วินาทีเป็น gauge users_total ของ /metrics
 	go func(userRepo repositories.UserRepository) {
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
 
 		for range ticker.C {
-			count, err := userRepo.CountUser()
+			count, err := userRepo.CountUser(context.Background())
 			if err != nil {
-				fmt.Printf("Background task error: Failed to count users: %v\n", err)
+				log.Error("background task: failed to count users", "error", err)
+				continue
+			}
+			m.SetUsers(count)
+		}
+	}(repos.User)
+
+	// NOTE kill -HUP <pid> เพื่อโหลด key ring ใหม่ (rotate key) โดยไม่ต้อง restart
+	go func(keyRing *authorization.KeyRing) {
+		hangup := make(chan os.Signal, 1)
+		signal.Notify(hangup, syscall.SIGHUP)
+
+		for range hangup {
+			if err := keyRing.Reload(); err != nil {
+				log.Error("key ring reload failed", "error", err)
 			} else {
-				fmt.Printf("Background task: Current users: %d\n", count)
+				log.Info("key ring reloaded", "kid", keyRing.Active().Kid)
 			}
-			fmt.Printf("Background task: Completed at %s\n", time.Now().Format("2006-01-02 15:04:05"))
 		}
-	}(userRepo)
+	}(keyRing)
+
+	go func() {
+		if err := app.Listen(":" + config.Env.Port); err != nil {
+			log.Error("server stopped", "error", err)
+			os.Exit(1)
+		}
+	}()
 
-	app.Post("/api/signin", userHand.SignIn)
-	app.Post("/api/create-user", userHand.CreateUser)
-	app.Get("/api/user/:id", middlewares.AccessToken, userHand.GetUserByID)
-	app.Get("/api/users", middlewares.AccessToken, userHand.GetUsers)
-	app.Put("/api/user/:id", middlewares.AccessToken, userHand.UpdateUser)
-	app.Delete("/api/user/:id", middlewares.AccessToken, userHand.DeleteUser)
+	// NOTE SIGINT / SIGTERM หยุดรับ request ใหม่ รอ request ที่ค้างก่อนปิด tracer และคิวส่ง email (defer ด้านบน)
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
 
-	app.Listen(":" + config.Env.Port)
+	log.Info("shutting down", "timeout", config.Env.ShutdownTimeout.String())
+	// NOTE ครบ SHUTDOWN_TIMEOUT แล้วยกเลิก request ที่ค้างระหว่าง shutdown ให้หยุดรอฐานข้อมูลและตอบ 503
+	// แล้วรออีก shutdownDrain ให้ handler คืนค่าก่อนปิดจริง
+	cancelTimer := time.AfterFunc(config.Env.ShutdownTimeout, func() {
+		log.Warn("shutdown timeout reached, cancelling in-flight requests")
+		cancelRequests()
+	})
+	defer cancelTimer.Stop()
+	if err := app.ShutdownWithTimeout(config.Env.ShutdownTimeout + shutdownDrain); err != nil {
+		log.Warn("in-flight requests did not finish after cancelling", "error", err)
+	}
 }
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/config"
+	"7solutions/backend/core/repositories"
+	"log/slog"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DB_DRIVER=memory เก็บข้อมูลใน process (หายเมื่อปิด) ใช้ demo / test
+const DBDriverMemory = "memory"
+
+// repository ทั้งหมดที่ app ใช้
+type Repositories struct {
+	User              repositories.UserRepository
+	RefreshToken      repositories.RefreshTokenRepository
+	RevokedToken      repositories.RevokedTokenRepository
+	PasswordReset     repositories.PasswordResetRepository
+	EmailVerification repositories.EmailVerificationRepository
+	LoginAttempt      repositories.LoginAttemptRepository
+	RateLimit         repositories.RateLimitRepository
+	MFA               repositories.MFARepository
+	APIKey            repositories.APIKeyRepository
+	OIDCState         repositories.OIDCStateRepository
+	Identity          repositories.IdentityRepository
+	OAuthClient       repositories.OAuthClientRepository
+	OAuthCode         repositories.OAuthCodeRepository
+}
+
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		User:              repositories.NewUserMemoryRepository(),
+		RefreshToken:      repositories.NewRefreshTokenMemoryRepository(),
+		RevokedToken:      repositories.NewRevokedTokenMemoryRepository(),
+		PasswordReset:     repositories.NewPasswordResetMemoryRepository(),
+		EmailVerification: repositories.NewEmailVerificationMemoryRepository(),
+		LoginAttempt:      repositories.NewLoginAttemptMemoryRepository(),
+		RateLimit:         repositories.NewRateLimitMemoryRepository(),
+		MFA:               repositories.NewMFAMemoryRepository(),
+		APIKey:            repositories.NewAPIKeyMemoryRepository(),
+		OIDCState:         repositories.NewOIDCStateMemoryRepository(),
+		Identity:          repositories.NewIdentityMemoryRepository(),
+		OAuthClient:       repositories.NewOAuthClientMemoryRepository(),
+		OAuthCode:         repositories.NewOAuthCodeMemoryRepository(),
+	}
+}
+
+// เลือก repository ตาม DB_DRIVER
+// NOTE metrics ของ repository เก็บเฉพาะ mongo (ผ่าน command monitor)
+func NewAppRepositories(logger *slog.Logger, m *metrics.Metrics) Repositories {
+	timeouts := repositories.Timeouts(config.DBTimeoutConfig())
+	switch config.Env.DBDriver {
+	case DBDriverMemory:
+		return NewMemoryRepositories()
+	case repositories.DialectPostgres, repositories.DialectSQLite:
+		db := config.NewAppSQLDatabase()
+		if err := repositories.MigrateSQL(db, config.Env.DBDriver, logger); err != nil {
+			logger.Error("unable to migrate database", "error", err)
+			os.Exit(1)
+		}
+		return Repositories{
+			User:              repositories.NewUserSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			RefreshToken:      repositories.NewRefreshTokenSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			RevokedToken:      repositories.NewRevokedTokenSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			PasswordReset:     repositories.NewPasswordResetSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			EmailVerification: repositories.NewEmailVerificationSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			LoginAttempt:      repositories.NewLoginAttemptSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			RateLimit:         repositories.NewRateLimitSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			MFA:               repositories.NewMFASQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			APIKey:            repositories.NewAPIKeySQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			OIDCState:         repositories.NewOIDCStateSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			Identity:          repositories.NewIdentitySQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			OAuthClient:       repositories.NewOAuthClientSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+			OAuthCode:         repositories.NewOAuthCodeSQLRepository(db, config.Env.DBDriver, timeouts, logger),
+		}
+	default:
+		db := config.NewAppDatabase(repositories.NewMongoMonitor(m))
+		config.NewAppIndexes(db, map[string][]mongo.IndexModel{
+			"users":               repositories.UserIndexes,
+			"refresh_tokens":      repositories.RefreshTokenIndexes,
+			"revoked_tokens":      repositories.RevokedTokenIndexes,
+			"password_resets":     repositories.PasswordResetIndexes,
+			"email_verifications": repositories.EmailVerificationIndexes,
+			"login_attempts":      repositories.LoginAttemptIndexes,
+			"rate_limits":         repositories.RateLimitIndexes,
+			"user_mfa":            repositories.MFAIndexes,
+			"api_keys":            repositories.APIKeyIndexes,
+			"oidc_states":         repositories.OIDCStateIndexes,
+			"user_identities":     repositories.IdentityIndexes,
+			"oauth_clients":       repositories.OAuthClientIndexes,
+			"oauth_codes":         repositories.OAuthCodeIndexes,
+		}, logger)
+		return Repositories{
+			User:              repositories.NewUserRepository(db, "users", timeouts, logger),
+			RefreshToken:      repositories.NewRefreshTokenRepository(db, "refresh_tokens", timeouts, logger),
+			RevokedToken:      repositories.NewRevokedTokenRepository(db, "revoked_tokens", timeouts, logger),
+			PasswordReset:     repositories.NewPasswordResetRepository(db, "password_resets", timeouts, logger),
+			EmailVerification: repositories.NewEmailVerificationRepository(db, "email_verifications", timeouts, logger),
+			LoginAttempt:      repositories.NewLoginAttemptRepository(db, "login_attempts", timeouts, logger),
+			RateLimit:         repositories.NewRateLimitRepository(db, "rate_limits", timeouts, logger),
+			MFA:               repositories.NewMFARepository(db, "user_mfa", timeouts, logger),
+			APIKey:            repositories.NewAPIKeyRepository(db, "api_keys", timeouts, logger),
+			OIDCState:         repositories.NewOIDCStateRepository(db, "oidc_states", timeouts, logger),
+			Identity:          repositories.NewIdentityRepository(db, "user_identities", timeouts, logger),
+			OAuthClient:       repositories.NewOAuthClientRepository(db, "oauth_clients", timeouts, logger),
+			OAuthCode:         repositories.NewOAuthCodeRepository(db, "oauth_codes", timeouts, logger),
+		}
+	}
+}
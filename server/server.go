@@ -0,0 +1,99 @@
+package server
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/config"
+	"7solutions/backend/core/handlers"
+	"7solutions/backend/core/middlewares"
+	"7solutions/backend/core/models"
+	"7solutions/backend/core/services"
+	"context"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// สร้าง fiber app พร้อม route ทั้งหมด (main และ e2e test ใช้ร่วมกัน)
+// NOTE ctx ของทุก request ต่อจาก ctx นี้ ยกเลิกเมื่อต้องหยุด request ที่ยังค้าง (ตอนปิด server)
+func New(ctx context.Context, keyRing *authorization.KeyRing, repos Repositories, notify notifier.Notifier, oidcProviders oidc.Providers, logger *slog.Logger, m *metrics.Metrics) *fiber.App {
+	auth := authorization.NewAppAuthorization(keyRing)
+
+	userSrv := services.NewUserService(auth, repos.User, repos.RefreshToken, repos.RevokedToken, repos.PasswordReset, repos.EmailVerification, repos.LoginAttempt, repos.MFA, repos.Identity, repos.OIDCState, oidcProviders, notify, logger, m)
+
+	apiKeySrv := services.NewAPIKeyService(repos.User, repos.APIKey, logger)
+
+	oauthSrv := services.NewOAuthService(auth, repos.User, repos.OAuthClient, repos.OAuthCode, repos.RevokedToken, logger)
+
+	userHand := handlers.NewUserHandler(userSrv)
+	apiKeyHand := handlers.NewAPIKeyHandler(apiKeySrv)
+	oauthHand := handlers.NewOAuthHandler(oauthSrv)
+	keyHand := handlers.NewKeyHandler(keyRing)
+
+	accessToken := middlewares.AccessToken(auth, repos.RevokedToken, repos.APIKey, repos.User, repos.OAuthClient)
+	// NOTE ใส่ต่อจาก accessToken ใน route ที่ต้อง login เพื่อนับตาม user
+	limit := middlewares.RateLimit(repos.RateLimit, config.RateLimitConfig())
+	// NOTE ใส่ก่อน accessToken เพื่อนับ request ที่ยังไม่ได้ยืนยันตัวตน (เดา token) ตาม IP
+	ipLimit := middlewares.RateLimitIP(repos.RateLimit, config.RateLimitIPConfig())
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middlewares.ErrorHandler,
+		// NOTE ค่าจาก ctx (เช่น c.Params) ต้องคัดลอก เพราะ repository แบบ memory เก็บไว้เป็น key
+		Immutable: true,
+		// NOTE IP ของ client (rate limit, login attempt, log) อ่านจาก header เฉพาะเมื่อ request มาจาก proxy ที่เชื่อถือ
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          config.TrustedProxiesConfig(),
+		ProxyHeader:             config.Env.ProxyHeader,
+		EnableIPValidation:      true,
+	})
+	app.Use(middlewares.RequestContext(ctx, config.Env.RequestTimeout))
+	app.Use(middlewares.RequestID(logger))
+	app.Use(middlewares.Tracing())
+	app.Use(middlewares.AccessLog())
+	app.Use(middlewares.Metrics(m))
+	app.Use(recover.New())
+	app.Use(cors.New(config.CorsConfig()))
+	app.Use(middlewares.CSRF())
+
+	app.Get("/metrics", middlewares.MetricsToken(config.Env.MetricsToken), adaptor.HTTPHandler(m.Handler()))
+	app.Get("/.well-known/jwks.json", limit, keyHand.JWKS)
+	app.Post("/api/signin", limit, userHand.SignIn)
+	app.Post("/api/signin/mfa", limit, userHand.SignInMFA)
+	app.Get("/api/oidc/:provider/login", limit, userHand.OIDCLogin)
+	app.Get("/api/oidc/:provider/callback", limit, userHand.OIDCCallback)
+	app.Post("/api/token/refresh", limit, userHand.RefreshToken)
+	app.Post("/api/signout", ipLimit, accessToken, limit, userHand.SignOut)
+	app.Post("/api/create-user", limit, userHand.CreateUser)
+	app.Get("/api/user/:id", ipLimit, accessToken, limit, userHand.GetUserByID)
+	app.Get("/api/users", ipLimit, accessToken, limit, middlewares.RequirePermission(models.PermissionUserList), userHand.GetUsers)
+	app.Put("/api/user/:id", ipLimit, accessToken, limit, userHand.UpdateUser)
+	app.Delete("/api/user/:id", ipLimit, accessToken, limit, userHand.DeleteUser)
+	app.Post("/api/user/:id/revoke-tokens", ipLimit, accessToken, limit, userHand.RevokeUserTokens)
+	app.Post("/api/user/:id/unlock", ipLimit, accessToken, limit, userHand.UnlockUser)
+	app.Post("/api/user/:id/password", ipLimit, accessToken, limit, userHand.ChangePassword)
+	app.Post("/api/user/:id/mfa/enroll", ipLimit, accessToken, limit, userHand.EnrollMFA)
+	app.Post("/api/user/:id/mfa/confirm", ipLimit, accessToken, limit, userHand.ConfirmMFA)
+	app.Delete("/api/user/:id/mfa", ipLimit, accessToken, limit, userHand.ResetMFA)
+	app.Post("/api/user/:id/api-keys", ipLimit, accessToken, limit, apiKeyHand.CreateAPIKey)
+	app.Get("/api/user/:id/api-keys", ipLimit, accessToken, limit, apiKeyHand.GetAPIKeys)
+	app.Delete("/api/user/:id/api-keys/:keyId", ipLimit, accessToken, limit, apiKeyHand.RevokeAPIKey)
+	app.Post("/api/oauth/clients", ipLimit, accessToken, limit, middlewares.RequirePermission(models.PermissionOAuthClient), oauthHand.CreateClient)
+	app.Get("/api/oauth/clients", ipLimit, accessToken, limit, middlewares.RequirePermission(models.PermissionOAuthClient), oauthHand.GetClients)
+	app.Delete("/api/oauth/clients/:id", ipLimit, accessToken, limit, middlewares.RequirePermission(models.PermissionOAuthClient), oauthHand.DeleteClient)
+	app.Get("/api/oauth/authorize", ipLimit, accessToken, limit, oauthHand.Authorize)
+	app.Post("/api/oauth/authorize", ipLimit, accessToken, limit, oauthHand.Consent)
+	app.Post("/api/oauth/token", limit, oauthHand.Token)
+	app.Post("/api/oauth/introspect", limit, oauthHand.Introspect)
+	app.Post("/api/oauth/revoke", limit, oauthHand.Revoke)
+	app.Post("/api/forgot-password", limit, userHand.ForgotPassword)
+	app.Post("/api/reset-password", limit, userHand.ResetPassword)
+	app.Get("/api/verify-email", limit, userHand.VerifyEmail)
+	app.Post("/api/verify-email/resend", limit, userHand.ResendVerification)
+
+	return app
+}
@@ -0,0 +1,988 @@
+package server_test
+
+import (
+	"7solutions/backend/common/authorization"
+	"7solutions/backend/common/logger"
+	"7solutions/backend/common/metrics"
+	"7solutions/backend/common/notifier"
+	"7solutions/backend/common/oidc"
+	"7solutions/backend/common/oidc/oidctest"
+	"7solutions/backend/common/totp"
+	"7solutions/backend/config"
+	"7solutions/backend/core/models"
+	"7solutions/backend/server"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type response struct {
+	Status    bool                      `json:"status"`
+	Message   string                    `json:"message"`
+	Code      int                       `json:"code"`
+	ErrorCode string                    `json:"errorCode"`
+	Data      json.RawMessage           `json:"data"`
+	Meta      *models.ResponseMetaModel `json:"meta"`
+}
+
+// เก็บข้อความที่ส่งไว้ให้ test อ่าน token
+type outbox struct {
+	mu       sync.Mutex
+	messages []notifier.Message
+}
+
+func (o *outbox) Notify(message notifier.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+// ข้อความล่าสุดของ template
+func (o *outbox) last(t *testing.T, template string) notifier.Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].Template == template {
+			return o.messages[i]
+		}
+	}
+	require.Failf(t, "no message", "template %s was not sent", template)
+	return notifier.Message{}
+}
+
+func newTestApp(t *testing.T) (*fiber.App, server.Repositories, *outbox) {
+	return newTestAppWithOIDC(t, oidc.Providers{})
+}
+
+func newTestAppWithOIDC(t *testing.T, providers oidc.Providers) (*fiber.App, server.Repositories, *outbox) {
+	return newTestServer(t, providers, metrics.New())
+}
+
+func newTestServer(t *testing.T, providers oidc.Providers, m *metrics.Metrics) (*fiber.App, server.Repositories, *outbox) {
+	config.Env.SignatureExp = time.Hour
+	config.Env.RefreshTokenExp = time.Hour
+	config.Env.LoginDelay = 0
+	config.Env.MFAEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+	keyRing, err := authorization.NewKeyRing(jwt.SigningMethodHS256, authorization.StaticKeyLoader("test",
+		authorization.SigningKey{Kid: "test", PrivateKey: []byte("secret"), PublicKey: []byte("secret")},
+	))
+	require.NoError(t, err)
+
+	repos := server.NewMemoryRepositories()
+	mail := &outbox{}
+	return server.New(context.Background(), keyRing, repos, mail, providers, logger.Discard(), m), repos, mail
+}
+
+// ส่ง request แบบ JSON body เป็น string ดิบ (ทดสอบ body ผิดรูปแบบได้)
+func send(t *testing.T, app *fiber.App, method string, path string, token string, body string) (result response) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &result), string(raw))
+	assert.Equal(t, res.StatusCode, result.Code, string(raw))
+	return result
+}
+
+func call(t *testing.T, app *fiber.App, method string, path string, token string, payload interface{}) response {
+	body := ""
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		require.NoError(t, err)
+		body = string(raw)
+	}
+	return send(t, app, method, path, token, body)
+}
+
+func data[T any](t *testing.T, res response) (result T) {
+	require.NoError(t, json.Unmarshal(res.Data, &result))
+	return result
+}
+
+func signIn(t *testing.T, app *fiber.App, email string, password string) models.SrvSignInResModel {
+	res := call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: email, Password: password})
+	require.Equal(t, 200, res.Code, res.Message)
+	return data[models.SrvSignInResModel](t, res)
+}
+
+func Test_UserFlow(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+
+	// NOTE sign up
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "Bank@Test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bank := data[models.SrvResUserModel](t, res)
+	assert.Equal(t, "bank@test.com", bank.Email)
+	assert.Equal(t, models.RoleUser, bank.Role)
+
+	res = call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "other", Email: "other@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	other := data[models.SrvResUserModel](t, res)
+
+	res = call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	assert.Equal(t, 409, res.Code)
+	assert.Equal(t, "EMAIL_ALREADY_EXISTS", res.ErrorCode)
+
+	// NOTE sign in
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "wrong"})
+	assert.Equal(t, 401, res.Code)
+	token := signIn(t, app, "bank@test.com", "123456")
+
+	// NOTE CRUD ของตัวเอง
+	res = call(t, app, "GET", "/api/user/"+bank.ID, "", nil)
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "UNAUTHORIZED", res.ErrorCode)
+
+	res = call(t, app, "GET", "/api/user/"+bank.ID, token.AccessToken, nil)
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, bank.ID, data[models.SrvResUserModel](t, res).ID)
+
+	res = call(t, app, "GET", "/api/user/"+other.ID, token.AccessToken, nil)
+	assert.Equal(t, 403, res.Code)
+
+	res = call(t, app, "PUT", "/api/user/"+bank.ID, token.AccessToken, models.SrvUpdateUserModel{Name: "bank updated"})
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "bank updated", data[models.SrvResUserModel](t, res).Name)
+
+	res = call(t, app, "GET", "/api/users", token.AccessToken, nil)
+	assert.Equal(t, 403, res.Code)
+
+	// NOTE เปลี่ยนเป็น admin แล้ว sign in ใหม่เพื่อให้ token มี role ใหม่
+	_, err := repos.User.UpdateUser(context.Background(), bank.ID, models.RepoUpdateUserModel{Role: models.RoleAdmin})
+	require.NoError(t, err)
+	token = signIn(t, app, "bank@test.com", "123456")
+
+	res = call(t, app, "GET", "/api/users?sort=name&limit=1", token.AccessToken, nil)
+	assert.Equal(t, 200, res.Code)
+	require.NotNil(t, res.Meta)
+	assert.Equal(t, int64(2), res.Meta.Total)
+	assert.NotEmpty(t, res.Meta.NextCursor)
+	assert.Len(t, data[[]models.SrvResUserModel](t, res), 1)
+
+	// NOTE ไม่มี password hash ใน response และ admin เห็นเวลาเข้าสู่ระบบล่าสุด
+	res = call(t, app, "GET", "/api/user/"+bank.ID, token.AccessToken, nil)
+	assert.NotContains(t, string(res.Data), "password")
+	assert.NotEmpty(t, data[models.SrvResUserModel](t, res).LastLoginAt)
+
+	res = call(t, app, "GET", "/api/users?sort=unknown", token.AccessToken, nil)
+	assert.Equal(t, 422, res.Code)
+
+	// NOTE refresh token หมุนทุกครั้ง ใช้ตัวเก่าซ้ำ = reuse
+	res = call(t, app, "POST", "/api/token/refresh", "", models.SrvRefreshTokenModel{RefreshToken: token.RefreshToken})
+	require.Equal(t, 200, res.Code, res.Message)
+	refreshed := data[models.SrvSignInResModel](t, res)
+	assert.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+
+	res = call(t, app, "POST", "/api/token/refresh", "", models.SrvRefreshTokenModel{RefreshToken: token.RefreshToken})
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "REFRESH_TOKEN_REUSED", res.ErrorCode)
+
+	// NOTE admin ลบ user อื่น
+	otherToken := signIn(t, app, "other@test.com", "123456")
+	res = call(t, app, "DELETE", "/api/user/"+other.ID, refreshed.AccessToken, nil)
+	assert.Equal(t, 200, res.Code)
+	res = call(t, app, "GET", "/api/user/"+other.ID, refreshed.AccessToken, nil)
+	assert.Equal(t, 404, res.Code)
+	assert.Equal(t, "USER_NOT_FOUND", res.ErrorCode)
+
+	// NOTE token ของ user ที่ถูกลบใช้ไม่ได้อีก (ทั้ง access และ refresh token)
+	res = call(t, app, "PUT", "/api/user/"+other.ID, otherToken.AccessToken, models.SrvUpdateUserModel{Name: "ghost"})
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+	res = call(t, app, "POST", "/api/token/refresh", "", models.SrvRefreshTokenModel{RefreshToken: otherToken.RefreshToken})
+	assert.Equal(t, 401, res.Code)
+
+	// NOTE sign out แล้ว access token ใช้ไม่ได้
+	res = call(t, app, "POST", "/api/signout", refreshed.AccessToken, nil)
+	assert.Equal(t, 200, res.Code)
+	res = call(t, app, "GET", "/api/user/"+bank.ID, refreshed.AccessToken, nil)
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+}
+
+func Test_RoleChangeRevokesTokens(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+	for _, email := range []string{"bank@test.com", "other@test.com"} {
+		res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: email, Password: "123456"})
+		require.Equal(t, 201, res.Code, res.Message)
+		_, err := repos.User.UpdateUser(context.Background(), data[models.SrvResUserModel](t, res).ID, models.RepoUpdateUserModel{Role: models.RoleAdmin})
+		require.NoError(t, err)
+	}
+	admin := signIn(t, app, "bank@test.com", "123456")
+	demoted := signIn(t, app, "other@test.com", "123456")
+	user, err := repos.User.GetUserByEmail(context.Background(), "other@test.com")
+	require.NoError(t, err)
+
+	res := call(t, app, "GET", "/api/users", demoted.AccessToken, nil)
+	assert.Equal(t, 200, res.Code)
+	res = call(t, app, "PUT", "/api/user/"+user.ID, admin.AccessToken, models.SrvUpdateUserModel{Role: models.RoleUser})
+	require.Equal(t, 200, res.Code, res.Message)
+
+	// NOTE token เดิมยังมี role admin ใน claim ต้องใช้ไม่ได้อีก (ทั้ง access และ refresh token)
+	res = call(t, app, "GET", "/api/users", demoted.AccessToken, nil)
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+	res = call(t, app, "POST", "/api/token/refresh", "", models.SrvRefreshTokenModel{RefreshToken: demoted.RefreshToken})
+	assert.Equal(t, 401, res.Code)
+
+	// NOTE sign in ใหม่ได้ token ที่มี role ใหม่
+	time.Sleep(2 * time.Millisecond)
+	token := signIn(t, app, "other@test.com", "123456")
+	res = call(t, app, "GET", "/api/users", token.AccessToken, nil)
+	assert.Equal(t, 403, res.Code)
+}
+
+func Test_PasswordFlow(t *testing.T) {
+	app, _, mail := newTestApp(t)
+
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bank := data[models.SrvResUserModel](t, res)
+	token := signIn(t, app, "bank@test.com", "123456")
+
+	// NOTE เปลี่ยนรหัสผ่านแล้ว session เดิมใช้ไม่ได้
+	res = call(t, app, "POST", "/api/user/"+bank.ID+"/password", token.AccessToken, models.SrvChangePasswordModel{CurrentPassword: "wrong", NewPassword: "654321"})
+	assert.Equal(t, 401, res.Code)
+	res = call(t, app, "POST", "/api/user/"+bank.ID+"/password", token.AccessToken, models.SrvChangePasswordModel{CurrentPassword: "123456", NewPassword: "654321"})
+	require.Equal(t, 200, res.Code, res.Message)
+
+	res = call(t, app, "GET", "/api/user/"+bank.ID, token.AccessToken, nil)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+	res = call(t, app, "POST", "/api/token/refresh", "", models.SrvRefreshTokenModel{RefreshToken: token.RefreshToken})
+	assert.Equal(t, 401, res.Code)
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "123456"})
+	assert.Equal(t, 401, res.Code)
+	token = signIn(t, app, "bank@test.com", "654321")
+
+	// NOTE ลืมรหัสผ่าน: email ที่ไม่มีในระบบตอบเหมือนกัน
+	sent := len(mail.messages)
+	res = call(t, app, "POST", "/api/forgot-password", "", models.SrvForgotPasswordModel{Email: "unknown@test.com"})
+	assert.Equal(t, 200, res.Code)
+	assert.Len(t, mail.messages, sent)
+
+	res = call(t, app, "POST", "/api/forgot-password", "", models.SrvForgotPasswordModel{Email: "Bank@Test.com"})
+	require.Equal(t, 200, res.Code, res.Message)
+	message := mail.last(t, notifier.TemplatePasswordReset)
+	assert.Equal(t, "bank@test.com", message.To)
+
+	res = call(t, app, "POST", "/api/reset-password", "", models.SrvResetPasswordModel{Token: message.Data["token"], Password: "new-password"})
+	require.Equal(t, 200, res.Code, res.Message)
+	res = call(t, app, "POST", "/api/reset-password", "", models.SrvResetPasswordModel{Token: message.Data["token"], Password: "again"})
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "RESET_TOKEN_INVALID", res.ErrorCode)
+
+	res = call(t, app, "GET", "/api/user/"+bank.ID, token.AccessToken, nil)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+	signIn(t, app, "bank@test.com", "new-password")
+}
+
+func Test_EmailVerificationFlow(t *testing.T) {
+	app, _, mail := newTestApp(t)
+	config.Env.RequireEmailVerified = true
+	defer func() { config.Env.RequireEmailVerified = false }()
+
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bank := data[models.SrvResUserModel](t, res)
+	assert.False(t, bank.EmailVerified)
+	assert.Equal(t, "bank@test.com", mail.last(t, notifier.TemplateWelcome).To)
+	message := mail.last(t, notifier.TemplateEmailVerification)
+
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "123456"})
+	assert.Equal(t, 403, res.Code)
+	assert.Equal(t, "EMAIL_NOT_VERIFIED", res.ErrorCode)
+
+	// NOTE ขอส่งใหม่แล้ว token เดิมใช้ไม่ได้
+	res = call(t, app, "POST", "/api/verify-email/resend", "", models.SrvResendVerificationModel{Email: "bank@test.com"})
+	require.Equal(t, 200, res.Code, res.Message)
+	res = call(t, app, "GET", "/api/verify-email?token="+message.Data["token"], "", nil)
+	assert.Equal(t, "VERIFY_TOKEN_INVALID", res.ErrorCode)
+
+	message = mail.last(t, notifier.TemplateEmailVerification)
+	res = call(t, app, "GET", "/api/verify-email?token="+message.Data["token"], "", nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	token := signIn(t, app, "bank@test.com", "123456")
+
+	res = call(t, app, "GET", "/api/user/"+bank.ID, token.AccessToken, nil)
+	assert.True(t, data[models.SrvResUserModel](t, res).EmailVerified)
+
+	// NOTE เปลี่ยน email ต้องยืนยันใหม่
+	res = call(t, app, "PUT", "/api/user/"+bank.ID, token.AccessToken, models.SrvUpdateUserModel{Email: "new@test.com"})
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.False(t, data[models.SrvResUserModel](t, res).EmailVerified)
+	message = mail.last(t, notifier.TemplateEmailVerification)
+	assert.Equal(t, "new@test.com", message.To)
+
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "new@test.com", Password: "123456"})
+	assert.Equal(t, "EMAIL_NOT_VERIFIED", res.ErrorCode)
+	res = call(t, app, "GET", "/api/verify-email?token="+message.Data["token"], "", nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	signIn(t, app, "new@test.com", "123456")
+}
+
+func Test_RequestErrors(t *testing.T) {
+	app, _, _ := newTestApp(t)
+
+	cases := []struct {
+		Name      string
+		Method    string
+		Path      string
+		Token     string
+		Body      string
+		Code      int
+		ErrorCode string
+	}{
+		{Name: "malformed json", Method: "POST", Path: "/api/create-user", Body: `{"name":`, Code: 400, ErrorCode: "INVALID_BODY"},
+		{Name: "wrong json type", Method: "POST", Path: "/api/signin", Body: `{"email":1}`, Code: 400, ErrorCode: "INVALID_BODY"},
+		{Name: "validation", Method: "POST", Path: "/api/create-user", Body: `{"name":"bank"}`, Code: 422, ErrorCode: "EMAIL_REQUIRED"},
+		{Name: "invalid token", Method: "GET", Path: "/api/user/1", Token: "not-a-token", Code: 401, ErrorCode: "TOKEN_INVALID"},
+		{Name: "unknown route", Method: "GET", Path: "/api/unknown", Code: 404, ErrorCode: "NOT_FOUND"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := send(t, app, c.Method, c.Path, c.Token, c.Body)
+			assert.Equal(t, c.Code, res.Code)
+			assert.Equal(t, c.ErrorCode, res.ErrorCode)
+			assert.False(t, res.Status)
+		})
+	}
+}
+
+func Test_RateLimitUnauthenticated(t *testing.T) {
+	rateLimitIP := config.Env.RateLimitIP
+	config.Env.RateLimitIP = "3/1h"
+	t.Cleanup(func() { config.Env.RateLimitIP = rateLimitIP })
+	app, _, _ := newTestApp(t)
+
+	// NOTE token ที่ไม่ผ่านถูกนับตาม IP ก่อนถึง accessToken
+	for i, path := range []string{"/api/user/1", "/api/users", "/api/user/2"} {
+		res := call(t, app, "GET", path, "guessed-token", nil)
+		assert.Equal(t, 401, res.Code, i)
+	}
+	res := call(t, app, "GET", "/api/user/1", "guessed-token", nil)
+	assert.Equal(t, 429, res.Code)
+	assert.Equal(t, "RATE_LIMITED", res.ErrorCode)
+}
+
+func Test_RateLimitTrustedProxy(t *testing.T) {
+	rateLimitIP, trustedProxies := config.Env.RateLimitIP, config.Env.TrustedProxies
+	t.Cleanup(func() { config.Env.RateLimitIP, config.Env.TrustedProxies = rateLimitIP, trustedProxies })
+	config.Env.RateLimitIP = "1/1h"
+
+	get := func(app *fiber.App, forwardedFor string) int {
+		req := httptest.NewRequest("GET", "/api/users", nil)
+		req.Header.Set("Authorization", "Bearer guessed-token")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	// NOTE ไม่ได้ตั้ง proxy -> header ถูกเมิน client เปลี่ยน IP ตัวเองไม่ได้
+	config.Env.TrustedProxies = ""
+	app, _, _ := newTestApp(t)
+	assert.Equal(t, 401, get(app, "203.0.113.1"))
+	assert.Equal(t, 429, get(app, "203.0.113.2"))
+
+	// NOTE request จาก proxy ที่เชื่อถือ (app.Test ต่อมาจาก 0.0.0.0) นับตาม IP ใน header
+	config.Env.TrustedProxies = "0.0.0.0"
+	app, _, _ = newTestApp(t)
+	assert.Equal(t, 401, get(app, "203.0.113.1"))
+	assert.Equal(t, 429, get(app, "203.0.113.1"))
+	assert.Equal(t, 401, get(app, "203.0.113.2"))
+}
+
+func Test_SignInLockout(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+
+	for _, email := range []string{"bank@test.com", "admin@test.com"} {
+		res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: email, Password: "123456"})
+		require.Equal(t, 201, res.Code, res.Message)
+	}
+	admin, err := repos.User.GetUserByEmail(context.Background(), "admin@test.com")
+	require.NoError(t, err)
+	_, err = repos.User.UpdateUser(context.Background(), admin.ID, models.RepoUpdateUserModel{Role: models.RoleAdmin})
+	require.NoError(t, err)
+	token := signIn(t, app, "admin@test.com", "123456")
+
+	// NOTE email ที่ไม่มีในระบบกับรหัสผ่านผิดตอบเหมือนกัน
+	unknown := call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "unknown@test.com", Password: "wrong"})
+	wrong := call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "wrong"})
+	assert.Equal(t, unknown, wrong)
+	assert.Equal(t, "INVALID_CREDENTIALS", wrong.ErrorCode)
+
+	for i := 1; i < config.Env.LoginMaxAttempts; i++ {
+		call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "wrong"})
+	}
+	res := call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "123456"})
+	assert.Equal(t, 429, res.Code)
+	assert.Equal(t, "TOO_MANY_ATTEMPTS", res.ErrorCode)
+
+	bank, err := repos.User.GetUserByEmail(context.Background(), "bank@test.com")
+	require.NoError(t, err)
+	res = call(t, app, "POST", "/api/user/"+bank.ID+"/unlock", token.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	signIn(t, app, "bank@test.com", "123456")
+}
+
+func Test_MFAFlow(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+
+	for _, email := range []string{"bank@test.com", "admin@test.com"} {
+		res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: email, Password: "123456"})
+		require.Equal(t, 201, res.Code, res.Message)
+	}
+	admin, err := repos.User.GetUserByEmail(context.Background(), "admin@test.com")
+	require.NoError(t, err)
+	_, err = repos.User.UpdateUser(context.Background(), admin.ID, models.RepoUpdateUserModel{Role: models.RoleAdmin})
+	require.NoError(t, err)
+	bank, err := repos.User.GetUserByEmail(context.Background(), "bank@test.com")
+	require.NoError(t, err)
+	token := signIn(t, app, "bank@test.com", "123456")
+
+	res := call(t, app, "POST", "/api/user/"+bank.ID+"/mfa/enroll", token.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	enroll := data[models.SrvMFAEnrollResModel](t, res)
+	step := totp.Step(time.Now())
+	code, err := totp.Code(enroll.Secret, step)
+	require.NoError(t, err)
+	res = call(t, app, "POST", "/api/user/"+bank.ID+"/mfa/confirm", token.AccessToken, models.SrvMFACodeModel{Code: code})
+	require.Equal(t, 200, res.Code, res.Message)
+	recovery := data[models.SrvMFARecoveryCodesResModel](t, res)
+
+	// NOTE sign in ด้วยรหัสผ่านอย่างเดียวได้แค่ mfa token ใช้เรียก API ไม่ได้
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 200, res.Code, res.Message)
+	challenge := data[models.SrvMFAChallengeResModel](t, res)
+	require.True(t, challenge.MFARequired)
+	res = call(t, app, "GET", "/api/user/"+bank.ID, challenge.MFAToken, nil)
+	assert.Equal(t, 401, res.Code)
+
+	next, err := totp.Code(enroll.Secret, step+1)
+	require.NoError(t, err)
+	res = call(t, app, "POST", "/api/signin/mfa", "", models.SrvMFASignInModel{MFAToken: challenge.MFAToken, Code: next})
+	require.Equal(t, 200, res.Code, res.Message)
+	token = data[models.SrvSignInResModel](t, res)
+	res = call(t, app, "GET", "/api/user/"+bank.ID, token.AccessToken, nil)
+	assert.Equal(t, 200, res.Code, res.Message)
+
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "123456"})
+	challenge = data[models.SrvMFAChallengeResModel](t, res)
+	res = call(t, app, "POST", "/api/signin/mfa", "", models.SrvMFASignInModel{MFAToken: challenge.MFAToken, Code: recovery.RecoveryCodes[0]})
+	require.Equal(t, 200, res.Code, res.Message)
+
+	adminToken := signIn(t, app, "admin@test.com", "123456")
+	res = call(t, app, "DELETE", "/api/user/"+bank.ID+"/mfa", token.AccessToken, nil)
+	assert.Equal(t, 403, res.Code)
+	res = call(t, app, "DELETE", "/api/user/"+bank.ID+"/mfa", adminToken.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	signIn(t, app, "bank@test.com", "123456")
+
+	_, body := scrape(t, app, "")
+	assert.Contains(t, body, `signin_total{method="password",reason="",result="mfa_required"} 2`)
+	assert.Contains(t, body, `signin_total{method="mfa",reason="",result="success"} 2`)
+}
+
+func Test_APIKeyFlow(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+
+	for _, email := range []string{"bank@test.com", "admin@test.com"} {
+		res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: email, Password: "123456"})
+		require.Equal(t, 201, res.Code, res.Message)
+	}
+	admin, err := repos.User.GetUserByEmail(context.Background(), "admin@test.com")
+	require.NoError(t, err)
+	_, err = repos.User.UpdateUser(context.Background(), admin.ID, models.RepoUpdateUserModel{Role: models.RoleAdmin})
+	require.NoError(t, err)
+	bank, err := repos.User.GetUserByEmail(context.Background(), "bank@test.com")
+	require.NoError(t, err)
+	token := signIn(t, app, "admin@test.com", "123456")
+
+	res := call(t, app, "POST", "/api/user/"+admin.ID+"/api-keys", token.AccessToken, models.SrvCreateAPIKeyModel{
+		Name:        "batch",
+		Permissions: []string{models.PermissionUserList},
+	})
+	require.Equal(t, 201, res.Code, res.Message)
+	key := data[models.SrvCreateAPIKeyResModel](t, res)
+
+	withAPIKey := func(apiKey string, method string, path string, body string) response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("apikey", apiKey)
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		result := response{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		return result
+	}
+	withKey := func(method string, path string, body string) response {
+		return withAPIKey(key.Key, method, path, body)
+	}
+
+	// NOTE ได้เฉพาะสิทธิ์ที่ระบุ ส่วนข้อมูลของเจ้าของ key ยังอ่านได้
+	assert.Equal(t, 200, withKey("GET", "/api/users", "").Code)
+	assert.Equal(t, 200, withKey("GET", "/api/user/"+admin.ID, "").Code)
+	assert.Equal(t, 403, withKey("GET", "/api/user/"+bank.ID, "").Code)
+	assert.Equal(t, 403, withKey("POST", "/api/user/"+admin.ID+"/api-keys", `{"name":"copy"}`).Code)
+
+	// NOTE key ที่ไม่มี scope อ่านข้อมูลเจ้าของได้อย่างเดียว แก้ไข ลบ หรือเพิกถอนไม่ได้
+	res = call(t, app, "POST", "/api/user/"+bank.ID+"/api-keys", signIn(t, app, "bank@test.com", "123456").AccessToken, models.SrvCreateAPIKeyModel{Name: "read-only"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bankKey := data[models.SrvCreateAPIKeyResModel](t, res)
+	assert.Equal(t, 200, withAPIKey(bankKey.Key, "GET", "/api/user/"+bank.ID, "").Code)
+	assert.Equal(t, 403, withAPIKey(bankKey.Key, "PUT", "/api/user/"+bank.ID, `{"name":"bank","email":"attacker@test.com"}`).Code)
+	assert.Equal(t, 403, withAPIKey(bankKey.Key, "DELETE", "/api/user/"+bank.ID, "").Code)
+	assert.Equal(t, 403, withAPIKey(bankKey.Key, "POST", "/api/user/"+bank.ID+"/revoke-tokens", "").Code)
+	assert.Equal(t, 403, withAPIKey(bankKey.Key, "GET", "/api/user/"+bank.ID+"/api-keys", "").Code)
+	assert.Equal(t, 403, withAPIKey(bankKey.Key, "DELETE", "/api/user/"+bank.ID+"/api-keys/"+bankKey.ID, "").Code)
+	unchanged, err := repos.User.GetUserByID(context.Background(), bank.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bank@test.com", unchanged.Email)
+
+	res = call(t, app, "GET", "/api/user/"+admin.ID+"/api-keys", token.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	keys := data[[]models.SrvResAPIKeyModel](t, res)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].LastUsedAt)
+	assert.NotContains(t, string(res.Data), "keyHash")
+
+	res = call(t, app, "DELETE", "/api/user/"+admin.ID+"/api-keys/"+key.ID, token.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	res = withKey("GET", "/api/users", "")
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "API_KEY_REVOKED", res.ErrorCode)
+}
+
+func Test_CookieSession(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bank, err := repos.User.GetUserByEmail(context.Background(), "bank@test.com")
+	require.NoError(t, err)
+
+	// NOTE จำ cookie แบบ browser
+	jar := map[string]*http.Cookie{}
+	browser := func(method string, path string, csrf string, body string) response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if csrf != "" {
+			req.Header.Set(config.CSRFHeader, csrf)
+		}
+		for _, cookie := range jar {
+			req.AddCookie(cookie)
+		}
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		for _, cookie := range res.Cookies() {
+			if !cookie.Expires.IsZero() && cookie.Expires.Before(time.Now()) {
+				delete(jar, cookie.Name)
+			} else {
+				jar[cookie.Name] = cookie
+			}
+		}
+		result := response{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		assert.Equal(t, res.StatusCode, result.Code)
+		return result
+	}
+
+	res = browser("POST", "/api/signin?session=cookie", "", `{"email":"bank@test.com","password":"123456"}`)
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.NotContains(t, string(res.Data), "accessToken")
+	session := data[models.SrvSessionResModel](t, res)
+	assert.Equal(t, "Cookie", session.Type)
+	for _, name := range []string{config.AccessTokenCookie, config.RefreshTokenCookie, config.CSRFCookie} {
+		require.Contains(t, jar, name)
+		assert.True(t, jar[name].Secure, name)
+		assert.Equal(t, http.SameSiteLaxMode, jar[name].SameSite, name)
+		assert.Equal(t, name != config.CSRFCookie, jar[name].HttpOnly, name)
+	}
+	assert.Equal(t, session.CSRFToken, jar[config.CSRFCookie].Value)
+
+	assert.Equal(t, 200, browser("GET", "/api/user/"+bank.ID, "", "").Code)
+	res = browser("PUT", "/api/user/"+bank.ID, "", `{"name":"csrf"}`)
+	assert.Equal(t, 403, res.Code)
+	assert.Equal(t, "CSRF_TOKEN_INVALID", res.ErrorCode)
+	assert.Equal(t, 200, browser("PUT", "/api/user/"+bank.ID, session.CSRFToken, `{"name":"bank2"}`).Code)
+
+	// NOTE refresh ด้วย cookie ได้ token ใหม่ใน cookie csrf token เดิมยังใช้ได้
+	access := jar[config.AccessTokenCookie].Value
+	assert.Equal(t, 403, browser("POST", "/api/token/refresh", "", "").Code)
+	res = browser("POST", "/api/token/refresh", session.CSRFToken, "")
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.NotEqual(t, access, jar[config.AccessTokenCookie].Value)
+	assert.Equal(t, session.CSRFToken, data[models.SrvSessionResModel](t, res).CSRFToken)
+
+	access = jar[config.AccessTokenCookie].Value
+	res = browser("POST", "/api/signout", session.CSRFToken, "")
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.Empty(t, jar)
+	res = call(t, app, "GET", "/api/user/"+bank.ID, access, nil)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+}
+
+func Test_OIDCFlow(t *testing.T) {
+	idp := oidctest.NewServer("backend", "secret")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "sub-1", Email: "bank@test.com", EmailVerified: true, Name: "Bank"})
+
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     "backend",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/api/oidc/stub/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+	app, repos, _ := newTestAppWithOIDC(t, oidc.Providers{"stub": provider})
+
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bank, err := repos.User.GetUserByEmail(context.Background(), "bank@test.com")
+	require.NoError(t, err)
+	assert.False(t, bank.EmailVerified)
+
+	// NOTE จำ cookie แบบ browser
+	jar := map[string]*http.Cookie{}
+	browser := func(path string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		for _, cookie := range jar {
+			req.AddCookie(cookie)
+		}
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		for _, cookie := range res.Cookies() {
+			if !cookie.Expires.IsZero() && cookie.Expires.Before(time.Now()) {
+				delete(jar, cookie.Name)
+			} else {
+				jar[cookie.Name] = cookie
+			}
+		}
+		return res
+	}
+	decode := func(res *http.Response) (result response) {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		assert.Equal(t, res.StatusCode, result.Code)
+		return result
+	}
+	// NOTE provider อนุมัติทันทีแล้ว redirect กลับมาที่ callback
+	login := func() string {
+		res := browser("/api/oidc/stub/login?session=cookie")
+		require.Equal(t, http.StatusFound, res.StatusCode)
+		require.Contains(t, jar, config.OIDCStateCookie)
+		assert.True(t, jar[config.OIDCStateCookie].HttpOnly)
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		authorize, err := client.Get(res.Header.Get("Location"))
+		require.NoError(t, err)
+		authorize.Body.Close()
+		require.Equal(t, http.StatusFound, authorize.StatusCode)
+		callback, err := url.Parse(authorize.Header.Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "/api/oidc/stub/callback", callback.Path)
+		return callback.RequestURI()
+	}
+
+	// NOTE user เดิมยังไม่ยืนยัน email ผูกไม่ได้ (อาจเป็นบัญชีที่คนอื่นสมัครดักไว้)
+	res = decode(browser(login()))
+	assert.Equal(t, 403, res.Code)
+	assert.Equal(t, "OIDC_ACCOUNT_NOT_VERIFIED", res.ErrorCode)
+	verified := true
+	_, err = repos.User.UpdateUser(context.Background(), bank.ID, models.RepoUpdateUserModel{EmailVerified: &verified})
+	require.NoError(t, err)
+
+	callback := login()
+	res = decode(browser(callback))
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.Equal(t, "Cookie", data[models.SrvSessionResModel](t, res).Type)
+	assert.NotContains(t, jar, config.OIDCStateCookie)
+	require.Contains(t, jar, config.AccessTokenCookie)
+
+	// NOTE ผูกกับ user เดิมตาม email
+	res = decode(browser("/api/user/" + bank.ID))
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.True(t, data[models.SrvResUserModel](t, res).EmailVerified)
+
+	// NOTE callback ซ้ำใช้ไม่ได้
+	res = decode(browser(callback))
+	assert.Equal(t, "OIDC_STATE_INVALID", res.ErrorCode)
+
+	// NOTE browser อื่น (ไม่มี cookie state) ใช้ callback ของคนอื่นไม่ได้
+	callback = login()
+	delete(jar, config.OIDCStateCookie)
+	res = decode(browser(callback))
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "OIDC_STATE_INVALID", res.ErrorCode)
+
+	// NOTE sign in แบบ JSON token (ไม่ใช่ cookie) และ user ใหม่ถูกสร้างให้
+	idp.SetUser(oidctest.User{Subject: "sub-2", Email: "new@test.com", EmailVerified: true, Name: "New"})
+	res = call(t, app, "GET", "/api/oidc/unknown/login", "", nil)
+	assert.Equal(t, 404, res.Code)
+	callback = login()
+	delete(jar, config.OIDCSessionCookie)
+	token := data[models.SrvSignInResModel](t, decode(browser(callback)))
+	require.NotEmpty(t, token.AccessToken)
+	user, err := repos.User.GetUserByEmail(context.Background(), "new@test.com")
+	require.NoError(t, err)
+	assert.Equal(t, "New", user.Name)
+	res = call(t, app, "GET", "/api/user/"+user.ID, token.AccessToken, nil)
+	assert.Equal(t, 200, res.Code, res.Message)
+}
+
+func Test_OAuthFlow(t *testing.T) {
+	app, repos, _ := newTestApp(t)
+
+	for _, email := range []string{"bank@test.com", "admin@test.com"} {
+		res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: email, Password: "123456"})
+		require.Equal(t, 201, res.Code, res.Message)
+	}
+	admin, err := repos.User.GetUserByEmail(context.Background(), "admin@test.com")
+	require.NoError(t, err)
+	_, err = repos.User.UpdateUser(context.Background(), admin.ID, models.RepoUpdateUserModel{Role: models.RoleAdmin})
+	require.NoError(t, err)
+	bank, err := repos.User.GetUserByEmail(context.Background(), "bank@test.com")
+	require.NoError(t, err)
+	adminToken := signIn(t, app, "admin@test.com", "123456")
+	bankToken := signIn(t, app, "bank@test.com", "123456")
+
+	res := call(t, app, "POST", "/api/oauth/clients", bankToken.AccessToken, models.SrvCreateOAuthClientModel{Name: "reports"})
+	assert.Equal(t, 403, res.Code)
+	res = call(t, app, "POST", "/api/oauth/clients", adminToken.AccessToken, models.SrvCreateOAuthClientModel{
+		Name:         "reports",
+		RedirectURIs: []string{"https://reports.test/callback"},
+		Scopes:       []string{models.PermissionUserRead, models.PermissionUserList},
+		GrantTypes:   []string{models.GrantAuthorizationCode, models.GrantClientCredentials},
+		Confidential: true,
+	})
+	require.Equal(t, 201, res.Code, res.Message)
+	client := data[models.SrvCreateOAuthClientResModel](t, res)
+
+	res = call(t, app, "GET", "/api/oauth/clients", adminToken.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	assert.NotContains(t, string(res.Data), "secret")
+
+	// NOTE endpoint ของ client รับ form และตอบตามรูปแบบของ RFC
+	oauthPost := func(path string, form url.Values, basic bool) (int, map[string]interface{}) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if basic {
+			req.SetBasicAuth(url.QueryEscape(client.ID), url.QueryEscape(client.Secret))
+		}
+		res, err := app.Test(req, -1)
+		require.NoError(t, err)
+		if res.StatusCode == 200 {
+			assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+		}
+		body := map[string]interface{}{}
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		if len(raw) != 0 {
+			require.NoError(t, json.Unmarshal(raw, &body), string(raw))
+		}
+		return res.StatusCode, body
+	}
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ID},
+		"redirect_uri":          {"https://reports.test/callback"},
+		"scope":                 {"user:read"},
+		"state":                 {"xyz"},
+		"code_challenge":        {oidc.CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	res = call(t, app, "GET", "/api/oauth/authorize?"+query.Encode(), bankToken.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	consent := data[models.SrvOAuthConsentResModel](t, res)
+	assert.Equal(t, "reports", consent.ClientName)
+	assert.Equal(t, []string{"user:read"}, consent.Scopes)
+
+	res = call(t, app, "POST", "/api/oauth/authorize", bankToken.AccessToken, map[string]interface{}{
+		"response_type": "code", "client_id": client.ID, "redirect_uri": "https://reports.test/callback", "scope": "user:read",
+		"state": "xyz", "code_challenge": oidc.CodeChallenge(verifier), "code_challenge_method": "S256", "approve": true,
+	})
+	require.Equal(t, 200, res.Code, res.Message)
+	redirect, err := url.Parse(data[models.SrvOAuthRedirectResModel](t, res).RedirectURI)
+	require.NoError(t, err)
+	assert.Equal(t, "reports.test", redirect.Host)
+	assert.Equal(t, "xyz", redirect.Query().Get("state"))
+
+	status, body := oauthPost("/api/oauth/token", url.Values{
+		"grant_type": {"authorization_code"}, "code": {redirect.Query().Get("code")},
+		"redirect_uri": {"https://reports.test/callback"}, "code_verifier": {verifier},
+	}, true)
+	require.Equal(t, 200, status, body)
+	assert.Equal(t, "user:read", body["scope"])
+	userToken := body["access_token"].(string)
+
+	// NOTE token ของ client ใช้ได้เฉพาะ scope ที่ user อนุญาต
+	assert.Equal(t, 200, call(t, app, "GET", "/api/user/"+bank.ID, userToken, nil).Code)
+	assert.Equal(t, 403, call(t, app, "POST", "/api/user/"+bank.ID+"/password", userToken, models.SrvChangePasswordModel{CurrentPassword: "123456", NewPassword: "1234567"}).Code)
+	assert.Equal(t, 403, call(t, app, "GET", "/api/oauth/authorize?"+query.Encode(), userToken, nil).Code)
+
+	status, body = oauthPost("/api/oauth/token", url.Values{
+		"grant_type": {"authorization_code"}, "code": {redirect.Query().Get("code")},
+		"redirect_uri": {"https://reports.test/callback"}, "code_verifier": {verifier},
+	}, true)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	status, body = oauthPost("/api/oauth/token", url.Values{"grant_type": {"client_credentials"}, "client_id": {client.ID}, "client_secret": {"wrong"}}, false)
+	assert.Equal(t, 401, status)
+	assert.Equal(t, "invalid_client", body["error"])
+
+	status, body = oauthPost("/api/oauth/token", url.Values{"grant_type": {"client_credentials"}, "scope": {"user:list"}}, true)
+	require.Equal(t, 200, status, body)
+	serviceToken := body["access_token"].(string)
+	assert.Equal(t, 200, call(t, app, "GET", "/api/users", serviceToken, nil).Code)
+	assert.Equal(t, 403, call(t, app, "GET", "/api/user/"+bank.ID, serviceToken, nil).Code)
+
+	status, body = oauthPost("/api/oauth/introspect", url.Values{"token": {userToken}}, true)
+	require.Equal(t, 200, status, body)
+	assert.Equal(t, true, body["active"])
+	assert.Equal(t, bank.ID, body["sub"])
+	assert.Equal(t, client.ID, body["client_id"])
+
+	status, _ = oauthPost("/api/oauth/revoke", url.Values{"token": {userToken}, "token_type_hint": {"access_token"}}, true)
+	assert.Equal(t, 200, status)
+	status, body = oauthPost("/api/oauth/introspect", url.Values{"token": {userToken}}, true)
+	require.Equal(t, 200, status, body)
+	assert.Equal(t, map[string]interface{}{"active": false}, body)
+	res = call(t, app, "GET", "/api/user/"+bank.ID, userToken, nil)
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+
+	res = call(t, app, "DELETE", "/api/oauth/clients/"+client.ID, adminToken.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+	// NOTE ลบ client แล้ว token ที่ออกให้ client ใช้ไม่ได้อีก
+	res = call(t, app, "GET", "/api/users", serviceToken, nil)
+	assert.Equal(t, 401, res.Code)
+	assert.Equal(t, "TOKEN_REVOKED", res.ErrorCode)
+	status, body = oauthPost("/api/oauth/token", url.Values{"grant_type": {"client_credentials"}}, true)
+	assert.Equal(t, 401, status)
+	assert.Equal(t, "invalid_client", body["error"])
+}
+
+// อ่านผลของ GET /metrics
+func scrape(t *testing.T, app *fiber.App, token string) (status int, body string) {
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(raw)
+}
+
+func Test_Metrics(t *testing.T) {
+	app, _, _ := newTestServer(t, oidc.Providers{}, metrics.New())
+
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+	bank := data[models.SrvResUserModel](t, res)
+	tokens := signIn(t, app, "bank@test.com", "123456")
+	res = call(t, app, "POST", "/api/signin", "", models.SrvSignInModel{Email: "bank@test.com", Password: "wrong"})
+	require.Equal(t, 401, res.Code, res.Message)
+	res = call(t, app, "GET", "/api/user/"+bank.ID, tokens.AccessToken, nil)
+	require.Equal(t, 200, res.Code, res.Message)
+
+	status, body := scrape(t, app, "")
+	require.Equal(t, 200, status)
+	for _, line := range []string{
+		`http_requests_total{method="POST",route="/api/signin",status="200"} 1`,
+		`http_requests_total{method="POST",route="/api/signin",status="401"} 1`,
+		// NOTE label เป็น route ไม่ใช่ path จริง
+		`http_requests_total{method="GET",route="/api/user/:id",status="200"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/api/signin"} 2`,
+		`signin_total{method="password",reason="",result="success"} 1`,
+		`signin_total{method="password",reason="INVALID_CREDENTIALS",result="failure"} 1`,
+		`users_total 0`,
+	} {
+		assert.Contains(t, body, line)
+	}
+}
+
+func Test_MetricsToken(t *testing.T) {
+	config.Env.MetricsToken = "scrape-secret"
+	t.Cleanup(func() { config.Env.MetricsToken = "" })
+	app, _, _ := newTestApp(t)
+
+	status, _ := scrape(t, app, "")
+	assert.Equal(t, 401, status)
+	status, _ = scrape(t, app, "wrong")
+	assert.Equal(t, 401, status)
+	status, body := scrape(t, app, "scrape-secret")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, "# TYPE http_requests_total counter")
+}
+
+func Test_Tracing(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	app, _, _ := newTestApp(t)
+
+	res := call(t, app, "POST", "/api/create-user", "", models.SrvCreateUserModel{Name: "bank", Email: "bank@test.com", Password: "123456"})
+	require.Equal(t, 201, res.Code, res.Message)
+
+	req := httptest.NewRequest("POST", "/api/signin", strings.NewReader(`{"email":"bank@test.com","password":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	httpRes, err := app.Test(req, -1)
+	require.NoError(t, err)
+	require.Equal(t, 200, httpRes.StatusCode)
+
+	// NOTE span ของ sign in ทั้งหมดอยู่ใน trace ที่ส่งมา แยกเวลา bcrypt / JWT ได้
+	parents := map[string]string{}
+	names := map[string]string{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() != traceID {
+			continue
+		}
+		names[span.SpanContext().SpanID().String()] = span.Name()
+		parents[span.Name()] = span.Parent().SpanID().String()
+	}
+	assert.Equal(t, "POST /api/signin", names[parents["userSrv.SignIn"]])
+	assert.Equal(t, "userSrv.SignIn", names[parents["bcrypt.Compare"]])
+	assert.Equal(t, "userSrv.SignIn", names[parents["jwt.Sign"]])
+	assert.Equal(t, "00f067aa0ba902b7", parents["POST /api/signin"])
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// API key มีรูปแบบ sk_<prefix>_<secret> prefix ใช้ค้นหา key ในฐานข้อมูล
+const apiKeyScheme = "sk"
+
+// สุ่ม API key ใหม่ คืน key เต็ม (แสดงให้ผู้ใช้ครั้งเดียว) และ prefix
+func APIKey_Generate() (key string, prefix string, err error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(buf)
+	secret, err := Token_Random(32)
+	if err != nil {
+		return "", "", err
+	}
+	return apiKeyScheme + "_" + prefix + "_" + secret, prefix, nil
+}
+
+// prefix ของ key ที่ส่งมา ok = false ถ้ารูปแบบไม่ถูกต้อง
+func APIKey_Prefix(key string) (prefix string, ok bool) {
+	// NOTE secret เป็น base64url อาจมี _ จึงแยกแค่ 3 ส่วน
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
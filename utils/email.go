@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// รูปแบบเดียวของ email ที่ใช้เก็บและค้นหา (ตัดช่องว่าง + ตัวพิมพ์เล็ก)
+func Email_Normalize(email string) (result string) {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// email (ที่ normalize แล้ว) ต้องเป็น address เปล่าๆ
+// NOTE mail.ParseAddress รับ "name <addr>" ด้วย จึงต้องเทียบว่า address ที่ได้คือค่าที่ส่งมาทั้งหมด
+func Email_Valid(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Name == "" && addr.Address == email
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// สุ่ม token แบบ opaque (base64url) ขนาด size bytes
+func Token_Random(size int) (result string, err error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hash ของ token สำหรับเก็บลงฐานข้อมูล
+func Token_Hash(token string) (result string) {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}